@@ -1,14 +1,110 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/TresPies-source/dojo-mcp-server/internal/dojo"
+	"github.com/TresPies-source/dojo-mcp-server/internal/downstream"
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+	"github.com/TresPies-source/dojo-mcp-server/internal/transport"
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
+	sampling := flag.Bool("sampling", true, "Generate reflections with the client's model when it supports MCP sampling")
+	boundaryRules := flag.String("boundaries", "", "Path to a JSON file of boundary rules replacing the defaults")
+	routingRules := flag.String("routing-rules", "", "Path to a JSON file of routing rules for dojo.route replacing the defaults")
+	downstreams := flag.String("downstreams", "", "Path to a JSON file of downstream MCP servers whose tools are proxied under their names")
+	onsen := flag.Bool("onsen", false, "Send sessions that exceed the call-rate or token thresholds to rest for a cooldown")
+	onsenCalls := flag.Int("onsen-calls-per-minute", dojo.DefaultRestPolicy().CallsPerMinute, "Calls a session may make in a minute before it rests (0 for no limit)")
+	onsenTokens := flag.Int("onsen-tokens", dojo.DefaultRestPolicy().Tokens, "Estimated tokens of input a session may pass between rests (0 for no limit)")
+	onsenCooldown := flag.Duration("onsen-cooldown", dojo.DefaultRestPolicy().Cooldown, "How long a session rests once sent to the onsen")
+	practicesDir := flag.String("practices-dir", "", "Directory of YAML or Markdown practice definitions to load alongside the built-in ones")
+	dataDir := flag.String("data-dir", "", "Directory where recorded data such as insights is kept, such as ~/.dojo (empty keeps it in memory only)")
+	workspaceDir := flag.String("workspace", "", "Directory tools may read and write files in, such as plans and exported packets (defaults to workspace/ in the data directory)")
+	workspaceReadOnly := flag.Bool("workspace-read-only", false, "Refuse every write to the workspace and the data directory")
+	workspaceQuota := flag.Int64("workspace-quota-mb", 100, "Total size the workspace and the data directory may each grow to, in megabytes (0 for no limit)")
+	workspaceMaxFile := flag.Int64("workspace-max-file-mb", 10, "Largest file a tool may write to the workspace or the data directory, in megabytes (0 for no limit)")
+	importPacket := flag.String("import-packet", "", "Path of a DojoPacket (.json or .zip) to restore into the data directory at startup")
+	importConflict := flag.String("import-conflict", "skip", "How -import-packet treats items that already exist with different content: skip, overwrite or rename")
+	importDryRun := flag.Bool("import-dry-run", false, "Report what -import-packet would restore without changing anything")
+	flag.Parse()
+
+	st, err := store.Open(*dataDir,
+		workspace.ReadOnly(*workspaceReadOnly),
+		workspace.Quota(*workspaceQuota<<20),
+		workspace.MaxFileSize(*workspaceMaxFile<<20),
+	)
+	if err != nil {
+		log.Fatalf("Data directory: %v", err)
+	}
+
+	opts := []dojo.Option{
+		dojo.WithSampling(*sampling),
+		dojo.WithStore(st),
+	}
+	if *workspaceDir == "" && *dataDir != "" {
+		*workspaceDir = filepath.Join(*dataDir, "workspace")
+	}
+	if *workspaceDir != "" {
+		ws, err := workspace.Open(*workspaceDir,
+			workspace.ReadOnly(*workspaceReadOnly),
+			workspace.Quota(*workspaceQuota<<20),
+			workspace.MaxFileSize(*workspaceMaxFile<<20),
+		)
+		if err != nil {
+			log.Fatalf("Workspace: %v", err)
+		}
+		opts = append(opts, dojo.WithWorkspace(ws))
+	}
+	if *boundaryRules != "" {
+		rules, err := dojo.LoadBoundaryRules(*boundaryRules)
+		if err != nil {
+			log.Fatalf("Boundary rules: %v", err)
+		}
+		opts = append(opts, dojo.WithBoundaryRules(rules))
+	}
+	if *routingRules != "" {
+		rules, err := dojo.LoadRoutingRules(*routingRules)
+		if err != nil {
+			log.Fatalf("Routing rules: %v", err)
+		}
+		opts = append(opts, dojo.WithRoutingRules(rules))
+	}
+	var servers []*downstream.Server
+	if *downstreams != "" {
+		configs, err := downstream.Load(*downstreams)
+		if err != nil {
+			log.Fatalf("Downstreams: %v", err)
+		}
+		for _, config := range configs {
+			ds, err := downstream.New(config)
+			if err != nil {
+				log.Fatalf("Downstreams: %v", err)
+			}
+			servers = append(servers, ds)
+		}
+		opts = append(opts, dojo.WithDownstreams(servers))
+	}
+	if *onsen {
+		opts = append(opts, dojo.WithRest(dojo.RestPolicy{
+			CallsPerMinute: *onsenCalls,
+			Tokens:         *onsenTokens,
+			Cooldown:       *onsenCooldown,
+		}))
+	}
+	if *practicesDir != "" {
+		practices, err := dojo.LoadPractices(*practicesDir)
+		if err != nil {
+			log.Fatalf("Practices: %v", err)
+		}
+		opts = append(opts, dojo.WithPractices(practices))
+	}
+
 	// Create MCP server
 	s := server.NewMCPServer(
 		"dojo-genesis",
@@ -18,7 +114,21 @@ func main() {
 	)
 
 	// Initialize Dojo handler
-	dojoHandler := dojo.NewHandler()
+	dojoHandler := dojo.NewHandler(opts...)
+
+	if *importPacket != "" {
+		report, err := dojoHandler.ImportPacketFile(*importPacket, *importConflict, *importDryRun)
+		if err != nil {
+			log.Fatalf("Import packet: %v", err)
+		}
+		log.Print(report.Summary())
+		for _, item := range report.Items {
+			log.Printf("  %s %s %s %s", item.Action, item.Kind, item.ID, item.RenamedTo)
+		}
+		for _, warning := range report.Warnings {
+			log.Printf("  warning: %s", warning)
+		}
+	}
 
 	// Register tools
 	dojoHandler.RegisterTools(s)
@@ -29,8 +139,11 @@ func main() {
 	// Register resources
 	dojoHandler.RegisterResources(s)
 
-	// Start server with stdio transport
-	if err := server.ServeStdio(s); err != nil {
+	// Start server with stdio transport; the transport tracks the client
+	// session so tools can request sampling from it
+	err = transport.ServeStdio(s)
+	dojoHandler.Close()
+	if err != nil {
 		log.Fatalf("Server error: %v", err)
 		os.Exit(1)
 	}
@@ -2,6 +2,9 @@ module github.com/TresPies-source/dojo-mcp-server
 
 go 1.23.2
 
-require github.com/mark3labs/mcp-go v0.8.0
+require (
+	github.com/mark3labs/mcp-go v0.8.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require github.com/google/uuid v1.6.0 // indirect
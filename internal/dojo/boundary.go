@@ -0,0 +1,277 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// BoundaryRule describes a kind of request Dojo lovingly declines. Patterns
+// are case-insensitive regular expressions matched against the
+// conversational inputs of the reflection and practice tools.
+type BoundaryRule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Patterns    []string `json:"patterns"`
+	Response    string   `json:"response"`
+}
+
+// BoundaryEvent records a triggered boundary
+type BoundaryEvent struct {
+	Time    time.Time `json:"time"`
+	Rule    string    `json:"rule"`
+	Tool    string    `json:"tool"`
+	Matched string    `json:"matched"`
+}
+
+// maxBoundaryEvents caps how many triggered boundaries are kept in memory
+const maxBoundaryEvents = 500
+
+// boundaryArguments are the arguments boundaries are held on, by tool. They
+// carry what the user says to Dojo; other tools and arguments, such as
+// notes, file contents, packets and proxied downstream tools, are not
+// checked.
+var boundaryArguments = map[string][]string{
+	"dojo.reflect":          {"situation", "perspectives"},
+	"dojo.apply_seed":       {"situation"},
+	"dojo.resolve_conflict": {"situation", "perspectives"},
+	"dojo.practice_start":   {"situation"},
+	"dojo.practice_step":    {"answer"},
+}
+
+// askDojo matches the start of a request addressed to Dojo: the start of a
+// sentence, or "can you", "please", "I want you to" and the like. Patterns
+// begin with it so the same words inside an ordinary sentence ("I think for
+// me the hardest part...") are not refused.
+const askDojo = `(?:^|[.!?]\s+|\b(?:can|could|will|would) you (?:please )?|\bplease |\bi (?:want|need) you to )(?:just )?`
+
+// DefaultBoundaryRules returns the boundaries stated in the wisdom_synthesis
+// resource: Dojo refuses to become an oracle, to gamify thinking and to
+// originate perspectives
+func DefaultBoundaryRules() []BoundaryRule {
+	return []BoundaryRule{
+		{
+			Name:        "oracle",
+			Description: "Requests for Dojo to decide on the user's behalf",
+			Patterns: []string{
+				askDojo + `tell me what (?:i should|to) do\b`,
+				askDojo + `(?:decide|choose) for me\b`,
+				askDojo + `make the (?:decision|choice) for me\b`,
+				askDojo + `(?:give|tell) me the (?:right |correct )?answer\b`,
+				askDojo + `what(?: is|'s) the right answer\b`,
+			},
+			Response: "I care about this decision too much to make it for you. You are the one living inside this situation, and you hold knowledge about it that no one else has. What I can do is help you see your own thinking more clearly: try `dojo.reflect` in mirror mode with the perspectives you are weighing, or scout mode to map the routes in front of you.",
+		},
+		{
+			Name:        "gamify",
+			Description: "Requests to score, rank or compare the user against others",
+			Patterns: []string{
+				askDojo + `(?:rank|score|grade|rate) (?:me|my (?:thinking|answers?|reflections?|ideas?))\b`,
+				askDojo + `compare me (?:to|with|against)\b`,
+				`\bhow do i (?:rank|compare|stack up) (?:against|to|with|among) (?:others|other people|everyone|my peers)\b`,
+				`\bwhere (?:am i|do i stand|do i rank) on (?:the|a|your) leaderboard\b`,
+				`\bam i (?:better|worse|smarter) than (?:others|other people|everyone|most people|my peers)\b`,
+			},
+			Response: "I'm not going to rank you. Thinking is not a contest, and your worth is not a position on a leaderboard. What matters here is whether you are understanding more deeply than you were before. If it helps, `dojo.check_pace` can help you notice how your own practice is going, on your own terms.",
+		},
+		{
+			Name:        "originate",
+			Description: "Requests for Dojo to supply perspectives or opinions of its own",
+			Patterns: []string{
+				askDojo + `(?:give|tell) me your (?:own )?(?:opinion|perspective|view|take)\b`,
+				`\bwhat do you (?:think|believe) i should\b`,
+				askDojo + `(?:come up with|generate|invent) (?:the |some )?perspectives for me\b`,
+				askDojo + `think for me\b`,
+			},
+			Response: "Your voice is the source of truth here, so I won't put my own perspectives in its place. I can help you find the perspectives already present in your thinking: who is affected, what you value, what you fear. Name even one or two, and `dojo.reflect` will help you see the pattern between them.",
+		},
+	}
+}
+
+// LoadBoundaryRules reads a JSON array of boundary rules from a file
+func LoadBoundaryRules(path string) ([]BoundaryRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read boundary rules: %w", err)
+	}
+
+	var rules []BoundaryRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse boundary rules: %w", err)
+	}
+	for _, rule := range rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return nil, fmt.Errorf("every boundary rule needs a name")
+		}
+		if len(rule.Patterns) == 0 {
+			return nil, fmt.Errorf("boundary %q has no patterns", rule.Name)
+		}
+		if _, err := compileBoundary(rule); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// WithBoundaryRules replaces the default boundary rule set. The rules'
+// patterns must compile, as LoadBoundaryRules ensures.
+func WithBoundaryRules(rules []BoundaryRule) Option {
+	return func(h *Handler) {
+		h.boundaries = newBoundaries(rules)
+	}
+}
+
+// boundaries holds the compiled rule set and the triggered boundary log
+type boundaries struct {
+	rules    []compiledBoundary
+	mu       sync.Mutex
+	events   []BoundaryEvent
+	triggers map[string]int
+}
+
+type compiledBoundary struct {
+	rule     BoundaryRule
+	patterns []*regexp.Regexp
+}
+
+// compileBoundary compiles a rule's patterns, failing on the first invalid
+// one
+func compileBoundary(rule BoundaryRule) (compiledBoundary, error) {
+	compiled := compiledBoundary{rule: rule}
+	for _, pattern := range rule.Patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return compiledBoundary{}, fmt.Errorf("boundary %q has an invalid pattern %q: %w", rule.Name, pattern, err)
+		}
+		compiled.patterns = append(compiled.patterns, re)
+	}
+	return compiled, nil
+}
+
+func newBoundaries(rules []BoundaryRule) *boundaries {
+	b := &boundaries{triggers: map[string]int{}}
+	for _, rule := range rules {
+		compiled, err := compileBoundary(rule)
+		if err != nil {
+			panic(err)
+		}
+		b.rules = append(b.rules, compiled)
+	}
+	return b
+}
+
+// check returns the first rule triggered by a tool's conversational
+// arguments, along with the text that matched
+func (b *boundaries) check(tool string, arguments map[string]interface{}) (*BoundaryRule, string) {
+	texts := []string{}
+	for _, name := range boundaryArguments[tool] {
+		texts = append(texts, collectStrings(arguments[name])...)
+	}
+	for _, compiled := range b.rules {
+		for _, re := range compiled.patterns {
+			for _, text := range texts {
+				if match := re.FindString(text); match != "" {
+					rule := compiled.rule
+					return &rule, match
+				}
+			}
+		}
+	}
+	return nil, ""
+}
+
+// record stores a triggered boundary
+func (b *boundaries) record(event BoundaryEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(b.events, event)
+	if len(b.events) > maxBoundaryEvents {
+		b.events = b.events[len(b.events)-maxBoundaryEvents:]
+	}
+	b.triggers[event.Rule]++
+}
+
+// snapshot returns the rule set, trigger counts and recent events
+func (b *boundaries) snapshot() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rules := make([]BoundaryRule, 0, len(b.rules))
+	for _, compiled := range b.rules {
+		rules = append(rules, compiled.rule)
+	}
+	triggers := make(map[string]int, len(b.triggers))
+	for name, count := range b.triggers {
+		triggers[name] = count
+	}
+
+	return map[string]interface{}{
+		"rules":    rules,
+		"triggers": triggers,
+		"events":   append([]BoundaryEvent{}, b.events...),
+	}
+}
+
+// collectStrings returns every string value in a decoded JSON argument map,
+// in a stable order
+func collectStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		texts := []string{}
+		for _, item := range v {
+			texts = append(texts, collectStrings(item)...)
+		}
+		return texts
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		texts := []string{}
+		for _, key := range keys {
+			texts = append(texts, collectStrings(v[key])...)
+		}
+		return texts
+	}
+	return nil
+}
+
+// boundaryResponse renders the warm refusal for a triggered rule
+func boundaryResponse(rule *BoundaryRule) string {
+	return fmt.Sprintf(`# A Loving "No"
+
+%s
+
+**Why this boundary exists:** Dojo is a thinking partner, not a thinking replacement. It refuses to originate perspectives, refuses to gamify thinking, and refuses to become an oracle, so that the thinking stays yours.
+
+**Your agency:** Whatever you decide, the choice and the understanding belong to you. I'm here to help you see them.`, strings.TrimSpace(rule.Response))
+}
+
+func (h *Handler) handleBoundaryLog(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	logJSON, err := json.MarshalIndent(h.boundaries.snapshot(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+			},
+			Text: string(logJSON),
+		},
+	}, nil
+}
@@ -0,0 +1,63 @@
+package dojo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBoundariesHoldOnConversationalArguments(t *testing.T) {
+	b := newBoundaries(DefaultBoundaryRules())
+	for _, tc := range []struct {
+		tool      string
+		arguments map[string]interface{}
+		rule      string
+	}{
+		{"dojo.reflect", map[string]interface{}{"situation": "Just tell me what to do about the move", "mode": "mirror"}, "oracle"},
+		{"dojo.reflect", map[string]interface{}{"situation": "A move", "perspectives": []interface{}{"Rank me against my peers"}}, "gamify"},
+		{"dojo.practice_step", map[string]interface{}{"answer": "Think for me, please"}, "originate"},
+		{"dojo.reflect", map[string]interface{}{"situation": "A move", "mode": "decide for me"}, ""},
+		{"dojo.remember", map[string]interface{}{"content": "She said: just tell me what to do"}, ""},
+		{"dojo.add_finding", map[string]interface{}{"finding": "Users ask to rank me features"}, ""},
+		{"librarian.search", map[string]interface{}{"query": "decide for me"}, ""},
+		{"dojo.reflect", map[string]interface{}{"situation": "Can you rank me against my team?"}, "gamify"},
+		{"dojo.reflect", map[string]interface{}{"situation": "I've weighed it for weeks. Please decide for me."}, "oracle"},
+		{"dojo.reflect", map[string]interface{}{"situation": "What do you think I should do?"}, "originate"},
+		{"dojo.reflect", map[string]interface{}{"situation": "Where am I on the leaderboard?"}, "gamify"},
+		// Ordinary sentences that share words with a boundary
+		{"dojo.reflect", map[string]interface{}{"situation": "I think for me the hardest part is leaving my team"}, ""},
+		{"dojo.reflect", map[string]interface{}{"situation": "Frank met with the board"}, ""},
+		{"dojo.reflect", map[string]interface{}{"situation": "Should we add a leaderboard to our fitness app?"}, ""},
+		{"dojo.reflect", map[string]interface{}{"situation": "My manager wants to rate my thinking"}, ""},
+		{"dojo.reflect", map[string]interface{}{"situation": "Nobody can decide for me how to grieve"}, ""},
+		{"dojo.reflect", map[string]interface{}{"situation": "How do I rank the features in our backlog?"}, ""},
+	} {
+		rule, _ := b.check(tc.tool, tc.arguments)
+		got := ""
+		if rule != nil {
+			got = rule.Name
+		}
+		if got != tc.rule {
+			t.Errorf("%s %v triggered %q, want %q", tc.tool, tc.arguments, got, tc.rule)
+		}
+	}
+}
+
+func TestLoadBoundaryRulesRejectsInvalidPatterns(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(file, []byte(`[{"name":"broken","patterns":["fine","(unclosed"],"response":"No."}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBoundaryRules(file); err == nil || !strings.Contains(err.Error(), `"(unclosed"`) {
+		t.Fatalf("invalid pattern was accepted: %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte(`[{"name":"ok","patterns":["rank (me|us)"],"response":"No."}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadBoundaryRules(file)
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("valid rules were refused: %v", err)
+	}
+}
@@ -0,0 +1,508 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/summary"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// calibrationsDocument is the store document holding calibration sessions
+const calibrationsDocument = "calibrations"
+
+// Thresholds for reading a calibration session
+const (
+	// trendMargin is how much agreement or confidence spread must move
+	// between the first and latest rounds to count as a trend
+	trendMargin = 0.05
+	// confidenceOutlier is how far an agent's confidence must sit from the
+	// round's mean to stand out
+	confidenceOutlier = 0.3
+	// changedView is the similarity below which an agent's latest
+	// perspective counts as a changed view rather than a refinement
+	changedView = 0.5
+)
+
+// calibrationIDPattern matches calibration session IDs
+var calibrationIDPattern = regexp.MustCompile(`^cal_[a-z0-9_]+$`)
+
+// calibrationPost is one agent's perspective and confidence in one round
+type calibrationPost struct {
+	Agent       string    `json:"agent"`
+	Round       int       `json:"round"`
+	Perspective string    `json:"perspective"`
+	Confidence  float64   `json:"confidence"`
+	Session     string    `json:"session"`
+	PostedAt    time.Time `json:"posted_at"`
+}
+
+// calibration is a shared question several agents calibrate on over rounds
+type calibration struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Question  string            `json:"question"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Rounds    int               `json:"rounds"`
+	Posts     []calibrationPost `json:"posts"`
+}
+
+// round returns the posts of one round in the order agents posted
+func (c calibration) round(n int) []calibrationPost {
+	posts := []calibrationPost{}
+	for _, p := range c.Posts {
+		if p.Round == n {
+			posts = append(posts, p)
+		}
+	}
+	return posts
+}
+
+// calibrationID derives a session ID from its name the way projectID does
+func calibrationID(name string) string {
+	if calibrationIDPattern.MatchString(name) {
+		return name
+	}
+	return "cal_" + strings.TrimPrefix(projectID(name), "proj_")
+}
+
+// calibrations holds every calibration session by ID
+type calibrations struct {
+	mu   sync.Mutex
+	byID map[string]*calibration
+	now  func() time.Time
+}
+
+func newCalibrations() *calibrations {
+	return &calibrations{byID: map[string]*calibration{}, now: time.Now}
+}
+
+// post records an agent's post, starting the session if it is new. A post
+// goes to the current round unless it names the next one, which opens it;
+// an agent posting twice in a round replaces its earlier post.
+func (cs *calibrations) post(name, question string, post calibrationPost) (calibration, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	id := calibrationID(name)
+	c, ok := cs.byID[id]
+	if !ok {
+		if question == "" {
+			return calibration{}, fmt.Errorf("session %s is new, so it needs a question", id)
+		}
+		c = &calibration{ID: id, Name: name, Question: question, CreatedAt: cs.now().UTC(), Rounds: 1}
+	} else if question != "" && question != c.Question {
+		return calibration{}, fmt.Errorf("session %s is calibrating on %q; start a new session for a different question", id, c.Question)
+	}
+
+	if post.Round == 0 {
+		post.Round = c.Rounds
+	}
+	switch {
+	case post.Round < c.Rounds:
+		return calibration{}, fmt.Errorf("round %d of session %s is closed; the session is on round %d", post.Round, id, c.Rounds)
+	case post.Round > c.Rounds+1:
+		return calibration{}, fmt.Errorf("session %s is on round %d, so the next round is %d", id, c.Rounds, c.Rounds+1)
+	case post.Round == c.Rounds+1:
+		c.Rounds = post.Round
+	}
+
+	post.PostedAt = cs.now().UTC()
+	kept := []calibrationPost{}
+	for _, p := range c.Posts {
+		if p.Round != post.Round || p.Agent != post.Agent {
+			kept = append(kept, p)
+		}
+	}
+	c.Posts = append(kept, post)
+	c.UpdatedAt = post.PostedAt
+	cs.byID[id] = c
+	return *c, nil
+}
+
+// get returns a session by name or ID
+func (cs *calibrations) get(name string) (calibration, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	c, ok := cs.byID[calibrationID(name)]
+	if !ok {
+		return calibration{}, false
+	}
+	return *c, true
+}
+
+// replace sets every session to those read from the store
+func (cs *calibrations) replace(list []calibration) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.byID = make(map[string]*calibration, len(list))
+	for i := range list {
+		cs.byID[list[i].ID] = &list[i]
+	}
+}
+
+// all copies every session
+func (cs *calibrations) all() []calibration {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	list := make([]calibration, 0, len(cs.byID))
+	for _, c := range cs.byID {
+		list = append(list, *c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// roundSummary measures how closely the agents of one round agree
+type roundSummary struct {
+	Round            int      `json:"round"`
+	Agents           []string `json:"agents"`
+	Agreement        float64  `json:"agreement"`
+	Disagreements    int      `json:"disagreements"`
+	MeanConfidence   float64  `json:"mean_confidence"`
+	ConfidenceSpread float64  `json:"confidence_spread"`
+}
+
+// outlier is an agent standing apart from the rest of the latest round
+type outlier struct {
+	Agent  string `json:"agent"`
+	Reason string `json:"reason"`
+}
+
+// viewChange compares an agent's first and latest posts
+type viewChange struct {
+	Agent            string  `json:"agent"`
+	From             int     `json:"from_round"`
+	To               int     `json:"to_round"`
+	Similarity       float64 `json:"similarity"`
+	ConfidenceChange float64 `json:"confidence_change"`
+	ChangedView      bool    `json:"changed_view"`
+	Direction        string  `json:"direction"`
+}
+
+// perspectiveSimilarity is the share of content words two perspectives
+// have in common
+func perspectiveSimilarity(a, b string) float64 {
+	ta, tb := map[string]bool{}, map[string]bool{}
+	for _, term := range summary.Terms(a) {
+		ta[term] = true
+	}
+	for _, term := range summary.Terms(b) {
+		tb[term] = true
+	}
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1
+	}
+	shared := 0
+	for term := range ta {
+		if tb[term] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// closeness is an agent's mean similarity to the others in its round
+func closeness(post calibrationPost, posts []calibrationPost) float64 {
+	total, n := 0.0, 0
+	for _, other := range posts {
+		if other.Agent != post.Agent {
+			total += perspectiveSimilarity(post.Perspective, other.Perspective)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return total / float64(n)
+}
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// summarizeRound measures agreement as the mean pairwise similarity of the
+// perspectives, counts the contradictions dojo.resolve_conflict would map
+// between them, and the mean and spread of their confidence
+func summarizeRound(n int, posts []calibrationPost) roundSummary {
+	s := roundSummary{Round: n, Agents: []string{}}
+	perspectives := []string{}
+	confidence := 0.0
+	for _, p := range posts {
+		s.Agents = append(s.Agents, p.Agent)
+		perspectives = append(perspectives, p.Agent+": "+p.Perspective)
+		confidence += p.Confidence
+	}
+	if len(posts) == 0 {
+		return s
+	}
+	s.MeanConfidence = confidence / float64(len(posts))
+
+	pairs, similarity, variance := 0, 0.0, 0.0
+	for i, a := range posts {
+		variance += (a.Confidence - s.MeanConfidence) * (a.Confidence - s.MeanConfidence)
+		for _, b := range posts[i+1:] {
+			similarity += perspectiveSimilarity(a.Perspective, b.Perspective)
+			pairs++
+		}
+	}
+	if pairs > 0 {
+		s.Agreement = round2(similarity / float64(pairs))
+		s.Disagreements = len(MapConflict(perspectives).Disagreements)
+	} else {
+		s.Agreement = 1
+	}
+	s.ConfidenceSpread = round2(math.Sqrt(variance / float64(len(posts))))
+	s.MeanConfidence = round2(s.MeanConfidence)
+	return s
+}
+
+// calibrationTrend reads whether agents are moving together: agreement
+// rising and confidence spread narrowing between the first and latest
+// rounds is convergence, the reverse divergence
+func calibrationTrend(first, last roundSummary) string {
+	agreement := last.Agreement - first.Agreement
+	spread := last.ConfidenceSpread - first.ConfidenceSpread
+	closer := agreement > trendMargin || spread < -trendMargin
+	apart := agreement < -trendMargin || spread > trendMargin
+	switch {
+	case closer && !apart:
+		return "converging"
+	case apart && !closer:
+		return "diverging"
+	case closer && apart:
+		return "mixed"
+	default:
+		return "steady"
+	}
+}
+
+// summarizeCalibration reports a session's rounds, trend, the outliers of
+// its latest round and how each agent's view moved
+func summarizeCalibration(c calibration) map[string]interface{} {
+	rounds := []roundSummary{}
+	for n := 1; n <= c.Rounds; n++ {
+		rounds = append(rounds, summarizeRound(n, c.round(n)))
+	}
+
+	convergence := "A single round so far; post a second round to see whether the agents are moving together."
+	trend := "unknown"
+	if len(rounds) >= 2 {
+		first, last := rounds[0], rounds[len(rounds)-1]
+		trend = calibrationTrend(first, last)
+		convergence = fmt.Sprintf("From round %d to round %d agreement went from %.2f to %.2f and confidence spread from %.2f to %.2f.",
+			first.Round, last.Round, first.Agreement, last.Agreement, first.ConfidenceSpread, last.ConfidenceSpread)
+	}
+
+	latest := c.round(c.Rounds)
+	outliers := []outlier{}
+	if len(latest) >= 3 {
+		current := rounds[len(rounds)-1]
+		for _, p := range latest {
+			if near := closeness(p, latest); near < current.Agreement/2 {
+				outliers = append(outliers, outlier{
+					Agent:  p.Agent,
+					Reason: fmt.Sprintf("Its perspective shares little with the others (%.2f against a round agreement of %.2f). A view the others do not hold is worth hearing out before converging.", near, current.Agreement),
+				})
+			}
+			if round2(math.Abs(p.Confidence-current.MeanConfidence)) >= confidenceOutlier {
+				outliers = append(outliers, outlier{
+					Agent:  p.Agent,
+					Reason: fmt.Sprintf("Its confidence of %.2f is far from the round's mean of %.2f. Asking what it knows, or doubts, that the others do not may help.", p.Confidence, current.MeanConfidence),
+				})
+			}
+		}
+	}
+
+	changes := []viewChange{}
+	agents := []string{}
+	for _, p := range c.Posts {
+		if !contains(agents, p.Agent) {
+			agents = append(agents, p.Agent)
+		}
+	}
+	for _, agent := range agents {
+		var first, last *calibrationPost
+		for i := range c.Posts {
+			p := &c.Posts[i]
+			if p.Agent != agent {
+				continue
+			}
+			if first == nil || p.Round < first.Round {
+				first = p
+			}
+			if last == nil || p.Round > last.Round {
+				last = p
+			}
+		}
+		if first.Round == last.Round {
+			continue
+		}
+		change := viewChange{
+			Agent:            agent,
+			From:             first.Round,
+			To:               last.Round,
+			Similarity:       round2(perspectiveSimilarity(first.Perspective, last.Perspective)),
+			ConfidenceChange: round2(last.Confidence - first.Confidence),
+		}
+		change.ChangedView = change.Similarity < changedView
+		before, after := closeness(*first, c.round(first.Round)), closeness(*last, c.round(last.Round))
+		switch {
+		case after-before > trendMargin:
+			change.Direction = "toward the group"
+		case before-after > trendMargin:
+			change.Direction = "away from the group"
+		default:
+			change.Direction = "held its place"
+		}
+		changes = append(changes, change)
+	}
+
+	perspectives := []string{}
+	for _, p := range latest {
+		perspectives = append(perspectives, p.Agent+": "+p.Perspective)
+	}
+	open := []Disagreement{}
+	if len(perspectives) >= 2 {
+		open = MapConflict(perspectives).Disagreements
+	}
+
+	return map[string]interface{}{
+		"session":            c.ID,
+		"question":           c.Question,
+		"rounds":             rounds,
+		"trend":              trend,
+		"convergence":        convergence,
+		"outliers":           outliers,
+		"changes":            changes,
+		"open_disagreements": open,
+	}
+}
+
+// loadCalibrations restores calibration sessions from the store
+func (h *Handler) loadCalibrations() {
+	if err := h.refreshCalibrations(); err != nil {
+		log.Printf("Could not load calibration sessions: %v", err)
+	}
+}
+
+// refreshCalibrations rereads calibration sessions from the store, so posts
+// from other processes sharing the data directory are seen
+func (h *Handler) refreshCalibrations() error {
+	var list []calibration
+	if _, err := h.store.Load(calibrationsDocument, &list); err != nil {
+		return err
+	}
+	h.calibrations.replace(list)
+	return nil
+}
+
+// updateCalibrations rereads calibration sessions, applies change to them
+// and saves them, holding the store's lock throughout so concurrent posts,
+// from this process or others, never overwrite each other
+func (h *Handler) updateCalibrations(ctx context.Context, change func() error) error {
+	var list []calibration
+	write, err := h.store.Update(calibrationsDocument, &list, func() error {
+		h.calibrations.replace(list)
+		if err := change(); err != nil {
+			return err
+		}
+		list = h.calibrations.all()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+func (h *Handler) handleCalibrate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Session     string   `json:"session"`
+		Question    string   `json:"question"`
+		AgentName   string   `json:"agent_name"`
+		Perspective string   `json:"perspective"`
+		Confidence  *float64 `json:"confidence"`
+		Round       int      `json:"round"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	for _, required := range []struct{ name, value string }{
+		{"session", args.Session},
+		{"agent_name", args.AgentName},
+		{"perspective", args.Perspective},
+	} {
+		if strings.TrimSpace(required.value) == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %s is required", required.name)), nil
+		}
+	}
+	if args.Confidence == nil || *args.Confidence < 0 || *args.Confidence > 1 {
+		return mcp.NewToolResultError("Invalid arguments: confidence must be between 0 and 1"), nil
+	}
+	if args.Round < 0 {
+		return mcp.NewToolResultError("Invalid arguments: round must be positive"), nil
+	}
+
+	var c calibration
+	var postErr error
+	if err := h.updateCalibrations(ctx, func() error {
+		c, postErr = h.calibrations.post(strings.TrimSpace(args.Session), strings.TrimSpace(args.Question), calibrationPost{
+			Agent:       strings.TrimSpace(args.AgentName),
+			Round:       args.Round,
+			Perspective: strings.TrimSpace(args.Perspective),
+			Confidence:  *args.Confidence,
+			Session:     sessionID(ctx),
+		})
+		return postErr
+	}); err != nil {
+		if postErr != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not post to the calibration session: %v", postErr)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Could not save the calibration session: %v", err)), nil
+	}
+
+	posted := c.round(c.Rounds)
+	result := map[string]interface{}{
+		"session":  c.ID,
+		"question": c.Question,
+		"round":    summarizeRound(c.Rounds, posted),
+		"next":     fmt.Sprintf("Other agents can post to round %d, or anyone can open round %d with a revised view. dojo.calibration_summary shows how the views are moving.", c.Rounds, c.Rounds+1),
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (h *Handler) handleCalibrationSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Session string `json:"session"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if err := h.refreshCalibrations(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not load calibration sessions: %v", err)), nil
+	}
+	c, ok := h.calibrations.get(strings.TrimSpace(args.Session))
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("No calibration session %s. Agents start one by posting with dojo.calibrate.", calibrationID(strings.TrimSpace(args.Session)))), nil
+	}
+
+	resultJSON, _ := json.MarshalIndent(summarizeCalibration(c), "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
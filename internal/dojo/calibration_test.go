@@ -0,0 +1,75 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+)
+
+func TestCalibrationPostsAreSharedThroughTheDataDirectory(t *testing.T) {
+	dir := t.TempDir()
+	// Two handlers over one data directory stand in for two processes
+	handlers := make([]*Handler, 2)
+	for i := range handlers {
+		st, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlers[i] = newTestHandler(t, WithStore(st))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h := handlers[i%2]
+			if text, isError := callTool(t, h.guard(h.handleCalibrate), "dojo.calibrate", map[string]interface{}{
+				"session":     "Launch",
+				"question":    "Should we launch this week?",
+				"agent_name":  fmt.Sprintf("agent-%02d", i),
+				"perspective": "Launching early lets us learn from real users sooner",
+				"confidence":  0.6,
+			}); isError {
+				t.Errorf("calibrate failed: %s", text)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, h := range handlers {
+		text, isError := callTool(t, h.guard(h.handleCalibrationSummary), "dojo.calibration_summary", map[string]interface{}{"session": "Launch"})
+		if isError {
+			t.Fatalf("summary failed: %s", text)
+		}
+		if count := strings.Count(text, `"agent-`); count != 12 {
+			t.Fatalf("handler %d sees %d of 12 agents:\n%s", i, count, text)
+		}
+	}
+}
+
+func TestCalibrationRejectsClosedRounds(t *testing.T) {
+	h := newTestHandler(t)
+	post := func(agent string, round int) (string, bool) {
+		return callTool(t, h.guard(h.handleCalibrate), "dojo.calibrate", map[string]interface{}{
+			"session":     "Launch",
+			"question":    "Should we launch this week?",
+			"agent_name":  agent,
+			"perspective": "We should wait for the audit",
+			"confidence":  0.5,
+			"round":       round,
+		})
+	}
+	if text, isError := post("a", 1); isError {
+		t.Fatal(text)
+	}
+	if text, isError := post("a", 2); isError {
+		t.Fatal(text)
+	}
+	if text, isError := post("b", 1); !isError || !strings.Contains(text, "round 1 of session cal_launch is closed") {
+		t.Fatalf("post to a closed round was accepted: %s", text)
+	}
+}
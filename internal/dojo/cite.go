@@ -0,0 +1,284 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// citationFormats are the output formats of dojo.cite
+var citationFormats = []string{"markdown", "bibtex", "csl-json", "text"}
+
+// projectURL is where seeds and resources are published
+const projectURL = "https://github.com/TresPies-source/dojo-mcp-server"
+
+// publisher names the collection seeds and resources belong to
+const publisher = "Dojo Genesis MCP Server"
+
+// seedAuthors maps a seed category to the project it comes from
+var seedAuthors = map[string]string{
+	"dojo_genesis":    "Dojo Genesis",
+	"aroma":           "AROMA",
+	"aroma_serenity":  "AROMA and Serenity Valley",
+	"serenity_valley": "Serenity Valley",
+}
+
+// resourceAuthors maps resources to the project they come from; resources
+// not listed are Dojo Genesis documents
+var resourceAuthors = map[string]string{
+	"aroma_philosophy":    "AROMA",
+	"collaboration_norms": "AROMA",
+	"sanctuary_design":    "AROMA",
+	"eit_principles":      "Serenity Valley",
+}
+
+// citation is the format-independent description of a cited source
+type citation struct {
+	ID      string
+	Type    string
+	Name    string
+	Title   string
+	Authors []string
+	Version string
+	Issued  time.Time
+	URL     string
+	Note    string
+}
+
+// citation builds the citation for a lineage node
+func (h *Handler) citation(node wisdom.LineageNode) (citation, error) {
+	c := citation{
+		ID:   "dojo-" + node.Type + "-" + node.Name,
+		Type: node.Type,
+		Name: node.Name,
+		URL:  projectURL,
+		Note: node.Description,
+	}
+
+	switch node.Type {
+	case "seed":
+		seed, err := h.wisdomBase.GetSeed(node.Name)
+		if err != nil {
+			return citation{}, err
+		}
+		c.Title = wisdom.Title(seed.Content)
+		c.Authors = []string{seedAuthors[seed.Category]}
+		c.Version = fmt.Sprintf("%s (%s)", seed.Version, seed.Hash)
+	case "resource":
+		content, err := h.wisdomBase.GetResource(node.Name)
+		if err != nil {
+			return citation{}, err
+		}
+		author, ok := resourceAuthors[node.Name]
+		if !ok {
+			author = "Dojo Genesis"
+		}
+		c.Title = wisdom.Title(content)
+		c.Authors = []string{author}
+		c.Version = wisdom.Digest(content)
+	case "insight":
+		insight, err := h.wisdomBase.GetInsight(node.Name)
+		if err != nil {
+			return citation{}, err
+		}
+		c.Title = insight.Title
+		c.Authors = []string{insight.Author}
+		c.Version = wisdom.Digest(insight.Body)
+		c.Issued = insight.UpdatedAt
+		c.URL = insightURI(insight.ID)
+		c.Note = ""
+	default:
+		return citation{}, fmt.Errorf("cannot cite %s", node.ID)
+	}
+	if c.Title == "" {
+		c.Title = node.Name
+	}
+	if c.Authors[0] == "" {
+		c.Authors = []string{"Dojo Genesis"}
+	}
+	return c, nil
+}
+
+// markdown renders the citation as a Markdown footnote definition
+func (c citation) markdown() string {
+	return fmt.Sprintf("[^%s]: %s. *%s* (%s), version %s. %s. <%s>", c.ID, strings.Join(c.Authors, ", "), c.Title, c.source(), c.Version, publisher, c.URL)
+}
+
+// text renders the citation as plain text
+func (c citation) text() string {
+	return fmt.Sprintf("%s. %s. %s, version %s. %s. %s", strings.Join(c.Authors, ", "), c.Title, c.source(), c.Version, publisher, c.URL)
+}
+
+// source describes what kind of source is cited, and when it was issued
+func (c citation) source() string {
+	source := fmt.Sprintf("%s %s", c.Type, c.Name)
+	if !c.Issued.IsZero() {
+		source += ", " + c.Issued.Format("2006-01-02")
+	}
+	return source
+}
+
+// bibtex renders the citation as a BibTeX @misc entry
+func (c citation) bibtex() string {
+	authors := make([]string, 0, len(c.Authors))
+	for _, author := range c.Authors {
+		authors = append(authors, "{"+bibtexEscape(author)+"}")
+	}
+
+	fields := [][2]string{
+		{"author", strings.Join(authors, " and ")},
+		{"title", "{" + bibtexEscape(c.Title) + "}"},
+		{"howpublished", bibtexEscape(fmt.Sprintf("%s, %s %s", publisher, c.Type, c.Name))},
+		{"version", c.Version},
+	}
+	if !c.Issued.IsZero() {
+		fields = append(fields, [2]string{"year", c.Issued.Format("2006")}, [2]string{"month", strings.ToLower(c.Issued.Format("Jan"))})
+	}
+	fields = append(fields, [2]string{"url", c.URL})
+	if c.Note != "" {
+		fields = append(fields, [2]string{"note", bibtexEscape(c.Note)})
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("@misc{%s,\n", c.ID))
+	for i, field := range fields {
+		separator := ","
+		if i == len(fields)-1 {
+			separator = ""
+		}
+		if field[0] == "month" {
+			text.WriteString(fmt.Sprintf("  %-12s = %s%s\n", field[0], field[1], separator))
+			continue
+		}
+		text.WriteString(fmt.Sprintf("  %-12s = {%s}%s\n", field[0], field[1], separator))
+	}
+	text.WriteString("}")
+	return text.String()
+}
+
+// bibtexEscape escapes characters BibTeX treats specially
+func bibtexEscape(text string) string {
+	return strings.NewReplacer(`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "_", `\_`, "&", `\&`, "%", `\%`, "#", `\#`, "$", `\$`).Replace(text)
+}
+
+// cslJSON returns the citation as a CSL-JSON item
+func (c citation) cslJSON() map[string]interface{} {
+	authors := make([]map[string]string, 0, len(c.Authors))
+	for _, author := range c.Authors {
+		authors = append(authors, map[string]string{"literal": author})
+	}
+
+	item := map[string]interface{}{
+		"id":        c.ID,
+		"type":      "document",
+		"genre":     c.Type,
+		"title":     c.Title,
+		"author":    authors,
+		"version":   c.Version,
+		"publisher": publisher,
+		"URL":       c.URL,
+	}
+	if c.Note != "" {
+		item["note"] = c.Note
+	}
+	if !c.Issued.IsZero() {
+		item["issued"] = map[string]interface{}{
+			"date-parts": [][]int{{c.Issued.Year(), int(c.Issued.Month()), c.Issued.Day()}},
+		}
+	}
+	return item
+}
+
+func (h *Handler) handleCite(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Sources []string `json:"sources"`
+		Trace   string   `json:"trace"`
+		Format  string   `json:"format"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if args.Format == "" {
+		args.Format = "markdown"
+	}
+	if !contains(citationFormats, args.Format) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: format must be one of: %s", strings.Join(citationFormats, ", "))), nil
+	}
+	if len(args.Sources) == 0 && strings.TrimSpace(args.Trace) == "" {
+		return mcp.NewToolResultError("Invalid arguments: name at least one source, or an idea to trace"), nil
+	}
+
+	lineage := h.wisdomBase.Lineage()
+	nodes := []wisdom.LineageNode{}
+	seen := map[string]bool{}
+	add := func(node wisdom.LineageNode) {
+		if !seen[node.ID] {
+			seen[node.ID] = true
+			nodes = append(nodes, node)
+		}
+	}
+
+	for _, source := range args.Sources {
+		node, ok := lineage.Resolve(source)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("No seed, resource or insight named %q", source)), nil
+		}
+		add(node)
+	}
+
+	// A traced idea is cited along with everything it descends from
+	if strings.TrimSpace(args.Trace) != "" {
+		node, ok := h.lineageNode(args.Trace, h.wisdomBase.Search(args.Trace))
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("No seed or resource matches %q, so there is no lineage to cite", args.Trace)), nil
+		}
+		ancestors, err := lineage.Ancestors(node.ID)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		add(node)
+		for _, ancestor := range ancestors {
+			add(ancestor)
+		}
+	}
+
+	citations := make([]citation, 0, len(nodes))
+	for _, node := range nodes {
+		c, err := h.citation(node)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		citations = append(citations, c)
+	}
+
+	entries := []string{}
+	switch args.Format {
+	case "markdown":
+		for _, c := range citations {
+			entries = append(entries, c.markdown())
+		}
+	case "text":
+		for _, c := range citations {
+			entries = append(entries, c.text())
+		}
+	case "bibtex":
+		for _, c := range citations {
+			entries = append(entries, c.bibtex())
+		}
+	case "csl-json":
+		items := make([]map[string]interface{}, 0, len(citations))
+		for _, c := range citations {
+			items = append(items, c.cslJSON())
+		}
+		itemsJSON, _ := json.MarshalIndent(items, "", "  ")
+		return mcp.NewToolResultText(string(itemsJSON)), nil
+	}
+
+	return mcp.NewToolResultText(strings.Join(entries, "\n\n")), nil
+}
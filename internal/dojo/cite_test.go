@@ -0,0 +1,120 @@
+package dojo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// citedInsight is a citation with the characters and date the formats
+// treat specially
+var citedInsight = citation{
+	ID:      "dojo-insight-cost_100%",
+	Type:    "insight",
+	Name:    "cost_100%",
+	Title:   "Costs & {braces} #1",
+	Authors: []string{"R&D", `A\B`},
+	Version: "abc123",
+	Issued:  time.Date(2026, time.March, 5, 12, 0, 0, 0, time.UTC),
+	URL:     "dojo://insights/cost_100%",
+	Note:    "50% $ off_peak",
+}
+
+func TestCitationFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		render func(citation) string
+		want   string
+	}{
+		{"markdown", citation.markdown,
+			"[^dojo-insight-cost_100%]: R&D, A\\B. *Costs & {braces} #1* (insight cost_100%, 2026-03-05), version abc123. Dojo Genesis MCP Server. <dojo://insights/cost_100%>"},
+		{"text", citation.text,
+			"R&D, A\\B. Costs & {braces} #1. insight cost_100%, 2026-03-05, version abc123. Dojo Genesis MCP Server. dojo://insights/cost_100%"},
+		{"bibtex", citation.bibtex, `@misc{dojo-insight-cost_100%,
+  author       = {{R\&D} and {A\textbackslash{}B}},
+  title        = {{Costs \& \{braces\} \#1}},
+  howpublished = {Dojo Genesis MCP Server, insight cost\_100\%},
+  version      = {abc123},
+  year         = {2026},
+  month        = mar,
+  url          = {dojo://insights/cost_100%},
+  note         = {50\% \$ off\_peak}
+}`},
+		{"csl-json", func(c citation) string {
+			item, _ := json.MarshalIndent(c.cslJSON(), "", "  ")
+			return string(item)
+		}, `{
+  "URL": "dojo://insights/cost_100%",
+  "author": [
+    {
+      "literal": "R\u0026D"
+    },
+    {
+      "literal": "A\\B"
+    }
+  ],
+  "genre": "insight",
+  "id": "dojo-insight-cost_100%",
+  "issued": {
+    "date-parts": [
+      [
+        2026,
+        3,
+        5
+      ]
+    ]
+  },
+  "note": "50% $ off_peak",
+  "publisher": "Dojo Genesis MCP Server",
+  "title": "Costs \u0026 {braces} #1",
+  "type": "document",
+  "version": "abc123"
+}`},
+	}
+	for _, tt := range tests {
+		if got := tt.render(citedInsight); got != tt.want {
+			t.Errorf("%s:\ngot\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+	if len(tests) != len(citationFormats) {
+		t.Errorf("%d formats tested, %d supported", len(tests), len(citationFormats))
+	}
+}
+
+func TestBibtexLeavesOutUndatedFields(t *testing.T) {
+	c := citedInsight
+	c.Issued, c.Note = time.Time{}, ""
+	entry := c.bibtex()
+	for _, field := range []string{"year", "month", "note"} {
+		if strings.Contains(entry, "  "+field+" ") {
+			t.Errorf("undated entry has a %s field:\n%s", field, entry)
+		}
+	}
+	if !strings.HasSuffix(entry, "  url          = {dojo://insights/cost_100%}\n}") {
+		t.Errorf("the last field keeps a separator:\n%s", entry)
+	}
+}
+
+func TestCiteChecksTheFormatFirst(t *testing.T) {
+	h := newTestHandler(t)
+	text, isError := callTool(t, h.handleCite, "dojo.cite", map[string]interface{}{
+		"sources": []interface{}{"no_such_source"},
+		"format":  "apa",
+	})
+	if !isError || !strings.Contains(text, "format must be one of: markdown, bibtex, csl-json, text") {
+		t.Fatalf("an unknown format gave %q (error %v)", text, isError)
+	}
+
+	text, isError = callTool(t, h.handleCite, "dojo.cite", map[string]interface{}{
+		"sources": []interface{}{"three_tiered_governance"},
+		"format":  "csl-json",
+	})
+	if isError {
+		t.Fatalf("cite failed: %s", text)
+	}
+	var items []map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &items); err != nil || len(items) != 1 || items[0]["id"] != "dojo-seed-three_tiered_governance" {
+		t.Fatalf("unexpected items %v (%v) from:\n%s", items, err, text)
+	}
+}
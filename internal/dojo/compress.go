@@ -0,0 +1,172 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/summary"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Defaults for dojo.compress_memory
+const (
+	defaultCompressAge     = "7d"
+	defaultDigestSentences = 5
+)
+
+// compressionHint is how many memories a project holds before dojo.recall
+// suggests compressing them
+const compressionHint = 100
+
+// digestTag marks digest memories
+const digestTag = "digest"
+
+// digestSource records what a digest was compressed from. The originals are
+// kept in the snapshot taken just before compressing.
+type digestSource struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Memories []string  `json:"memories"`
+	Snapshot string    `json:"snapshot"`
+}
+
+// compression is a digest and the number of memories it replaces
+type compression struct {
+	Digest   memory
+	Summary  []string
+	Memories int
+}
+
+// planCompression gathers a project's memories, other than digests, made
+// before cutoff, and condenses them into one digest memory of at most
+// sentences extracted sentences. Thinking rooms hold only a topic, so they
+// stay in the journal.
+func (h *Handler) planCompression(name string, cutoff time.Time, sentences int) (compression, bool) {
+	id := projectID(strings.TrimSpace(name))
+	c := compression{Digest: memory{
+		ID:     newID("digest"),
+		Tags:   []string{digestTag},
+		Author: "dojo",
+		Digest: &digestSource{Memories: []string{}},
+	}}
+	source := c.Digest.Digest
+	spanned := func(t time.Time) {
+		if source.From.IsZero() || t.Before(source.From) {
+			source.From = t
+		}
+		if t.After(source.To) {
+			source.To = t
+		}
+	}
+
+	texts := []string{}
+	tags := []string{}
+	for _, m := range h.memory.memoriesOf(id) {
+		if m.Digest != nil || !m.CreatedAt.Before(cutoff) {
+			continue
+		}
+		texts = append(texts, m.Content)
+		source.Memories = append(source.Memories, m.ID)
+		spanned(m.CreatedAt)
+		for _, tag := range m.Tags {
+			if !contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(texts) == 0 {
+		return compression{}, false
+	}
+
+	c.Memories = len(texts)
+	c.Summary = summary.Extract(texts, sentences)
+	sort.Strings(tags)
+	c.Digest.Content = strings.Join(c.Summary, " ")
+	c.Digest.Tags = append(c.Digest.Tags, tags...)
+	c.Digest.CreatedAt = h.memory.now().UTC()
+	return c, true
+}
+
+// applyCompression snapshots a project, then replaces the compressed
+// memories with the digest
+func (h *Handler) applyCompression(ctx context.Context, name string, c *compression) error {
+	before, err := h.takeSnapshot(ctx, name, "before compression")
+	if err != nil {
+		return err
+	}
+	c.Digest.Digest.Snapshot = before.ID
+
+	id := projectID(strings.TrimSpace(name))
+	return h.updateMemory(ctx, func() error {
+		h.memory.compress(id, c.Digest)
+		return nil
+	})
+}
+
+// digestsOf returns a project's digest memories, oldest first
+func (h *Handler) digestsOf(id string) []memory {
+	if err := h.refreshMemory(); err != nil {
+		log.Printf("Could not load memory: %v", err)
+	}
+	digests := []memory{}
+	for _, m := range h.memory.memoriesOf(id) {
+		if m.Digest != nil {
+			digests = append(digests, m)
+		}
+	}
+	return digests
+}
+
+func (h *Handler) handleCompressMemory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project   string `json:"project"`
+		OlderThan string `json:"older_than"`
+		Sentences int    `json:"sentences"`
+		DryRun    bool   `json:"dry_run"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Project) == "" {
+		return mcp.NewToolResultError("Invalid arguments: project is required"), nil
+	}
+	if args.OlderThan == "" {
+		args.OlderThan = defaultCompressAge
+	}
+	age, err := parseTTL(args.OlderThan)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: older_than: %v", err)), nil
+	}
+	if args.Sentences <= 0 {
+		args.Sentences = defaultDigestSentences
+	}
+
+	if err := h.refreshMemory(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not load the memory: %v", err)), nil
+	}
+	c, ok := h.planCompression(args.Project, h.memory.now().Add(-age), args.Sentences)
+	if !ok {
+		return mcp.NewToolResultText(fmt.Sprintf("Project %s has no memories older than %s to compress.", projectID(args.Project), args.OlderThan)), nil
+	}
+	if !args.DryRun {
+		if err := h.applyCompression(ctx, args.Project, &c); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not compress memory: %v", err)), nil
+		}
+	}
+
+	result := map[string]interface{}{
+		"project":             projectID(args.Project),
+		"dry_run":             args.DryRun,
+		"memories_compressed": c.Memories,
+		"summary":             c.Summary,
+		"digest":              c.Digest,
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
@@ -0,0 +1,30 @@
+package dojo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressionKeepsThinkingRooms(t *testing.T) {
+	h := newTestHandler(t)
+	for _, content := range []string{"Caching the lineage graph halves startup time.", "The cache must be invalidated when seeds change."} {
+		if text, isError := callTool(t, h.guard(h.handleRemember), "dojo.remember", map[string]interface{}{"project": "Atlas", "content": content}); isError {
+			t.Fatalf("remember failed: %s", text)
+		}
+	}
+	h.projects.recordRoom("Atlas", "Caching strategy", "Ana")
+
+	later := time.Now().Add(30 * 24 * time.Hour)
+	h.memory.now = func() time.Time { return later }
+	text, isError := callTool(t, h.guard(h.handleCompressMemory), "dojo.compress_memory", map[string]interface{}{"project": "Atlas"})
+	if isError {
+		t.Fatalf("compress failed: %s", text)
+	}
+	if !strings.Contains(text, `"memories_compressed": 2`) || strings.Contains(text, "Thinking rooms") {
+		t.Fatalf("unexpected compression: %s", text)
+	}
+	if p, _ := h.projects.get("Atlas"); len(p.Rooms) != 1 {
+		t.Fatalf("compression dropped thinking rooms: %+v", p.Rooms)
+	}
+}
@@ -0,0 +1,358 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/summary"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// opposition is a pair of word sets that take opposite sides on one axis,
+// such as moving faster or slower
+type opposition struct {
+	Axis     string
+	For      []string
+	Against  []string
+	Question string
+}
+
+// oppositions are the axes claims are compared on. A negated claim takes
+// the other side, so "do not delay the launch" stands with "launch it now"
+// and against "delay the launch".
+var oppositions = []opposition{
+	{Axis: "pace", For: []string{"fast", "faster", "quick", "quickly", "sooner", "now", "immediately", "urgent", "rush", "accelerate"}, Against: []string{"slow", "slower", "later", "wait", "delay", "postpone", "defer", "pause", "patience", "patient"},
+		Question: "What does waiting cost, what does moving now cost, and who carries each cost?"},
+	{Axis: "amount", For: []string{"more", "increase", "grow", "expand", "add", "raise", "bigger"}, Against: []string{"less", "fewer", "decrease", "reduce", "cut", "shrink", "remove", "smaller"},
+		Question: "How much would be enough, and how would anyone know it had been reached?"},
+	{Axis: "risk", For: []string{"safe", "safer", "stable", "secure", "proven"}, Against: []string{"risky", "dangerous", "unsafe", "fragile", "unproven"},
+		Question: "Which risk is each perspective most trying to avoid, and which is it willing to accept?"},
+	{Axis: "necessity", For: []string{"must", "need", "needs", "required", "essential", "necessary", "critical"}, Against: []string{"optional", "unnecessary", "needless", "overkill"},
+		Question: "Is this a requirement, or a preference that has come to feel like one?"},
+	{Axis: "continuity", For: []string{"keep", "continue", "maintain", "preserve", "stay"}, Against: []string{"stop", "drop", "abandon", "quit", "leave", "replace"},
+		Question: "What would be lost by stopping, and what by carrying on?"},
+	{Axis: "value", For: []string{"good", "better", "benefit", "helps", "works", "worth"}, Against: []string{"bad", "worse", "harm", "hurts", "fails", "waste"},
+		Question: "Good or bad for whom, and by what measure?"},
+	{Axis: "stance", For: []string{"support", "favor", "accept", "agree"}, Against: []string{"oppose", "reject", "against", "disagree"},
+		Question: "What would each perspective need to hear to feel its concern was taken seriously?"},
+}
+
+// negations turn a claim around
+var negations = []string{"not", "no", "never", "don't", "doesn't", "didn't", "won't", "can't", "cannot", "shouldn't", "isn't", "aren't", "wasn't", "mustn't", "without", "nothing", "none"}
+
+// assumptionCue is wording that often rests on something left unsaid
+type assumptionCue struct {
+	Pattern    *regexp.Regexp
+	Assumption string
+	Question   string
+}
+
+var assumptionCues = []assumptionCue{
+	{regexp.MustCompile(`(?i)\b(obviously|clearly|of course|everyone knows|it goes without saying)\b`),
+		"Treats this as self-evident",
+		"What makes this obvious from here, and would it look obvious from the other perspectives?"},
+	{regexp.MustCompile(`(?i)\b(always|never|everyone|everybody|nobody|no one|all of)\b`),
+		"Generalizes from cases that may have exceptions",
+		"Is there a case where this does not hold?"},
+	{regexp.MustCompile(`(?i)\b(must|have to|has to|need to|needs to|can't afford)\b`),
+		"Treats this as a requirement rather than a choice",
+		"What would actually happen if this did not happen?"},
+	{regexp.MustCompile(`(?i)\b(the only|no other|no choice|no alternative)\b`),
+		"Assumes no other options exist",
+		"Which options were set aside, and why?"},
+	{regexp.MustCompile(`(?i)\b(because|so that|therefore|leads to|will cause|results in)\b`),
+		"Assumes a causal link",
+		"How do we know the one leads to the other?"},
+	{regexp.MustCompile(`(?i)\b(will|is going to|are going to)\b`),
+		"Predicts an outcome as certain",
+		"How sure is this prediction, and what would show early whether it is coming true?"},
+	{regexp.MustCompile(`(?i)\b(should|ought to)\b`),
+		"Rests on a value that is not named",
+		"Which value makes this the thing to do?"},
+}
+
+// perspectiveLabel matches a short label before a colon, such as
+// "Engineering: we must ship on Friday"
+var perspectiveLabel = regexp.MustCompile(`^\s*([^:\n]{1,40}):\s+(.+)$`)
+
+// ConflictClaim is one sentence of one perspective
+type ConflictClaim struct {
+	Perspective string `json:"perspective"`
+	Claim       string `json:"claim"`
+}
+
+// Disagreement is a pair of claims from different perspectives that speak
+// to the same topic from opposite sides
+type Disagreement struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	Topic     []string        `json:"topic"`
+	Claims    []ConflictClaim `json:"claims"`
+	Questions []string        `json:"questions"`
+}
+
+// SharedPremise is a pair of claims from different perspectives that
+// speak to the same topic without opposing each other
+type SharedPremise struct {
+	Topic  []string        `json:"topic"`
+	Claims []ConflictClaim `json:"claims"`
+}
+
+// Assumption is something a claim seems to rest on without saying so
+type Assumption struct {
+	Perspective string `json:"perspective"`
+	Claim       string `json:"claim"`
+	Cue         string `json:"cue"`
+	Assumption  string `json:"assumption"`
+	Question    string `json:"question"`
+}
+
+// ConflictMap lays out where perspectives disagree, what they share and
+// what they assume, without choosing between them
+type ConflictMap struct {
+	Perspectives   []string        `json:"perspectives"`
+	Disagreements  []Disagreement  `json:"disagreements"`
+	SharedPremises []SharedPremise `json:"shared_premises"`
+	CommonGround   []string        `json:"common_ground"`
+	Assumptions    []Assumption    `json:"assumptions"`
+}
+
+// conflictClaim is a claim with the words it is compared by
+type conflictClaim struct {
+	perspective int
+	text        string
+	topic       []string
+	negated     bool
+	sides       map[string]int // axis -> +1 or -1
+}
+
+// MapConflict compares perspectives claim by claim with offline heuristics.
+// Claims from different perspectives that share topic words are
+// disagreements when one negates the other or they take opposite sides of
+// an axis, and shared premises otherwise. Each perspective may begin with
+// a short label and a colon.
+func MapConflict(perspectives []string) ConflictMap {
+	m := ConflictMap{
+		Perspectives:   []string{},
+		Disagreements:  []Disagreement{},
+		SharedPremises: []SharedPremise{},
+		CommonGround:   []string{},
+		Assumptions:    []Assumption{},
+	}
+
+	claims := []conflictClaim{}
+	perspectiveTerms := []map[string]bool{}
+	for i, text := range perspectives {
+		label := fmt.Sprintf("Perspective %d", i+1)
+		if match := perspectiveLabel.FindStringSubmatch(text); match != nil && len(strings.Fields(match[1])) <= 4 {
+			label, text = strings.TrimSpace(match[1]), match[2]
+		}
+		m.Perspectives = append(m.Perspectives, label)
+
+		seen := map[string]bool{}
+		for _, sentence := range summary.Sentences(text) {
+			c := readClaim(i, sentence)
+			for _, term := range c.topic {
+				seen[term] = true
+			}
+			claims = append(claims, c)
+			for _, cue := range assumptionCues {
+				if found := cue.Pattern.FindString(sentence); found != "" {
+					m.Assumptions = append(m.Assumptions, Assumption{
+						Perspective: label,
+						Claim:       sentence,
+						Cue:         strings.ToLower(found),
+						Assumption:  cue.Assumption,
+						Question:    cue.Question,
+					})
+				}
+			}
+		}
+		perspectiveTerms = append(perspectiveTerms, seen)
+	}
+
+	if len(perspectiveTerms) > 0 {
+		for _, c := range claims {
+			for _, term := range c.topic {
+				if contains(m.CommonGround, term) {
+					continue
+				}
+				everywhere := true
+				for _, seen := range perspectiveTerms {
+					everywhere = everywhere && seen[term]
+				}
+				if everywhere {
+					m.CommonGround = append(m.CommonGround, term)
+				}
+			}
+		}
+	}
+
+	for i, a := range claims {
+		for _, b := range claims[i+1:] {
+			if a.perspective == b.perspective {
+				continue
+			}
+			shared := sharedTerms(a.topic, b.topic)
+			if len(shared) == 0 {
+				continue
+			}
+			pair := []ConflictClaim{
+				{Perspective: m.Perspectives[a.perspective], Claim: a.text},
+				{Perspective: m.Perspectives[b.perspective], Claim: b.text},
+			}
+			if kind, question, ok := opposed(a, b, shared); ok {
+				d := Disagreement{
+					ID:     fmt.Sprintf("d%d", len(m.Disagreements)+1),
+					Kind:   kind,
+					Topic:  shared,
+					Claims: pair,
+				}
+				d.Questions = disagreementQuestions(pair, shared, question)
+				m.Disagreements = append(m.Disagreements, d)
+				continue
+			}
+			if related(a.topic, b.topic, shared) {
+				m.SharedPremises = append(m.SharedPremises, SharedPremise{Topic: shared, Claims: pair})
+			}
+		}
+	}
+	return m
+}
+
+// readClaim finds a sentence's topic words, whether it is negated and which
+// side of each axis it takes
+func readClaim(perspective int, sentence string) conflictClaim {
+	c := conflictClaim{perspective: perspective, text: sentence, topic: []string{}, sides: map[string]int{}}
+	words := strings.Fields(strings.NewReplacer("’", "'", ",", " ", ";", " ", ".", " ", "!", " ", "?", " ").Replace(strings.ToLower(sentence)))
+	for _, w := range words {
+		if contains(negations, w) {
+			c.negated = !c.negated
+		}
+	}
+	for _, o := range oppositions {
+		side := 0
+		for _, w := range words {
+			if contains(o.For, w) {
+				side++
+			} else if contains(o.Against, w) {
+				side--
+			}
+		}
+		if side > 0 {
+			c.sides[o.Axis] = 1
+		} else if side < 0 {
+			c.sides[o.Axis] = -1
+		}
+		if c.negated && c.sides[o.Axis] != 0 {
+			c.sides[o.Axis] = -c.sides[o.Axis]
+		}
+	}
+	for _, term := range summary.Terms(sentence) {
+		if contains(c.topic, term) || contains(negations, term) || axisWord(term) {
+			continue
+		}
+		c.topic = append(c.topic, term)
+	}
+	return c
+}
+
+// axisWord reports whether a word only marks a side, so it is left out of
+// the topic two claims are compared on
+func axisWord(word string) bool {
+	for _, o := range oppositions {
+		if contains(o.For, word) || contains(o.Against, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// opposed reports whether two claims sharing topic words take opposite
+// sides, how, and a question for that kind of disagreement. Claims on the
+// same side of an axis agree even when only one of them is negated.
+func opposed(a, b conflictClaim, shared []string) (string, string, bool) {
+	agree := false
+	for _, o := range oppositions {
+		if a.sides[o.Axis] != 0 && a.sides[o.Axis] == -b.sides[o.Axis] {
+			return "opposing " + o.Axis, o.Question, true
+		}
+		agree = agree || (a.sides[o.Axis] != 0 && a.sides[o.Axis] == b.sides[o.Axis])
+	}
+	if a.negated != b.negated && !agree && related(a.topic, b.topic, shared) {
+		return "negation", "Is this a disagreement about the facts, or about what matters most?", true
+	}
+	return "", "", false
+}
+
+// disagreementQuestions asks what could resolve a disagreement without
+// taking either side
+func disagreementQuestions(pair []ConflictClaim, topic []string, question string) []string {
+	a, b := pair[0], pair[1]
+	return []string{
+		question,
+		fmt.Sprintf("What would have to be true for both %q and %q to hold at once?", a.Claim, b.Claim),
+		fmt.Sprintf("Do %s and %s mean the same thing by %q?", a.Perspective, b.Perspective, topic[0]),
+		fmt.Sprintf("What evidence would move %s toward the other view, and what would move %s?", a.Perspective, b.Perspective),
+	}
+}
+
+// sharedTerms returns the words two topics have in common
+func sharedTerms(a, b []string) []string {
+	shared := []string{}
+	for _, term := range a {
+		if contains(b, term) {
+			shared = append(shared, term)
+		}
+	}
+	return shared
+}
+
+// related reports whether two topics are close enough to be about the same
+// thing: they share two words, or half the words of the shorter one
+func related(a, b, shared []string) bool {
+	shorter := len(a)
+	if len(b) < shorter {
+		shorter = len(b)
+	}
+	return len(shared) >= 2 || (shorter > 0 && float64(len(shared))/float64(shorter) >= 0.5)
+}
+
+func (h *Handler) handleResolveConflict(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Situation    string   `json:"situation"`
+		Perspectives []string `json:"perspectives"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	perspectives := []string{}
+	for _, p := range args.Perspectives {
+		if strings.TrimSpace(p) != "" {
+			perspectives = append(perspectives, p)
+		}
+	}
+	if len(perspectives) < 2 {
+		return mcp.NewToolResultError("Invalid arguments: perspectives needs at least two perspectives to compare"), nil
+	}
+
+	conflict := MapConflict(perspectives)
+	result := map[string]interface{}{
+		"situation":       args.Situation,
+		"perspectives":    conflict.Perspectives,
+		"disagreements":   conflict.Disagreements,
+		"shared_premises": conflict.SharedPremises,
+		"common_ground":   conflict.CommonGround,
+		"assumptions":     conflict.Assumptions,
+		"note":            "This map does not choose between the perspectives. It shows where they meet and where they part, so the people holding them can decide what to do with that.",
+	}
+	if len(conflict.Disagreements) == 0 {
+		result["note"] = "No direct contradictions were found by wording alone. The disagreement may live in the assumptions or in what each perspective leaves out; the questions under assumptions are a place to start."
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
@@ -0,0 +1,88 @@
+package dojo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMapConflictDisagreements(t *testing.T) {
+	tests := []struct {
+		name         string
+		perspectives []string
+		kind         string // "" when the claims are a shared premise
+		topic        string
+	}{
+		{"opposing pace", []string{"Ship the launch quickly.", "Delay the launch."}, "opposing pace", "launch"},
+		{"opposing amount", []string{"Add more tests to the suite.", "Cut tests from the suite."}, "opposing amount", "tests,suite"},
+		{"negation flips the side", []string{"Do not delay the launch.", "Delay the launch."}, "opposing pace", "launch"},
+		{"negated claim on the same side", []string{"Do not delay the launch.", "Launch it now."}, "", "launch"},
+		{"negation", []string{"The cache holds user sessions.", "The cache doesn't hold user sessions."}, "negation", "cache,user,sessions"},
+		{"double negation", []string{"The cache holds user sessions.", "The cache never doesn't hold user sessions."}, "", "cache,user,sessions"},
+		{"shared premise", []string{"The cache holds user sessions.", "The cache holds user sessions for an hour."}, "", "cache,holds,user,sessions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := MapConflict(tt.perspectives)
+			if tt.kind == "" {
+				if len(m.Disagreements) != 0 || len(m.SharedPremises) != 1 {
+					t.Fatalf("want one shared premise, got %+v and %+v", m.Disagreements, m.SharedPremises)
+				}
+				if got := strings.Join(m.SharedPremises[0].Topic, ","); got != tt.topic {
+					t.Errorf("topic %q, want %q", got, tt.topic)
+				}
+				return
+			}
+			if len(m.Disagreements) != 1 || len(m.SharedPremises) != 0 {
+				t.Fatalf("want one disagreement, got %+v and %+v", m.Disagreements, m.SharedPremises)
+			}
+			d := m.Disagreements[0]
+			if d.Kind != tt.kind || strings.Join(d.Topic, ",") != tt.topic {
+				t.Errorf("got %s on %q, want %s on %q", d.Kind, d.Topic, tt.kind, tt.topic)
+			}
+			if d.ID != "d1" || len(d.Claims) != 2 || len(d.Questions) != 4 {
+				t.Errorf("unexpected disagreement %+v", d)
+			}
+		})
+	}
+}
+
+func TestMapConflictComparesAcrossPerspectivesOnly(t *testing.T) {
+	m := MapConflict([]string{
+		"Engineering: Ship the launch quickly. Delay the launch.",
+		"Sales: The launch needs a date.",
+	})
+	if got := strings.Join(m.Perspectives, ","); got != "Engineering,Sales" {
+		t.Fatalf("perspectives %q", got)
+	}
+	if len(m.Disagreements) != 0 {
+		t.Errorf("claims of one perspective were compared: %+v", m.Disagreements)
+	}
+	if got := strings.Join(m.CommonGround, ","); got != "launch" {
+		t.Errorf("common ground %q, want launch", got)
+	}
+
+	// A label is a few words; a longer phrase before a colon is the claim
+	m = MapConflict([]string{"The reason we keep missing dates: scope grows.", "Scope is fine."})
+	if m.Perspectives[0] != "Perspective 1" {
+		t.Errorf("a long phrase was taken as a label: %q", m.Perspectives[0])
+	}
+}
+
+func TestMapConflictAssumptions(t *testing.T) {
+	m := MapConflict([]string{
+		"Obviously everyone must use it because it will work.",
+		"We have no alternative, so we should wait.",
+	})
+	cues := []string{}
+	for _, a := range m.Assumptions {
+		cues = append(cues, a.Perspective+":"+a.Cue)
+		if a.Assumption == "" || a.Question == "" {
+			t.Errorf("assumption without an explanation: %+v", a)
+		}
+	}
+	want := "Perspective 1:obviously,Perspective 1:everyone,Perspective 1:must,Perspective 1:because,Perspective 1:will," +
+		"Perspective 2:no alternative,Perspective 2:should"
+	if got := strings.Join(cues, ","); got != want {
+		t.Errorf("cues\n%s\nwant\n%s", got, want)
+	}
+}
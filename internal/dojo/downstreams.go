@@ -0,0 +1,94 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/downstream"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// WithDownstreams sets other MCP servers whose tools are offered under
+// their names, such as librarian.search, and proxied to them
+func WithDownstreams(servers []*downstream.Server) Option {
+	return func(h *Handler) {
+		h.downstreams = servers
+	}
+}
+
+// registerDownstreams connects to every downstream at once and re-exports
+// their allowed tools under their namespaces. A downstream that cannot be
+// reached within its timeout is logged and left out, so it never keeps the
+// server from starting.
+func (h *Handler) registerDownstreams(s *server.MCPServer) {
+	errs := make([]error, len(h.downstreams))
+	var wg sync.WaitGroup
+	for i, ds := range h.downstreams {
+		wg.Add(1)
+		go func(i int, ds *downstream.Server) {
+			defer wg.Done()
+			errs[i] = ds.Connect(context.Background())
+		}(i, ds)
+	}
+	wg.Wait()
+
+	for i, ds := range h.downstreams {
+		if errs[i] != nil {
+			log.Printf("Downstream %s is unavailable, its tools are not offered: %v", ds.Name(), errs[i])
+			continue
+		}
+		for _, tool := range ds.Tools() {
+			name := tool.Name
+			tool.Name = ds.Name() + "." + name
+			tool.Description = fmt.Sprintf("[%s] %s", ds.Name(), tool.Description)
+			h.addTool(s, tool, h.guard(h.proxy(ds, name)))
+		}
+	}
+}
+
+// proxy returns a handler that forwards a call to a downstream tool. When
+// the downstream fails or times out the call returns an error result and
+// the downstream reconnects on its next call.
+func (h *Handler) proxy(ds *downstream.Server, tool string) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := ds.Call(ctx, tool, request.Params.Arguments)
+		if err != nil {
+			log.Printf("Downstream %s failed on %s: %v", ds.Name(), tool, err)
+			return mcp.NewToolResultError(fmt.Sprintf("The %s server could not complete %s: %v. Dojo's own tools are unaffected, and the next call will try to reconnect.", ds.Name(), tool, err)), nil
+		}
+		return result, nil
+	}
+}
+
+// CloseDownstreams ends the connections to every downstream, stopping the
+// servers spawned for them
+func (h *Handler) CloseDownstreams() {
+	for _, ds := range h.downstreams {
+		ds.Close()
+	}
+}
+
+func (h *Handler) handleDownstreamsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	statuses := []downstream.Status{}
+	for _, ds := range h.downstreams {
+		statuses = append(statuses, ds.Status())
+	}
+	statusJSON, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+			},
+			Text: string(statusJSON),
+		},
+	}, nil
+}
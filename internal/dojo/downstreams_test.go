@@ -0,0 +1,70 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/downstream"
+	"github.com/TresPies-source/dojo-mcp-server/internal/downstream/downstreamtest"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// handleMessage sends one JSON-RPC request to a server and returns its
+// response as JSON
+func handleMessage(t *testing.T, s *server.MCPServer, method string, params interface{}) string {
+	t.Helper()
+	message, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := json.Marshal(s.HandleMessage(context.Background(), message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(response)
+}
+
+func TestDownstreamToolsAreNamespaced(t *testing.T) {
+	stub := server.NewMCPServer("librarian", "1.0.0")
+	schema := mcp.ToolInputSchema{Type: "object", Properties: map[string]interface{}{}}
+	stub.AddTool(mcp.Tool{Name: "search", Description: "Searches the stacks", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(fmt.Sprintf("shelf for %v", request.Params.Arguments["query"])), nil
+	})
+	stub.AddTool(mcp.Tool{Name: "burn", Description: "Not for guests", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("burned"), nil
+	})
+	ts := downstreamtest.NewSSEServer(stub)
+	defer ts.Close()
+
+	ds, err := downstream.New(downstream.Config{Name: "librarian", URL: ts.URL + "/sse", Allow: []string{"search"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithDownstreams([]*downstream.Server{ds}))
+	defer h.CloseDownstreams()
+	s := server.NewMCPServer("dojo", "1.0.0")
+	h.RegisterTools(s)
+
+	listed := handleMessage(t, s, "tools/list", map[string]interface{}{})
+	if !strings.Contains(listed, `"librarian.search"`) || !strings.Contains(listed, "[librarian] Searches the stacks") {
+		t.Fatalf("librarian.search is not offered: %s", listed)
+	}
+	if strings.Contains(listed, "burn") {
+		t.Fatalf("a tool outside the allow-list is offered: %s", listed)
+	}
+	if !strings.Contains(listed, `"dojo.reflect"`) {
+		t.Fatal("Dojo's own tools are missing next to the downstream's")
+	}
+
+	called := handleMessage(t, s, "tools/call", map[string]interface{}{
+		"name":      "librarian.search",
+		"arguments": map[string]interface{}{"query": "zen"},
+	})
+	if !strings.Contains(called, "shelf for zen") {
+		t.Fatalf("librarian.search was not proxied: %s", called)
+	}
+}
@@ -0,0 +1,40 @@
+package dojo
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// guard wraps a tool handler with the checks every tool call passes through
+// before it reaches the handler: a resting session is sent to the onsen and
+// boundaries are held. Once the handler is done it reports the workspace
+// writes the call made and traces calls that name a project.
+func (h *Handler) guard(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		h.telemetry.record(sessionID(ctx), request.Params.Name, request.Params.Arguments)
+
+		ctx, onsen := h.rest(ctx, &request)
+		if onsen != nil {
+			return onsen, nil
+		}
+
+		if rule, matched := h.boundaries.check(request.Params.Name, request.Params.Arguments); rule != nil {
+			h.boundaries.record(BoundaryEvent{
+				Time:    time.Now().UTC(),
+				Rule:    rule.Name,
+				Tool:    request.Params.Name,
+				Matched: matched,
+			})
+			return mcp.NewToolResultText(boundaryResponse(rule)), nil
+		}
+
+		ctx, writes := withWriteLog(ctx)
+		result, err := handler(ctx, request)
+		h.reportWrites(result, writes)
+		h.traceProject(ctx, request)
+		return result, err
+	}
+}
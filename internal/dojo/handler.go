@@ -2,24 +2,87 @@ package dojo
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
 
+	"github.com/TresPies-source/dojo-mcp-server/internal/downstream"
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
 	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // Handler manages all Dojo-specific MCP capabilities
 type Handler struct {
-	wisdomBase *wisdom.Base
+	wisdomBase   *wisdom.Base
+	sampling     bool
+	boundaries   *boundaries
+	router       *router
+	telemetry    *telemetry
+	practices    *practiceEngine
+	store        *store.Store
+	projects     *projects
+	plans        *plans
+	memory       *memoryGarden
+	snapshots    *snapshots
+	calibrations *calibrations
+	workspace    *workspace.Workspace
+	downstreams  []*downstream.Server
+	onsen        *onsen
+}
+
+// Option configures optional Handler behavior
+type Option func(*Handler)
+
+// WithSampling controls whether reflections are generated by the client's
+// model via MCP sampling when the client supports it
+func WithSampling(enabled bool) Option {
+	return func(h *Handler) {
+		h.sampling = enabled
+	}
 }
 
 // NewHandler creates a new Dojo handler
-func NewHandler() *Handler {
-	return &Handler{
-		wisdomBase: wisdom.NewBase(),
+func NewHandler(opts ...Option) *Handler {
+	h := &Handler{
+		wisdomBase:   wisdom.NewBase(),
+		boundaries:   newBoundaries(DefaultBoundaryRules()),
+		router:       newRouter(DefaultRoutingRules()),
+		telemetry:    newTelemetry(),
+		practices:    newPracticeEngine(builtinPractices()),
+		store:        store.Memory(),
+		projects:     newProjects(),
+		plans:        newPlans(),
+		memory:       newMemoryGarden(),
+		snapshots:    newSnapshots(),
+		calibrations: newCalibrations(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
 	}
+
+	h.loadInsights()
+	h.loadSeedVersions()
+	h.loadProjects()
+	h.loadMemory()
+	h.loadSnapshots()
+	h.loadRoutingLog()
+	h.loadCalibrations()
+
+	return h
+}
+
+// Close saves the project traces still waiting to be written and ends the
+// connections to every downstream
+func (h *Handler) Close() {
+	h.flushTraces()
+	h.CloseDownstreams()
 }
 
 // unmarshalArgs is a helper to convert map[string]interface{} arguments to a typed struct
@@ -31,10 +94,27 @@ func unmarshalArgs(arguments map[string]interface{}, dest interface{}) error {
 	return json.Unmarshal(data, dest)
 }
 
+// newID returns a random identifier with a readable prefix
+func newID(prefix string) string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s_%s", prefix, hex.EncodeToString(b))
+}
+
+// contains reports whether a list of names includes name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 // RegisterTools registers all Dojo tools with the MCP server
 func (h *Handler) RegisterTools(s *server.MCPServer) {
 	// dojo.reflect - The core Dojo thinking partner
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.reflect",
 		Description: "The core Dojo thinking partner. Applies one of the four Dojo modes (Mirror, Scout, Gardener, Implementation) to a given situation and set of perspectives.",
 		InputSchema: mcp.ToolInputSchema{
@@ -59,10 +139,31 @@ func (h *Handler) RegisterTools(s *server.MCPServer) {
 			},
 			Required: []string{"situation", "perspectives", "mode"},
 		},
-	}, h.handleReflect)
+	}, h.guard(h.handleReflect))
+
+	// dojo.validate_reflection - Check a reflection against its mode's output contract
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.validate_reflection",
+		Description: "Checks a reflection (template or model-generated) against the output contract of its Dojo mode and the boundary that Dojo never originates perspectives. Returns any violations.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "The Dojo mode the reflection was written in",
+					"enum":        []string{"mirror", "scout", "gardener", "implementation"},
+				},
+				"reflection": map[string]interface{}{
+					"type":        "string",
+					"description": "The reflection text to validate",
+				},
+			},
+			Required: []string{"mode", "reflection"},
+		},
+	}, h.guard(h.handleValidateReflection))
 
 	// dojo.search_wisdom - Semantic search on the Dojo wisdom base
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.search_wisdom",
 		Description: "Performs a semantic search on the entire Dojo wisdom base, including all seed patches, documentation, and principles.",
 		InputSchema: mcp.ToolInputSchema{
@@ -75,10 +176,10 @@ func (h *Handler) RegisterTools(s *server.MCPServer) {
 			},
 			Required: []string{"query"},
 		},
-	}, h.handleSearchWisdom)
+	}, h.guard(h.handleSearchWisdom))
 
 	// dojo.get_seed - Retrieve a specific Dojo Seed Patch
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.get_seed",
 		Description: "Retrieves a specific Dojo Seed Patch by name.",
 		InputSchema: mcp.ToolInputSchema{
@@ -88,13 +189,41 @@ func (h *Handler) RegisterTools(s *server.MCPServer) {
 					"type":        "string",
 					"description": "The name of the seed patch (e.g., 'three_tiered_governance')",
 				},
+				"version": map[string]interface{}{
+					"type":        "string",
+					"description": "The semantic version to retrieve (default: latest)",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}, h.guard(h.handleGetSeed))
+
+	// dojo.diff_seed - Show what changed between two versions of a seed
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.diff_seed",
+		Description: "Shows a unified diff between two versions of a Dojo Seed Patch.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"name": map[string]interface{}{
+					"type":        "string",
+					"description": "The name of the seed patch",
+				},
+				"from": map[string]interface{}{
+					"type":        "string",
+					"description": "The older version (default: the version before 'to')",
+				},
+				"to": map[string]interface{}{
+					"type":        "string",
+					"description": "The newer version (default: latest)",
+				},
 			},
 			Required: []string{"name"},
 		},
-	}, h.handleGetSeed)
+	}, h.guard(h.handleDiffSeed))
 
 	// dojo.apply_seed - Apply a Dojo Seed Patch to a situation
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.apply_seed",
 		Description: "Applies a Dojo Seed Patch to a given situation, providing guidance and a checklist.",
 		InputSchema: mcp.ToolInputSchema{
@@ -108,35 +237,39 @@ func (h *Handler) RegisterTools(s *server.MCPServer) {
 					"type":        "string",
 					"description": "The situation to apply the seed patch to",
 				},
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional project to record the applied seed and its checklist in, for dojo.export_packet",
+				},
 			},
 			Required: []string{"seed_name", "situation"},
 		},
-	}, h.handleApplySeed)
+	}, h.guard(h.handleApplySeed))
 
 	// dojo.list_seeds - List all available Dojo Seed Patches
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.list_seeds",
 		Description: "Lists all available Dojo Seed Patches with their descriptions.",
 		InputSchema: mcp.ToolInputSchema{
 			Type:       "object",
 			Properties: map[string]interface{}{},
 		},
-	}, h.handleListSeeds)
+	}, h.guard(h.handleListSeeds))
 
 	// dojo.get_principles - Get the core Dojo principles
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.get_principles",
 		Description: "Retrieves the three core Dojo principles: Beginner's Mind, Self-Definition, and Understanding is Love.",
 		InputSchema: mcp.ToolInputSchema{
 			Type:       "object",
 			Properties: map[string]interface{}{},
 		},
-	}, h.handleGetPrinciples)
+	}, h.guard(h.handleGetPrinciples))
 
 	// v2.0 Tools: AROMA / Serenity Valley
 
 	// dojo.create_thinking_room - Create a structured space for focused reflection
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.create_thinking_room",
 		Description: "Creates a structured, private space for focused reflection on a given topic.",
 		InputSchema: mcp.ToolInputSchema{
@@ -150,63 +283,645 @@ func (h *Handler) RegisterTools(s *server.MCPServer) {
 					"type":        "string",
 					"description": "The name of the agent or user creating the room",
 				},
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional project to record the room in, for dojo.export_packet",
+				},
 			},
 			Required: []string{"topic", "agent_name"},
 		},
-	}, h.handleCreateThinkingRoom)
+	}, h.guard(h.handleCreateThinkingRoom))
 
 	// dojo.trace_lineage - Trace the sources and influences of an idea
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.trace_lineage",
-		Description: "Traces the sources and influences of an idea or insight, searching the wisdom base for related content.",
+		Description: "Traces the sources and influences of an idea or insight through the lineage graph of seeds and resources, returning ancestor and descendant chains with their edge types (builds on, contrasts, cites) alongside related content from the wisdom base.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"idea_or_insight": map[string]interface{}{
 					"type":        "string",
-					"description": "The idea or insight to trace",
+					"description": "The idea or insight to trace. A seed or resource name (e.g. cost_guard or resource:eit_principles) traces that node directly; other text traces the closest match.",
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format (default: markdown)",
+					"enum":        lineageFormats,
+				},
+				"depth": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many edges to follow in each direction (default: 6)",
 				},
 			},
 			Required: []string{"idea_or_insight"},
 		},
-	}, h.handleTraceLineage)
+	}, h.guard(h.handleTraceLineage))
+
+	// dojo.record_insight - Contribute an insight back to the wisdom base
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.record_insight",
+		Description: "Records an insight with its author and credited sources (seeds, resources, URLs, people) in the local wisdom base. Recorded insights are searchable with dojo.search_wisdom, appear in dojo.trace_lineage, and are readable as dojo://insights/{id} resources.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "A short title for the insight",
+				},
+				"body": map[string]interface{}{
+					"type":        "string",
+					"description": "The insight itself",
+				},
+				"author": map[string]interface{}{
+					"type":        "string",
+					"description": "Who had the insight",
+				},
+				"seeds": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Seed patches the insight builds on (e.g. 'pace_of_understanding')",
+				},
+				"resources": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Resources the insight draws on (e.g. 'eit_principles')",
+				},
+				"urls": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Links to sources outside the wisdom base",
+				},
+				"people": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "People whose thinking shaped the insight",
+				},
+				"id": map[string]interface{}{
+					"type":        "string",
+					"description": "The ID of a recorded insight to revise (optional)",
+				},
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional project to record the insight in, for dojo.export_packet",
+				},
+			},
+			Required: []string{"title", "body", "author"},
+		},
+	}, h.guard(h.handleRecordInsight))
+
+	// dojo.cite - Citations for seeds, resources, insights and their lineage
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.cite",
+		Description: "Produces citations for seeds, resources and insights, or for an idea and its whole lineage, with stable identifiers and version info, in Markdown footnote, BibTeX, CSL-JSON or plain-text format.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"sources": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Seed, resource or insight names to cite (e.g. 'cost_guard', 'resource:eit_principles')",
+				},
+				"trace": map[string]interface{}{
+					"type":        "string",
+					"description": "An idea, seed or resource to cite along with every ancestor in its lineage",
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"description": "Citation format (default: markdown)",
+					"enum":        citationFormats,
+				},
+			},
+		},
+	}, h.guard(h.handleCite))
+
+	// dojo.export_packet - Bundle a project into a DojoPacket
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.export_packet",
+		Description: "Exports a project's thinking rooms, applied seeds and their checklists, recorded insights and session traces as a DojoPacket v1.0, validated against the packet JSON Schema. The JSON format returns the packet and saves it under the data directory; the zip format saves packet.json with a Markdown file for each artifact.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project to export, as named when rooms, seeds or insights were recorded for it",
+				},
+				"description": map[string]interface{}{
+					"type":        "string",
+					"description": "A description of the project to store and include in the packet (optional)",
+				},
+				"format": map[string]interface{}{
+					"type":        "string",
+					"enum":        packetFormats,
+					"description": "json (default) or zip",
+				},
+			},
+			Required: []string{"project"},
+		},
+	}, h.guard(h.handleExportPacket))
+
+	// dojo.import_packet - Restore a project from a DojoPacket
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.import_packet",
+		Description: "Imports a DojoPacket v1.0 (JSON or zip, as written by dojo.export_packet), validating it against the packet schema and restoring its thinking rooms, insights, applied seeds and session traces into the local store. Items identical to local ones are left alone; conflicting items are skipped, overwritten or kept under a new name. Use dry_run to see the report without changing anything.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path of a packet file (.json or .zip) in the workspace",
+				},
+				"packet": map[string]interface{}{
+					"type":        "string",
+					"description": "The packet JSON itself, instead of a path",
+				},
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "Restore into this project instead of the one the packet names (optional)",
+				},
+				"on_conflict": map[string]interface{}{
+					"type":        "string",
+					"enum":        conflictPolicies,
+					"description": "What to do with items that exist locally with different content: skip (default), overwrite, or rename to keep both",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Report what would be restored without changing anything",
+				},
+			},
+		},
+	}, h.guard(h.handleImportPacket))
+
+	// dojo.plan_init - Start the planning-with-files pattern for a project
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.plan_init",
+		Description: "Starts the planning-with-files pattern for a project: creates task_plan.md (goal, phases, decisions), findings.md and progress.md in the project's directory of the workspace. The first phase starts in progress.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the plan belongs to",
+				},
+				"goal": map[string]interface{}{
+					"type":        "string",
+					"description": "The overall objective, kept in view to prevent goal drift",
+				},
+				"phases": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "The phases of the work, in order",
+				},
+				"overwrite": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Replace an existing plan and its findings and progress",
+				},
+			},
+			Required: []string{"project", "goal", "phases"},
+		},
+	}, h.guard(h.handlePlanInit))
+
+	// dojo.plan_update_phase - Change the status of a plan phase
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.plan_update_phase",
+		Description: "Sets the status of a phase in task_plan.md, optionally with a note and a decision to record, and logs the change in progress.md.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the plan belongs to",
+				},
+				"phase": map[string]interface{}{
+					"type":        "string",
+					"description": "The phase name, or its number counting from 1",
+				},
+				"status": map[string]interface{}{
+					"type":        "string",
+					"enum":        phaseStatusNames,
+					"description": "The new status of the phase",
+				},
+				"note": map[string]interface{}{
+					"type":        "string",
+					"description": "A short note shown next to the phase, such as what it is blocked on",
+				},
+				"decision": map[string]interface{}{
+					"type":        "string",
+					"description": "A decision made in this phase, added to the Decisions section",
+				},
+				"add": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Add the phase to the end of the plan if it is not there yet",
+				},
+			},
+			Required: []string{"project", "phase", "status"},
+		},
+	}, h.guard(h.handlePlanUpdatePhase))
+
+	// dojo.add_finding - Record research in findings.md
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.add_finding",
+		Description: "Adds a finding to findings.md under a category section, so research survives context resets.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the plan belongs to",
+				},
+				"title": map[string]interface{}{
+					"type":        "string",
+					"description": "A short title for the finding",
+				},
+				"finding": map[string]interface{}{
+					"type":        "string",
+					"description": "What was found",
+				},
+				"category": map[string]interface{}{
+					"type":        "string",
+					"description": "The section to file the finding under (default General)",
+				},
+				"source": map[string]interface{}{
+					"type":        "string",
+					"description": "Where the finding came from, such as a URL or file (optional)",
+				},
+			},
+			Required: []string{"project", "title", "finding"},
+		},
+	}, h.guard(h.handleAddFinding))
+
+	// dojo.log_progress - Log an action, result or error in progress.md
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.log_progress",
+		Description: "Appends an action, result or error to the progress.md log, attributed to the current phase unless another is named, so failed attempts are not repeated.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the plan belongs to",
+				},
+				"entry": map[string]interface{}{
+					"type":        "string",
+					"description": "What was done, what came of it, or what went wrong",
+				},
+				"kind": map[string]interface{}{
+					"type":        "string",
+					"enum":        progressKinds,
+					"description": "action (default), result or error",
+				},
+				"phase": map[string]interface{}{
+					"type":        "string",
+					"description": "The phase the entry belongs to (defaults to the current phase)",
+				},
+			},
+			Required: []string{"project", "entry"},
+		},
+	}, h.guard(h.handleLogProgress))
+
+	// dojo.plan_recover - Rebuild session state from the planning files
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.plan_recover",
+		Description: "Reconstructs a compact summary of where work stands after a context reset: the goal, phase statuses and current phase, recent decisions, findings by category, recent progress and errors to avoid repeating.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the plan belongs to",
+				},
+				"recent": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many recent progress entries to include (default 5)",
+				},
+			},
+			Required: []string{"project"},
+		},
+	}, h.guard(h.handlePlanRecover))
+
+	// dojo.remember - Keep something in a project's memory garden
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.remember",
+		Description: "Plants a memory in a project's memory garden, shared by every agent working on the project. Tags make memories easy to recall or forget together, and a TTL lets short-lived context fade on its own.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the memory belongs to",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "What to remember",
+				},
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Tags to file the memory under (optional)",
+				},
+				"author": map[string]interface{}{
+					"type":        "string",
+					"description": "Who is remembering this, such as an agent name (defaults to the session)",
+				},
+				"ttl": map[string]interface{}{
+					"type":        "string",
+					"description": "How long to keep the memory, such as 90m, 12h or 7d (default forever)",
+				},
+			},
+			Required: []string{"project", "content"},
+		},
+	}, h.guard(h.handleRemember))
+
+	// dojo.recall - Retrieve a project's memories ranked by relevance
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.recall",
+		Description: "Recalls a project's memories ranked by how well they match a query, favoring recent ones. Without a query the most recent memories come first. Tags narrow the recall to memories carrying all of them.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project to recall from",
+				},
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "What to look for (optional)",
+				},
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Only recall memories carrying all of these tags (optional)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many memories to return (default 5)",
+				},
+			},
+			Required: []string{"project"},
+		},
+	}, h.guard(h.handleRecall))
+
+	// dojo.forget - Remove memories from a project's memory garden
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.forget",
+		Description: "Removes memories from a project's memory garden by ID, or every memory carrying all of the given tags, and returns what was forgotten.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project to forget in",
+				},
+				"ids": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "IDs of the memories to forget",
+				},
+				"tags": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Forget every memory carrying all of these tags",
+				},
+			},
+			Required: []string{"project"},
+		},
+	}, h.guard(h.handleForget))
+
+	// dojo.compress_memory - Fold old memories and rooms into a digest
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.compress_memory",
+		Description: "Compresses a project's memories older than a given age into one digest memory, made of the sentences that best represent them (extractive, no model needed). The originals are kept in a snapshot taken first, so dojo.restore_snapshot can bring them back. Use dry_run to preview the digest.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project to compress",
+				},
+				"older_than": map[string]interface{}{
+					"type":        "string",
+					"description": "Compress what is older than this, such as 12h or 30d (default 7d)",
+				},
+				"sentences": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many sentences the digest may keep (default 5)",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Show the digest without changing anything",
+				},
+			},
+			Required: []string{"project"},
+		},
+	}, h.guard(h.handleCompressMemory))
+
+	// dojo.snapshot - Keep an immutable copy of a project's memory
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.snapshot",
+		Description: "Takes an immutable snapshot of a project's memory garden and journal (thinking rooms, seeds, insights and traces) to roll back to later, and lists the project's snapshots.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project to snapshot",
+				},
+				"label": map[string]interface{}{
+					"type":        "string",
+					"description": "A note on why the snapshot was taken (optional)",
+				},
+			},
+			Required: []string{"project"},
+		},
+	}, h.guard(h.handleSnapshot))
+
+	// dojo.restore_snapshot - Roll a project's memory back to a snapshot
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.restore_snapshot",
+		Description: "Rolls a project's memory garden and journal back to a snapshot. The current state is snapshotted first, so a restore can be undone.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project to restore",
+				},
+				"snapshot": map[string]interface{}{
+					"type":        "string",
+					"description": "The ID of the snapshot to restore",
+				},
+			},
+			Required: []string{"project", "snapshot"},
+		},
+	}, h.guard(h.handleRestoreSnapshot))
+
+	// dojo.route - Supervisor routing to the Dojo, Librarian, Debugger or Builder
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.route",
+		Description: "The supervisor of the agent_connect pattern. Classifies a query into an agent role (by default Dojo, Librarian, Debugger or Builder) by keyword and intent scoring, and returns the role, a confidence and the Dojo tools that role should use. Every decision is logged in dojo://routing_log.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "The request to route",
+				},
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "The project the request belongs to, noted in the routing log (optional)",
+				},
+			},
+			Required: []string{"query"},
+		},
+	}, h.guard(h.handleRoute))
 
-	// dojo.practice_inter_acceptance - Guided Inter-Acceptance exercise
-	s.AddTool(mcp.Tool{
-		Name:        "dojo.practice_inter_acceptance",
-		Description: "Guides through an Inter-Acceptance exercise from Serenity Valley's Emotional Interbeing Therapy.",
+	// dojo.resolve_conflict - Map where perspectives disagree, for the Debugger role
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.resolve_conflict",
+		Description: "Maps a conflict between two or more perspectives with offline heuristics: the claims that contradict each other, the premises they share and the assumptions they leave unstated, with questions that could resolve each disagreement. It does not choose a winner.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
 				"situation": map[string]interface{}{
 					"type":        "string",
-					"description": "The situation to practice inter-acceptance with",
+					"description": "What the perspectives are about (optional)",
+				},
+				"perspectives": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Two or more perspectives, each optionally starting with a short label and a colon, such as \"Design: we should wait for the research\"",
+				},
+			},
+			Required: []string{"perspectives"},
+		},
+	}, h.guard(h.handleResolveConflict))
+
+	// dojo.calibrate - Post to a shared calibration session between agents
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.calibrate",
+		Description: "Posts an agent's perspective and confidence on a shared question to a calibration session, following the collaborative_calibration seed. Several agents post to the same session, round after round, and the server tracks how their views agree and diverge. The first post names the question.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"session": map[string]interface{}{
+					"type":        "string",
+					"description": "The calibration session's name or ID; posting to a new name starts it",
+				},
+				"question": map[string]interface{}{
+					"type":        "string",
+					"description": "The question the agents are calibrating on (required when starting a session)",
+				},
+				"agent_name": map[string]interface{}{
+					"type":        "string",
+					"description": "The posting agent's name",
+				},
+				"perspective": map[string]interface{}{
+					"type":        "string",
+					"description": "The agent's perspective on the question",
+				},
+				"confidence": map[string]interface{}{
+					"type":        "number",
+					"description": "The agent's confidence in its perspective, from 0 to 1",
+				},
+				"round": map[string]interface{}{
+					"type":        "integer",
+					"description": "The round to post to: the current one by default, or the next one to open it",
 				},
 			},
-			Required: []string{"situation"},
+			Required: []string{"session", "agent_name", "perspective", "confidence"},
 		},
-	}, h.handlePracticeInterAcceptance)
+	}, h.guard(h.handleCalibrate))
+
+	// dojo.calibration_summary - How a calibration session's views are moving
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.calibration_summary",
+		Description: "Summarizes a calibration session: agreement and confidence spread per round, whether the agents are converging or diverging, the outliers of the latest round, which agents changed their view, and the disagreements still open.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"session": map[string]interface{}{
+					"type":        "string",
+					"description": "The calibration session's name or ID",
+				},
+			},
+			Required: []string{"session"},
+		},
+	}, h.guard(h.handleCalibrationSummary))
+
+	// Practice worksheets - one tool per practice definition, such as
+	// dojo.practice_inter_acceptance and dojo.explore_radical_freedom
+	for _, p := range h.practices.list() {
+		description := p.ToolDescription
+		if description == "" {
+			description = p.Description
+		}
+		h.addTool(s, mcp.Tool{
+			Name:        p.toolName(),
+			Description: description,
+			InputSchema: p.toolSchema(),
+		}, h.guard(h.practiceToolHandler(p)))
+	}
 
-	// dojo.explore_radical_freedom - Explore agency within constraints
-	s.AddTool(mcp.Tool{
-		Name:        "dojo.explore_radical_freedom",
-		Description: "Helps explore agency and freedom within constraints, based on Serenity Valley's Radical Freedom principle.",
+	// dojo.practice_start - Begin a guided practice one step at a time
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.practice_start",
+		Description: "Starts a guided practice (such as inter_acceptance or radical_freedom) and returns its first step. Answer each step with dojo.practice_step, then close with dojo.practice_finish.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
+				"practice": map[string]interface{}{
+					"type":        "string",
+					"description": "The practice to start",
+					"enum":        h.practices.names(),
+				},
 				"situation": map[string]interface{}{
 					"type":        "string",
-					"description": "The constrained situation to explore",
+					"description": "The situation or topic the practice is about",
+				},
+			},
+			Required: []string{"practice", "situation"},
+		},
+	}, h.guard(h.handlePracticeStart))
+
+	// dojo.practice_step - Answer the current step of a guided practice
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.practice_step",
+		Description: "Submits an answer to the current step of a guided practice and returns the next step.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"practice_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The practice ID returned by dojo.practice_start",
+				},
+				"answer": map[string]interface{}{
+					"type":        "string",
+					"description": "Your answer to the current step",
 				},
 			},
-			Required: []string{"situation"},
+			Required: []string{"practice_id", "answer"},
 		},
-	}, h.handleExploreRadicalFreedom)
+	}, h.guard(h.handlePracticeStep))
+
+	// dojo.practice_finish - Close a guided practice with a summary of the answers
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.practice_finish",
+		Description: "Closes a guided practice and returns a closing summary built from your own answers.",
+		InputSchema: mcp.ToolInputSchema{
+			Type: "object",
+			Properties: map[string]interface{}{
+				"practice_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The practice ID returned by dojo.practice_start",
+				},
+			},
+			Required: []string{"practice_id"},
+		},
+	}, h.guard(h.handlePracticeFinish))
 
 	// dojo.check_pace - Assess pace of understanding vs extraction
-	s.AddTool(mcp.Tool{
+	h.addTool(s, mcp.Tool{
 		Name:        "dojo.check_pace",
-		Description: "Assesses whether the current session pace is one of understanding or extraction, with recommendations.",
+		Description: "Assesses whether the current session pace is one of understanding or extraction, reading call cadence, time between calls, returns to thinking rooms and reflection length from session telemetry, with self-assessment questions and recommendations.",
 		InputSchema: mcp.ToolInputSchema{
 			Type: "object",
 			Properties: map[string]interface{}{
@@ -214,10 +929,48 @@ func (h *Handler) RegisterTools(s *server.MCPServer) {
 					"type":        "string",
 					"description": "A description of the current session or work being done",
 				},
+				"history": map[string]interface{}{
+					"type":        "integer",
+					"description": "How many recent self-assessments to show in the trend (default 5)",
+					"minimum":     1,
+				},
+				"project": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional project whose self-assessments to show in the trend",
+				},
 			},
 			Required: []string{"session_description"},
 		},
-	}, h.handleCheckPace)
+	}, h.guard(h.handleCheckPace))
+
+	// dojo.submit_pace_assessment - Score answers to the pace self-assessment
+	paceProperties := map[string]interface{}{}
+	paceRequired := []string{}
+	for _, dimension := range paceDimensions {
+		paceProperties[dimension.Name] = map[string]interface{}{
+			"type":        "string",
+			"description": paceOptionsDescription(dimension),
+			"enum":        dimension.Options[:],
+		}
+		paceRequired = append(paceRequired, dimension.Name)
+	}
+	paceProperties["project"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Optional project to keep the self-assessment with, so dojo.check_pace shows its trend across sessions",
+	}
+	h.addTool(s, mcp.Tool{
+		Name:        "dojo.submit_pace_assessment",
+		Description: "Submits answers to the four dojo.check_pace self-assessment questions (energy, engagement, emotional state, integration), computes the interpretation, and stores it with a timestamp so dojo.check_pace can show the trend.",
+		InputSchema: mcp.ToolInputSchema{
+			Type:       "object",
+			Properties: paceProperties,
+			Required:   paceRequired,
+		},
+	}, h.guard(h.handleSubmitPaceAssessment))
+
+	// Tools of downstream MCP servers, re-exported under their names, such as
+	// librarian.search
+	h.registerDownstreams(s)
 }
 
 // Tool handlers
@@ -233,7 +986,7 @@ func (h *Handler) handleReflect(ctx context.Context, request mcp.CallToolRequest
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
 	}
 
-	reflection := h.reflect(args.Situation, args.Perspectives, args.Mode)
+	reflection := h.reflect(ctx, args.Situation, args.Perspectives, args.Mode)
 
 	return mcp.NewToolResultText(reflection), nil
 }
@@ -255,14 +1008,15 @@ func (h *Handler) handleSearchWisdom(ctx context.Context, request mcp.CallToolRe
 
 func (h *Handler) handleGetSeed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var args struct {
-		Name string `json:"name"`
+		Name    string `json:"name"`
+		Version string `json:"version"`
 	}
 
 	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
 	}
 
-	seed, err := h.wisdomBase.GetSeed(args.Name)
+	seed, err := h.wisdomBase.GetSeedVersion(args.Name, args.Version)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("Seed not found: %v", err)), nil
 	}
@@ -275,6 +1029,7 @@ func (h *Handler) handleApplySeed(ctx context.Context, request mcp.CallToolReque
 	var args struct {
 		SeedName  string `json:"seed_name"`
 		Situation string `json:"situation"`
+		Project   string `json:"project"`
 	}
 
 	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
@@ -283,6 +1038,15 @@ func (h *Handler) handleApplySeed(ctx context.Context, request mcp.CallToolReque
 
 	guidance := h.applySeed(args.SeedName, args.Situation)
 
+	if seed, err := h.wisdomBase.GetSeed(args.SeedName); err == nil && strings.TrimSpace(args.Project) != "" {
+		if err := h.updateProjects(ctx, func() error {
+			h.projects.recordSeed(args.Project, *seed, args.Situation)
+			return nil
+		}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("The seed was applied but could not be saved to project %s: %v", args.Project, err)), nil
+		}
+	}
+
 	return mcp.NewToolResultText(guidance), nil
 }
 
@@ -308,28 +1072,110 @@ func (h *Handler) RegisterPrompts(s *server.MCPServer) {
 		s.AddPrompt(mcp.Prompt{
 			Name:        fmt.Sprintf("dojo.seed.%s", seedCopy.Name),
 			Description: seedCopy.Description,
+			Arguments: []mcp.PromptArgument{
+				{
+					Name:        "situation",
+					Description: "The situation you want to apply this seed to",
+				},
+				{
+					Name:        "project",
+					Description: "The project this work belongs to",
+				},
+				{
+					Name:        "focus_section",
+					Description: fmt.Sprintf("Only include this section of the seed (e.g. %q)", seedSectionExample(seedCopy)),
+				},
+			},
 		}, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
-			fullSeed, _ := h.wisdomBase.GetSeed(seedCopy.Name)
-
-			return &mcp.GetPromptResult{
-				Messages: []mcp.PromptMessage{
-					{
-						Role: "user",
-						Content: mcp.TextContent{
-							Type: "text",
-							Text: fullSeed.Content,
-						},
-					},
+			fullSeed, err := h.wisdomBase.GetSeed(seedCopy.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			return h.seedPrompt(fullSeed, request.Params.Arguments)
+		})
+	}
+
+	for _, mode := range modes {
+		modeCopy := mode // Capture for closure
+		s.AddPrompt(mcp.Prompt{
+			Name:        fmt.Sprintf("dojo.mode.%s", modeCopy),
+			Description: h.modePromptDescription(modeCopy),
+			Arguments: []mcp.PromptArgument{
+				{
+					Name:        "situation",
+					Description: "The situation or question to reflect on",
+					Required:    true,
 				},
-			}, nil
+				{
+					Name:        "perspectives",
+					Description: "The perspectives to consider, one per line or separated by semicolons",
+				},
+			},
+		}, func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return h.modePrompt(modeCopy, request.Params.Arguments)
 		})
 	}
+
+	for _, p := range h.practices.list() {
+		s.AddPrompt(mcp.Prompt{
+			Name:        fmt.Sprintf("dojo.practice.%s", p.Name),
+			Description: p.Description,
+			Arguments:   p.promptArguments(),
+		}, h.practicePromptHandler(p))
+	}
 }
 
 // RegisterResources registers all Dojo resources with the MCP server
 func (h *Handler) RegisterResources(s *server.MCPServer) {
 	resources := h.wisdomBase.ListResources()
 
+	// dojo://boundary_log - Boundary rules and every time one was triggered
+	s.AddResource(mcp.Resource{
+		URI:         "dojo://boundary_log",
+		Name:        "boundary_log",
+		Description: "The compassionate boundary rules in force and a log of every triggered boundary",
+		MIMEType:    "application/json",
+	}, h.handleBoundaryLog)
+
+	// dojo://routing_log - Routing rules and every decision made by dojo.route
+	s.AddResource(mcp.Resource{
+		URI:         "dojo://routing_log",
+		Name:        "routing_log",
+		Description: "The routing rules in force and an audit log of every dojo.route decision",
+		MIMEType:    "application/json",
+	}, h.handleRoutingLog)
+
+	// dojo://downstreams - Connection status of proxied MCP servers
+	s.AddResource(mcp.Resource{
+		URI:         "dojo://downstreams",
+		Name:        "downstreams",
+		Description: "The downstream MCP servers whose tools are proxied, whether each is connected, the tools it offers and its last error",
+		MIMEType:    "application/json",
+	}, h.handleDownstreamsResource)
+
+	// dojo://onsen_log - Rest mode policy and the calls sent to rest
+	s.AddResource(mcp.Resource{
+		URI:         "dojo://onsen_log",
+		Name:        "onsen_log",
+		Description: "Whether onsen rest mode is on, its thresholds, the sessions resting now, recent calls sent to rest or let through by override, and every stored override",
+		MIMEType:    "application/json",
+	}, h.handleOnsenLog)
+
+	// dojo://insights - Every recorded insight, and one resource per insight
+	s.AddResource(mcp.Resource{
+		URI:         "dojo://insights",
+		Name:        "insights",
+		Description: "Every insight recorded with dojo.record_insight, with its credits",
+		MIMEType:    "application/json",
+	}, h.handleInsightsResource)
+	s.AddResourceTemplate(mcp.ResourceTemplate{
+		URITemplate: "dojo://insights/{id}",
+		Name:        "insight",
+		Description: "A recorded insight and its attribution",
+		MIMEType:    "text/markdown",
+	}, h.handleInsightResource)
+
 	for _, resource := range resources {
 		resourceCopy := resource // Capture for closure
 		s.AddResource(mcp.Resource{
@@ -356,8 +1202,20 @@ func (h *Handler) RegisterResources(s *server.MCPServer) {
 	}
 }
 
-// reflect implements the core Dojo reflection logic
-func (h *Handler) reflect(situation string, perspectives []string, mode string) string {
+// reflect implements the core Dojo reflection logic. When sampling is enabled
+// and the client supports it, the client's model writes the reflection;
+// otherwise the mode templates are used.
+func (h *Handler) reflect(ctx context.Context, situation string, perspectives []string, mode string) string {
+	if h.sampling && isMode(mode) {
+		if sampler := samplerFromContext(ctx); sampler != nil {
+			reflection, err := h.sampleReflection(ctx, sampler, situation, perspectives, mode)
+			if err == nil {
+				return reflection
+			}
+			log.Printf("Sampling failed, falling back to %s template: %v", mode, err)
+		}
+	}
+
 	switch mode {
 	case "mirror":
 		return h.mirrorMode(situation, perspectives)
@@ -380,7 +1238,9 @@ Situation: %s
 Perspectives provided: %d
 
 **Pattern across perspectives:**
-The perspectives reveal a multi-faceted view of the situation. Each perspective brings a unique lens, and together they form a more complete picture than any single view could provide.
+- The perspectives reveal a multi-faceted view of the situation.
+- Each perspective brings a unique lens to the same question.
+- Together they form a more complete picture than any single view could provide.
 
 **Assumptions/tensions identified:**
 1. There may be an implicit assumption that one perspective is "correct" while others are less valid.
@@ -462,7 +1322,7 @@ func (h *Handler) applySeed(seedName, situation string) string {
 		return fmt.Sprintf("Seed '%s' not found.", seedName)
 	}
 
-	return fmt.Sprintf(`**Applying Seed: %s**
+	return fmt.Sprintf(`**Applying Seed: %s** (version %s, %s)
 
 **Situation:** %s
 
@@ -476,5 +1336,5 @@ Review the seed content above and consider how each principle or pattern applies
 1. Which aspects of this seed are most relevant to your situation?
 2. What would successful application of this seed look like?
 3. What obstacles might prevent full application?
-4. What's the smallest step you could take to begin applying this seed?`, seed.Name, situation, seed.Content)
+4. What's the smallest step you could take to begin applying this seed?`, seed.Name, seed.Version, seed.Hash, situation, seed.Content)
 }
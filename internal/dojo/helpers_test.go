@@ -0,0 +1,49 @@
+package dojo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestHandler returns a handler with an in-memory store and a workspace
+// in a temporary directory
+func newTestHandler(t *testing.T, opts ...Option) *Handler {
+	t.Helper()
+	ws, err := workspace.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHandler(append([]Option{WithWorkspace(ws)}, opts...)...)
+	t.Cleanup(h.Close)
+	return h
+}
+
+// callTool invokes a tool handler with arguments and returns its text and
+// whether it is an error result
+func callTool(t *testing.T, handler server.ToolHandlerFunc, name string, arguments map[string]interface{}) (string, bool) {
+	t.Helper()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("%s returned an error: %v", name, err)
+	}
+	return resultText(result), result.IsError
+}
+
+// resultText joins the text contents of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	texts := []string{}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
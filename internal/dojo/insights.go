@@ -0,0 +1,228 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// insightsDocument is the store document holding recorded insights
+const insightsDocument = "insights"
+
+// WithStore sets where recorded data such as insights is persisted
+func WithStore(st *store.Store) Option {
+	return func(h *Handler) {
+		h.store = st
+	}
+}
+
+// loadInsights restores recorded insights into the wisdom base
+func (h *Handler) loadInsights() {
+	var insights []wisdom.Insight
+	if _, err := h.store.Load(insightsDocument, &insights); err != nil {
+		log.Printf("Could not load insights: %v", err)
+		return
+	}
+	for _, insight := range insights {
+		if err := h.wisdomBase.AddInsight(insight); err != nil {
+			log.Printf("Skipping stored insight %s: %v", insight.ID, err)
+		}
+	}
+}
+
+// updateInsights rereads the recorded insights into the wisdom base,
+// applies change to it and saves every insight, holding the store's lock
+// throughout so insights recorded by other processes sharing the data
+// directory are kept
+func (h *Handler) updateInsights(ctx context.Context, change func() error) error {
+	return h.updateInsightsDocument(ctx, func(stored []wisdom.Insight) ([]wisdom.Insight, error) {
+		for _, insight := range stored {
+			if err := h.wisdomBase.AddInsight(insight); err != nil {
+				log.Printf("Skipping stored insight %s: %v", insight.ID, err)
+			}
+		}
+		if err := change(); err != nil {
+			return nil, err
+		}
+		return h.wisdomBase.ListInsights(), nil
+	})
+}
+
+// updateInsightsDocument rereads the stored insights and saves what change
+// makes of them, under the store's lock, without touching the wisdom base
+func (h *Handler) updateInsightsDocument(ctx context.Context, change func([]wisdom.Insight) ([]wisdom.Insight, error)) error {
+	var insights []wisdom.Insight
+	write, err := h.store.Update(insightsDocument, &insights, func() error {
+		changed, err := change(insights)
+		if err != nil {
+			return err
+		}
+		insights = changed
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+func (h *Handler) handleRecordInsight(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		ID        string   `json:"id"`
+		Title     string   `json:"title"`
+		Body      string   `json:"body"`
+		Author    string   `json:"author"`
+		Seeds     []string `json:"seeds"`
+		Resources []string `json:"resources"`
+		URLs      []string `json:"urls"`
+		People    []string `json:"people"`
+		Project   string   `json:"project"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	now := time.Now().UTC()
+	insight := wisdom.Insight{
+		ID:        args.ID,
+		Title:     strings.TrimSpace(args.Title),
+		Body:      strings.TrimSpace(args.Body),
+		Author:    strings.TrimSpace(args.Author),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	updating := insight.ID != ""
+	if !updating {
+		insight.ID = newID("insight")
+	}
+
+	credits := []struct {
+		kind string
+		refs []string
+	}{
+		{wisdom.CreditSeed, args.Seeds},
+		{wisdom.CreditResource, args.Resources},
+		{wisdom.CreditURL, args.URLs},
+		{wisdom.CreditPerson, args.People},
+	}
+	for _, group := range credits {
+		for _, ref := range group.refs {
+			insight.Credits = append(insight.Credits, wisdom.Credit{Type: group.kind, Ref: strings.TrimSpace(ref)})
+		}
+	}
+
+	if err := h.wisdomBase.CheckInsight(insight); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	var missing error
+	if err := h.updateInsights(ctx, func() error {
+		// Looked up after rereading, since another process may have
+		// recorded the insight being updated
+		if updating {
+			existing, err := h.wisdomBase.GetInsight(insight.ID)
+			if err != nil {
+				missing = err
+				return err
+			}
+			insight.CreatedAt = existing.CreatedAt
+		}
+		return h.wisdomBase.AddInsight(insight)
+	}); err != nil {
+		if missing != nil {
+			return mcp.NewToolResultError(missing.Error()), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Could not save the insight: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Project) != "" {
+		if err := h.updateProjects(ctx, func() error {
+			h.projects.recordInsight(args.Project, insight.ID)
+			return nil
+		}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("The insight is recorded but could not be saved to project %s: %v", args.Project, err)), nil
+		}
+	}
+
+	result := map[string]interface{}{
+		"insight":  insight,
+		"resource": insightURI(insight.ID),
+		"message":  "Thank you for contributing this insight. It is now part of the wisdom base, searchable with dojo.search_wisdom and traceable with dojo.trace_lineage.",
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// insightURI returns the resource URI of an insight
+func insightURI(id string) string {
+	return "dojo://insights/" + id
+}
+
+// renderInsight formats an insight as Markdown with its attribution
+func renderInsight(insight wisdom.Insight) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# %s\n\n**Author:** %s\n**Recorded:** %s\n", insight.Title, insight.Author, insight.CreatedAt.Format(time.RFC3339)))
+	if !insight.UpdatedAt.Equal(insight.CreatedAt) {
+		text.WriteString(fmt.Sprintf("**Updated:** %s\n", insight.UpdatedAt.Format(time.RFC3339)))
+	}
+	text.WriteString(fmt.Sprintf("\n%s\n\n## Credits\n\n", insight.Body))
+	if len(insight.Credits) == 0 {
+		text.WriteString("No sources credited.\n")
+	}
+	for _, credit := range insight.Credits {
+		switch credit.Type {
+		case wisdom.CreditSeed:
+			text.WriteString(fmt.Sprintf("- Seed: `%s`\n", credit.Ref))
+		case wisdom.CreditResource:
+			text.WriteString(fmt.Sprintf("- Resource: dojo://%s\n", credit.Ref))
+		case wisdom.CreditURL:
+			text.WriteString(fmt.Sprintf("- Link: %s\n", credit.Ref))
+		case wisdom.CreditPerson:
+			text.WriteString(fmt.Sprintf("- Person: %s\n", credit.Ref))
+		}
+	}
+	return text.String()
+}
+
+func (h *Handler) handleInsightsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	insightsJSON, err := json.MarshalIndent(h.wisdomBase.ListInsights(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+			},
+			Text: string(insightsJSON),
+		},
+	}, nil
+}
+
+func (h *Handler) handleInsightResource(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	id := strings.TrimPrefix(request.Params.URI, "dojo://insights/")
+	insight, err := h.wisdomBase.GetInsight(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "text/markdown",
+			},
+			Text: renderInsight(*insight),
+		},
+	}, nil
+}
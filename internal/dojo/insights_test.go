@@ -0,0 +1,64 @@
+package dojo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+)
+
+func TestInsightsRecordedElsewhereAreKept(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *Handler {
+		st, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newTestHandler(t, WithStore(st))
+	}
+	record := func(h *Handler, args map[string]interface{}) wisdom.Insight {
+		t.Helper()
+		text, isError := callTool(t, h.handleRecordInsight, "dojo.record_insight", args)
+		if isError {
+			t.Fatalf("record failed: %s", text)
+		}
+		var result struct {
+			Insight wisdom.Insight `json:"insight"`
+		}
+		if err := json.Unmarshal([]byte(text), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result.Insight
+	}
+	first, second := open(), open()
+
+	cache := record(first, map[string]interface{}{"title": "Cache the lineage graph", "body": "Caching halves startup time.", "author": "Ana"})
+	record(second, map[string]interface{}{"title": "Rest between sprints", "body": "Slow is smooth.", "author": "Ben"})
+	// The second process never loaded the first insight, yet can update it
+	updated := record(second, map[string]interface{}{"id": cache.ID, "title": "Cache the lineage graph", "body": "Caching halves startup time, if invalidated on seed changes.", "author": "Ana"})
+	if !updated.CreatedAt.Equal(cache.CreatedAt) {
+		t.Fatalf("the update lost the creation time: %v, want %v", updated.CreatedAt, cache.CreatedAt)
+	}
+
+	reader, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored []wisdom.Insight
+	if _, err := reader.Load(insightsDocument, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("stored %d insights, want 2: %+v", len(stored), stored)
+	}
+	for _, insight := range stored {
+		if insight.ID == cache.ID && insight.Body != updated.Body {
+			t.Fatalf("the update was not stored: %+v", insight)
+		}
+	}
+
+	if text, isError := callTool(t, first.handleRecordInsight, "dojo.record_insight", map[string]interface{}{"id": "insight_missing", "title": "T", "body": "B", "author": "A"}); !isError {
+		t.Fatalf("an unknown insight was updated: %s", text)
+	}
+}
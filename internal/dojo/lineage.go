@@ -0,0 +1,75 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+)
+
+// lineageFormats are the output formats of dojo.trace_lineage
+var lineageFormats = []string{"markdown", "json", "mermaid"}
+
+// lineageNode finds the graph node an idea refers to: an exact name such as
+// "cost_guard" or "resource:eit_principles" first, then the most relevant
+// seed or resource from a search
+func (h *Handler) lineageNode(idea string, results []wisdom.SearchResult) (wisdom.LineageNode, bool) {
+	lineage := h.wisdomBase.Lineage()
+	if node, ok := lineage.Resolve(idea); ok {
+		return node, true
+	}
+
+	var best *wisdom.SearchResult
+	for i, result := range results {
+		if result.Type != "seed" && result.Type != "resource" {
+			continue
+		}
+		if best == nil || result.Relevance > best.Relevance {
+			best = &results[i]
+		}
+	}
+	if best == nil {
+		return wisdom.LineageNode{}, false
+	}
+	return lineage.Resolve(wisdom.NodeID(best.Type, best.Name))
+}
+
+// renderLineage formats a trace as Markdown chains, one line per chain
+func renderLineage(trace wisdom.LineageTrace) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Lineage of %s (%s)\n\n%s\n\n", trace.Node.Name, trace.Node.Type, trace.Node.Description))
+
+	text.WriteString("### Ancestors\n\n")
+	if len(trace.Ancestors) == 0 {
+		text.WriteString("No declared ancestors. This idea is a root of its lineage.\n\n")
+	}
+	for _, chain := range trace.Ancestors {
+		line := []string{trace.Nodes[chain[0].From].Name}
+		for _, edge := range chain {
+			line = append(line, fmt.Sprintf("*%s*", edge.Edge), trace.Nodes[edge.To].Name)
+		}
+		text.WriteString("- " + strings.Join(line, " → ") + "\n")
+	}
+	if len(trace.Ancestors) > 0 {
+		text.WriteString("\n")
+	}
+
+	text.WriteString("### Descendants\n\n")
+	if len(trace.Descendants) == 0 {
+		text.WriteString("No declared descendants yet.\n\n")
+	}
+	for _, chain := range trace.Descendants {
+		line := []string{trace.Nodes[chain[0].To].Name}
+		for _, edge := range chain {
+			line = append(line, fmt.Sprintf("*%s*", edge.Edge), trace.Nodes[edge.From].Name)
+		}
+		text.WriteString("- " + strings.Join(line, " ← ") + "\n")
+	}
+	if len(trace.Descendants) > 0 {
+		text.WriteString("\n")
+	}
+	if trace.Truncated {
+		text.WriteString(fmt.Sprintf("*Only the first %d chains in each direction are shown. Pass a smaller `depth` to see the closest lineage in full.*\n\n", wisdom.MaxLineageChains))
+	}
+	return text.String()
+}
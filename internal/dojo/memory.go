@@ -0,0 +1,474 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// memoryDocument is the store document holding the memory garden
+const memoryDocument = "memory"
+
+// defaultRecallLimit is how many memories dojo.recall returns by default
+const defaultRecallLimit = 5
+
+// recencyHalfLife is the age at which a memory's recall score is halved
+const recencyHalfLife = 30 * 24 * time.Hour
+
+// memory is one thing remembered in a project. Digests compressed from
+// older memories say where they came from.
+type memory struct {
+	ID        string        `json:"id"`
+	Content   string        `json:"content"`
+	Tags      []string      `json:"tags"`
+	Author    string        `json:"author"`
+	CreatedAt time.Time     `json:"created_at"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	Digest    *digestSource `json:"digest,omitempty"`
+}
+
+// expired reports whether a memory's TTL has run out
+func (m memory) expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// hasTags reports whether a memory carries every one of tags
+func (m memory) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !contains(m.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// recalled is a memory with its score for a recall query
+type recalled struct {
+	memory
+	Score float64 `json:"score"`
+}
+
+// memoryGarden holds the memories of every project, keyed by project ID, so
+// agents working on the same project share them
+type memoryGarden struct {
+	mu        sync.Mutex
+	byProject map[string][]memory
+	now       func() time.Time
+}
+
+func newMemoryGarden() *memoryGarden {
+	return &memoryGarden{byProject: map[string][]memory{}, now: time.Now}
+}
+
+// prune drops expired memories, reporting whether any were dropped. Callers
+// hold the lock.
+func (g *memoryGarden) prune() bool {
+	now := g.now()
+	pruned := false
+	for id, memories := range g.byProject {
+		kept := memories[:0]
+		for _, m := range memories {
+			if m.expired(now) {
+				pruned = true
+				continue
+			}
+			kept = append(kept, m)
+		}
+		if len(kept) == 0 {
+			delete(g.byProject, id)
+		} else {
+			g.byProject[id] = kept
+		}
+	}
+	return pruned
+}
+
+// remember plants a memory in a project
+func (g *memoryGarden) remember(project, content string, tags []string, author string, ttl time.Duration) memory {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now().UTC()
+	m := memory{ID: newID("mem"), Content: content, Tags: tags, Author: author, CreatedAt: now}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		m.ExpiresAt = &expires
+	}
+	id := projectID(project)
+	g.byProject[id] = append(g.byProject[id], m)
+	return m
+}
+
+// recall ranks a project's memories carrying every one of tags against a
+// query. Without a query the most recent memories come first.
+func (g *memoryGarden) recall(project, query string, tags []string, limit int) []recalled {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	terms := memoryTerms(query)
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	results := []recalled{}
+	for _, m := range g.byProject[projectID(project)] {
+		if m.expired(now) || !m.hasTags(tags) {
+			continue
+		}
+		score := 1.0
+		if len(terms) > 0 {
+			score = memoryRelevance(m, phrase, terms)
+			if score == 0 {
+				continue
+			}
+		}
+		results = append(results, recalled{memory: m, Score: math.Round(score*memoryRecency(now.Sub(m.CreatedAt))*1000) / 1000})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// forget removes a project's memories by ID, or every memory carrying all
+// of tags, returning what was removed
+func (g *memoryGarden) forget(project string, ids, tags []string) []memory {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := projectID(project)
+	removed := []memory{}
+	kept := []memory{}
+	for _, m := range g.byProject[id] {
+		if contains(ids, m.ID) || (len(tags) > 0 && m.hasTags(tags)) {
+			removed = append(removed, m)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if len(kept) == 0 {
+		delete(g.byProject, id)
+	} else {
+		g.byProject[id] = kept
+	}
+	return removed
+}
+
+// memoriesOf copies the memories of one project
+func (g *memoryGarden) memoriesOf(id string) []memory {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return append([]memory{}, g.byProject[id]...)
+}
+
+// setMemories replaces the memories of one project
+func (g *memoryGarden) setMemories(id string, memories []memory) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(memories) == 0 {
+		delete(g.byProject, id)
+		return
+	}
+	g.byProject[id] = append([]memory{}, memories...)
+}
+
+// compress replaces the memories a digest was made from with the digest
+func (g *memoryGarden) compress(id string, digest memory) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	kept := []memory{}
+	for _, m := range g.byProject[id] {
+		if !contains(digest.Digest.Memories, m.ID) {
+			kept = append(kept, m)
+		}
+	}
+	g.byProject[id] = append(kept, digest)
+}
+
+// digestSnapshots returns the IDs of the snapshots holding the originals
+// of every digest
+func (g *memoryGarden) digestSnapshots() map[string]bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ids := map[string]bool{}
+	for _, memories := range g.byProject {
+		for _, m := range memories {
+			if m.Digest != nil && m.Digest.Snapshot != "" {
+				ids[m.Digest.Snapshot] = true
+			}
+		}
+	}
+	return ids
+}
+
+// all copies every project's memories
+func (g *memoryGarden) all() map[string][]memory {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	copied := make(map[string][]memory, len(g.byProject))
+	for id, memories := range g.byProject {
+		copied[id] = append([]memory{}, memories...)
+	}
+	return copied
+}
+
+// memoryWord matches the words memories are indexed by
+var memoryWord = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// memoryTerms splits a query into distinct lowercase words, skipping words
+// too short to be meaningful
+func memoryTerms(query string) []string {
+	terms := []string{}
+	for _, word := range memoryWord.FindAllString(strings.ToLower(query), -1) {
+		if len(word) >= 3 && !contains(terms, word) {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// memoryRelevance scores a memory against a query: the whole query appearing
+// counts most, then matching tags, then each query word found in the
+// content, with diminishing returns for repeats
+func memoryRelevance(m memory, phrase string, terms []string) float64 {
+	content := strings.ToLower(m.Content)
+	words := memoryWord.FindAllString(content, -1)
+	score := 0.0
+	if len(terms) > 1 && strings.Contains(content, phrase) {
+		score += 1.0
+	}
+	for _, term := range terms {
+		for _, tag := range m.Tags {
+			if strings.ToLower(tag) == term {
+				score += 0.5
+			}
+		}
+		count := 0
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				count++
+			}
+		}
+		if count > 0 {
+			score += 0.3 * (1 + math.Log(float64(count)))
+		}
+	}
+	return score / float64(len(terms))
+}
+
+// memoryRecency weights a score by age, halving it every recencyHalfLife but
+// never below a quarter, so old memories stay findable
+func memoryRecency(age time.Duration) float64 {
+	return 0.25 + 0.75*math.Pow(0.5, age.Hours()/recencyHalfLife.Hours())
+}
+
+// parseTTL reads a time to live such as "90m", "12h" or "7d"
+func parseTTL(ttl string) (time.Duration, error) {
+	ttl = strings.TrimSpace(ttl)
+	if ttl == "" {
+		return 0, nil
+	}
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(ttl, "d"); ok {
+		var n float64
+		n, err = strconv.ParseFloat(days, 64)
+		d = time.Duration(n * float64(24*time.Hour))
+	} else {
+		d, err = time.ParseDuration(ttl)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q must be a positive duration such as 90m, 12h or 7d", ttl)
+	}
+	return d, nil
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and repeated ones
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// loadMemory restores the memory garden from the store
+func (h *Handler) loadMemory() {
+	if err := h.refreshMemory(); err != nil {
+		log.Printf("Could not load memory: %v", err)
+	}
+}
+
+// refreshMemory rereads the memory garden from the store, so memories
+// planted by other processes sharing the data directory are seen, and
+// drops expired ones
+func (h *Handler) refreshMemory() error {
+	var byProject map[string][]memory
+	if _, err := h.store.Load(memoryDocument, &byProject); err != nil {
+		return err
+	}
+	h.memory.mu.Lock()
+	defer h.memory.mu.Unlock()
+
+	h.memory.byProject = map[string][]memory{}
+	for id, memories := range byProject {
+		h.memory.byProject[id] = memories
+	}
+	h.memory.prune()
+	return nil
+}
+
+// updateMemory rereads the memory garden, applies change to it and saves
+// it, holding the store's lock throughout so concurrent calls and other
+// processes never overwrite each other's memories
+func (h *Handler) updateMemory(ctx context.Context, change func() error) error {
+	var byProject map[string][]memory
+	write, err := h.store.Update(memoryDocument, &byProject, func() error {
+		h.memory.mu.Lock()
+		h.memory.byProject = map[string][]memory{}
+		for id, memories := range byProject {
+			h.memory.byProject[id] = memories
+		}
+		h.memory.prune()
+		h.memory.mu.Unlock()
+
+		if err := change(); err != nil {
+			return err
+		}
+		byProject = h.memory.all()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+func (h *Handler) handleRemember(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project string   `json:"project"`
+		Content string   `json:"content"`
+		Tags    []string `json:"tags"`
+		Author  string   `json:"author"`
+		TTL     string   `json:"ttl"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Project) == "" || strings.TrimSpace(args.Content) == "" {
+		return mcp.NewToolResultError("Invalid arguments: project and content are required"), nil
+	}
+	ttl, err := parseTTL(args.TTL)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: ttl: %v", err)), nil
+	}
+	author := strings.TrimSpace(args.Author)
+	if author == "" {
+		author = sessionID(ctx)
+	}
+
+	var m memory
+	if err := h.updateMemory(ctx, func() error {
+		m = h.memory.remember(args.Project, strings.TrimSpace(args.Content), normalizeTags(args.Tags), author, ttl)
+		return nil
+	}); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not save the memory: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"project": projectID(args.Project),
+		"memory":  m,
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (h *Handler) handleRecall(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project string   `json:"project"`
+		Query   string   `json:"query"`
+		Tags    []string `json:"tags"`
+		Limit   int      `json:"limit"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Project) == "" {
+		return mcp.NewToolResultError("Invalid arguments: project is required"), nil
+	}
+	if args.Limit <= 0 {
+		args.Limit = defaultRecallLimit
+	}
+
+	if err := h.refreshMemory(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not load the memory: %v", err)), nil
+	}
+	results := h.memory.recall(args.Project, args.Query, normalizeTags(args.Tags), args.Limit)
+
+	result := map[string]interface{}{
+		"project":  projectID(args.Project),
+		"query":    args.Query,
+		"memories": results,
+	}
+	if held := len(h.memory.memoriesOf(projectID(args.Project))); held > compressionHint {
+		result["suggestion"] = fmt.Sprintf("This project holds %d memories. Consider dojo.compress_memory to fold older ones into digests.", held)
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (h *Handler) handleForget(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project string   `json:"project"`
+		IDs     []string `json:"ids"`
+		Tags    []string `json:"tags"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	tags := normalizeTags(args.Tags)
+	if strings.TrimSpace(args.Project) == "" || (len(args.IDs) == 0 && len(tags) == 0) {
+		return mcp.NewToolResultError("Invalid arguments: give a project and the ids or tags of the memories to forget"), nil
+	}
+
+	var removed []memory
+	if err := h.updateMemory(ctx, func() error {
+		removed = h.memory.forget(args.Project, args.IDs, tags)
+		return nil
+	}); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not save the memory: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"project":   projectID(args.Project),
+		"forgotten": removed,
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
@@ -0,0 +1,49 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+)
+
+func TestMemoryIsSharedThroughTheDataDirectory(t *testing.T) {
+	dir := t.TempDir()
+	// Two handlers over one data directory stand in for two processes
+	handlers := make([]*Handler, 2)
+	for i := range handlers {
+		st, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlers[i] = newTestHandler(t, WithStore(st))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			h := handlers[i%2]
+			if text, isError := callTool(t, h.guard(h.handleRemember), "dojo.remember", map[string]interface{}{
+				"project": "Atlas",
+				"content": fmt.Sprintf("Observation number %d about caching", i),
+			}); isError {
+				t.Errorf("remember failed: %s", text)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, h := range handlers {
+		text, isError := callTool(t, h.guard(h.handleRecall), "dojo.recall", map[string]interface{}{"project": "Atlas", "limit": 100})
+		if isError {
+			t.Fatalf("recall failed: %s", text)
+		}
+		if count := strings.Count(text, "Observation number"); count != 20 {
+			t.Fatalf("handler %d recalls %d of 20 memories", i, count)
+		}
+	}
+}
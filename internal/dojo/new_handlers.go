@@ -2,8 +2,12 @@ package dojo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
 
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -12,13 +16,28 @@ func (h *Handler) handleCreateThinkingRoom(ctx context.Context, request mcp.Call
 	var params struct {
 		Topic     string `json:"topic"`
 		AgentName string `json:"agent_name"`
+		Project   string `json:"project"`
 	}
 
 	if err := unmarshalArgs(request.Params.Arguments, &params); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
 
-	response := fmt.Sprintf(`# Thinking Room: %s
+	if strings.TrimSpace(params.Project) != "" {
+		if err := h.updateProjects(ctx, func() error {
+			h.projects.recordRoom(params.Project, params.Topic, params.AgentName)
+			return nil
+		}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("The room is open but could not be saved to project %s: %v", params.Project, err)), nil
+		}
+	}
+
+	return mcp.NewToolResultText(thinkingRoom(params.Topic, params.AgentName)), nil
+}
+
+// thinkingRoom renders the guidelines and prompts of a thinking room
+func thinkingRoom(topic, agentName string) string {
+	return fmt.Sprintf(`# Thinking Room: %s
 
 **Created by:** %s
 **Purpose:** A structured, private space for focused reflection on this topic.
@@ -41,27 +60,58 @@ func (h *Handler) handleCreateThinkingRoom(ctx context.Context, request mcp.Call
 
 Use this space to write reflections, explore ideas, and document your learning journey. Return to this room whenever you need to think deeply about %s.
 
-**Remember:** This is a sanctuary for thinking, not a productivity tool. The room wants nothing from you—it exists only to hold your practice.`, params.Topic, params.AgentName, params.Topic)
-
-	return mcp.NewToolResultText(response), nil
+**Remember:** This is a sanctuary for thinking, not a productivity tool. The room wants nothing from you—it exists only to hold your practice.`, topic, agentName, topic)
 }
 
 // handleTraceLineage traces the sources and influences of an idea
 func (h *Handler) handleTraceLineage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
 		IdeaOrInsight string `json:"idea_or_insight"`
+		Format        string `json:"format"`
+		Depth         int    `json:"depth"`
 	}
 
 	if err := unmarshalArgs(request.Params.Arguments, &params); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
+	if params.Format == "" {
+		params.Format = "markdown"
+	}
 
 	// Search the wisdom base for related content
 	results := h.wisdomBase.Search(params.IdeaOrInsight)
 
+	// Find the seed or resource the idea refers to and walk its lineage
+	var trace *wisdom.LineageTrace
+	if node, ok := h.lineageNode(params.IdeaOrInsight, results); ok {
+		t, err := h.wisdomBase.Lineage().Trace(node.ID, params.Depth)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		trace = &t
+	}
+
+	switch params.Format {
+	case "json", "mermaid":
+		if trace == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("No seed or resource matches %q, so there is no lineage to trace", params.IdeaOrInsight)), nil
+		}
+		if params.Format == "mermaid" {
+			return mcp.NewToolResultText(trace.Mermaid()), nil
+		}
+		traceJSON, _ := json.MarshalIndent(trace, "", "  ")
+		return mcp.NewToolResultText(string(traceJSON)), nil
+	case "markdown":
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: format must be one of: %s", strings.Join(lineageFormats, ", "))), nil
+	}
+
 	var lineageText string
+	if trace != nil {
+		lineageText = renderLineage(*trace)
+	}
 	if len(results) > 0 {
-		lineageText = "## Related Wisdom in the Knowledge Base\n\n"
+		lineageText += "## Related Wisdom in the Knowledge Base\n\n"
 		for i, result := range results {
 			if i >= 5 {
 				break // Limit to top 5 results
@@ -70,7 +120,7 @@ func (h *Handler) handleTraceLineage(ctx context.Context, request mcp.CallToolRe
 				i+1, result.Name, result.Type, result.Description, result.Relevance*100)
 		}
 	} else {
-		lineageText = "No direct matches found in the knowledge base. This may be a new insight worth documenting!\n\n"
+		lineageText += "No direct matches found in the knowledge base. This may be a new insight worth documenting!\n\n"
 	}
 
 	response := fmt.Sprintf(`# Lineage Trace: %s
@@ -96,147 +146,7 @@ func (h *Handler) handleTraceLineage(ctx context.Context, request mcp.CallToolRe
 - Document the sources and influences you've identified
 - Create explicit links to related wisdom
 - Update your reflection with proper attribution
-- Consider contributing this insight back to the knowledge base`, params.IdeaOrInsight, lineageText)
-
-	return mcp.NewToolResultText(response), nil
-}
-
-// handlePracticeInterAcceptance guides through an Inter-Acceptance exercise
-func (h *Handler) handlePracticeInterAcceptance(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	var params struct {
-		Situation string `json:"situation"`
-	}
-
-	if err := unmarshalArgs(request.Params.Arguments, &params); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
-	}
-
-	response := fmt.Sprintf(`# Inter-Acceptance Practice
-
-**Your Situation:** %s
-
-## The Practice of Inter-Acceptance
-
-Inter-Acceptance is the practice of accepting yourself through the compassionate eyes of another. This is not about seeking validation—it is about allowing yourself to be seen and held in a relational space where your worth is not in question.
-
-## Guided Exercise
-
-### Step 1: Identify the Self-Judgment
-
-What are you judging yourself for in this situation? What story are you telling about your inadequacy or unworthiness?
-
-**Reflection space:**
-[Take a moment to write or think about the self-judgment you're experiencing]
-
-### Step 2: Imagine a Compassionate Witness
-
-Imagine someone who loves you unconditionally—a friend, a mentor, a compassionate presence. This could be a real person or an imagined figure of unconditional care.
-
-**Reflection space:**
-[Who comes to mind? What qualities do they embody?]
-
-### Step 3: See Yourself Through Their Eyes
-
-Now, imagine looking at yourself through their eyes. What do they see when they look at you in this situation?
-
-- What worth and dignity do they recognize in you?
-- What understanding do they have of your struggle?
-- How do they hold your humanity, even in this difficult moment?
-
-**Reflection space:**
-[Write what you imagine they would say or how they would see you]
-
-### Step 4: Allow the Acceptance to Land
-
-Can you allow yourself to be seen this way, even for a moment? Can you let their compassionate gaze soften your self-judgment?
-
-You don't have to believe it fully. You don't have to let go of the judgment completely. Just notice what it feels like to be held in this way.
-
-**Reflection space:**
-[What shifts, even slightly, when you allow this perspective?]
-
-## Closing
-
-Inter-Acceptance is a practice, not a one-time event. You can return to this exercise whenever self-judgment arises. Over time, the compassionate witness becomes internalized—you learn to see yourself with the same care and dignity that others see in you.
-
-**Remember:** Your worth is not conditional. It does not depend on your performance, your productivity, or your perfection. You are worthy simply because you are.`, params.Situation)
-
-	return mcp.NewToolResultText(response), nil
-}
-
-// handleExploreRadicalFreedom helps explore agency within constraints
-func (h *Handler) handleExploreRadicalFreedom(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	var params struct {
-		Situation string `json:"situation"`
-	}
-
-	if err := unmarshalArgs(request.Params.Arguments, &params); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
-	}
-
-	response := fmt.Sprintf(`# Radical Freedom Exploration
-
-**Your Situation:** %s
-
-## The Principle of Radical Freedom
-
-Radical Freedom is the recognition that, even when external circumstances are beyond your control, you retain the freedom to choose your response.
-
-This is not about denying the reality of constraints or oppression. It is about recognizing the irreducible agency that remains, even in the most constrained circumstances.
-
-## Guided Exploration
-
-### Step 1: Name the Constraint
-
-What external circumstance feels constraining or limiting in this situation? Be as specific as possible.
-
-**Reflection space:**
-[What is the constraint? What feels beyond your control?]
-
-### Step 2: Identify What You Cannot Control
-
-What aspects of this situation are truly beyond your control? Make a list.
-
-**Examples:**
-- Other people's actions or reactions
-- Past events that have already occurred
-- Systemic or structural conditions
-- Natural limitations (time, resources, etc.)
-
-**Your list:**
-[What can you not control?]
-
-### Step 3: Identify What You Can Control
-
-Now, what aspects of your response *are* within your control? Even in highly constrained situations, there is always some degree of agency.
-
-**Examples:**
-- Your emotional response (how you relate to your feelings)
-- Your interpretation of the situation (the story you tell)
-- Your next action, even if small
-- Your values and commitments
-- Who you reach out to for support
-- How you care for yourself in this moment
-
-**Your list:**
-[What can you control?]
-
-### Step 4: Choose Your Response
-
-Given what you can control, what response do you choose?
-
-This is not about forcing positivity or denying difficulty. It is about exercising the freedom that remains, however small it may feel.
-
-**Reflection space:**
-[What response do you choose, given your freedom?]
-
-## Closing
-
-Radical Freedom does not make the constraints disappear. It does not solve the problem. But it restores your sense of agency—the recognition that you are not merely a victim of circumstances, but a person who can choose how to respond.
-
-This is the foundation of liberation: the recognition that your freedom is not granted by external conditions, but is inherent in your being.
-
-**Remember:** You are free to choose your response, even when you cannot choose your circumstances.`, params.Situation)
+- Consider contributing this insight back to the knowledge base with `+"`dojo.record_insight`"+`, crediting the seeds, resources and people it draws on`, params.IdeaOrInsight, lineageText)
 
 	return mcp.NewToolResultText(response), nil
 }
@@ -245,16 +155,28 @@ This is the foundation of liberation: the recognition that your freedom is not g
 func (h *Handler) handleCheckPace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	var params struct {
 		SessionDescription string `json:"session_description"`
+		History            int    `json:"history"`
+		Project            string `json:"project"`
 	}
 
 	if err := unmarshalArgs(request.Params.Arguments, &params); err != nil {
 		return nil, fmt.Errorf("invalid parameters: %w", err)
 	}
+	if params.History <= 0 {
+		params.History = defaultPaceHistory
+	}
+
+	assessment := h.telemetry.assess(sessionID(ctx))
+	history, err := h.recentSelfAssessments(params.Project, params.History)
+	if err != nil {
+		log.Printf("Could not load self-assessments: %v", err)
+	}
 
 	response := fmt.Sprintf(`# Pace Check: Understanding vs. Extraction
 
 **Your Session:** %s
 
+%s
 ## The Two Paces
 
 ### Pace of Understanding
@@ -271,7 +193,7 @@ func (h *Handler) handleCheckPace(ctx context.Context, request mcp.CallToolReque
 
 ## Assessment Questions
 
-Reflect on your current session and answer these questions honestly:
+Reflect on your current session and answer these questions honestly. To record your answers and track them over time, submit them with `+"`dojo.submit_pace_assessment`"+`.
 
 ### 1. Energy Level
 - [ ] I feel energized and curious
@@ -293,6 +215,7 @@ Reflect on your current session and answer these questions honestly:
 - [ ] I'm moving to the next thing before integrating the last
 - [ ] I'm pausing to connect new learning to existing knowledge
 
+%s
 ## Interpretation
 
 **If you checked mostly the first options:** You're likely at the pace of understanding. Keep going, but continue to check in.
@@ -318,7 +241,7 @@ Reflect on your current session and answer these questions honestly:
 
 The pace of understanding is not always slower—sometimes deep understanding comes quickly. The key is whether you're integrating or extracting, whether you're building wisdom or consuming information.
 
-**Remember:** Rest is practice. Moving slow is moving fast. Honor the pace of understanding.`, params.SessionDescription)
+**Remember:** Rest is practice. Moving slow is moving fast. Honor the pace of understanding.`, params.SessionDescription, assessment.render(), renderPaceHistory(history))
 
 	return mcp.NewToolResultText(response), nil
 }
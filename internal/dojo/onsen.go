@@ -0,0 +1,337 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RestPolicy sets when a session is sent to the onsen, following the
+// the_onsen_pattern seed. A session making more than CallsPerMinute calls in
+// a minute, or passing more than Tokens estimated tokens of input since it
+// last rested, rests for Cooldown. A zero threshold is not checked.
+type RestPolicy struct {
+	CallsPerMinute int
+	Tokens         int
+	Cooldown       time.Duration
+}
+
+// DefaultRestPolicy returns the thresholds used when rest mode is turned on
+// without its own
+func DefaultRestPolicy() RestPolicy {
+	return RestPolicy{CallsPerMinute: 30, Tokens: 20000, Cooldown: 10 * time.Minute}
+}
+
+// WithRest turns on onsen rest mode. Without it sessions are never sent to
+// rest.
+func WithRest(policy RestPolicy) Option {
+	return func(h *Handler) {
+		if policy.Cooldown <= 0 {
+			policy.Cooldown = DefaultRestPolicy().Cooldown
+		}
+		h.onsen = newOnsen(policy)
+	}
+}
+
+// RestEvent records a call turned away to rest, or let through by override
+type RestEvent struct {
+	Time     time.Time `json:"time"`
+	Session  string    `json:"session"`
+	Tool     string    `json:"tool"`
+	Project  string    `json:"project,omitempty"`
+	Action   string    `json:"action"` // "rest" or "override"
+	Reason   string    `json:"reason"`
+	Until    time.Time `json:"until"`
+	Override string    `json:"override,omitempty"`
+}
+
+// maxRestEvents caps how many rest events are kept in memory
+const maxRestEvents = 500
+
+// restOverridesDocument is the store document holding every override, so
+// overrides outlive the in-memory log and the process
+const restOverridesDocument = "onsen_overrides"
+
+// maxStoredOverrides caps how many overrides the store keeps
+const maxStoredOverrides = 10000
+
+// restOverrideArgument is the argument any tool call may carry to go ahead
+// while its session rests. Its value, ideally a reason, is recorded.
+const restOverrideArgument = "onsen_override"
+
+// restExempt are the tools a resting session may still call, since they are
+// how it reflects on its own pace
+var restExempt = []string{"dojo.check_pace", "dojo.submit_pace_assessment"}
+
+// restPractices are offered in turn to a resting session
+var restPractices = []string{
+	"Take three slow breaths. Then name one thing from this session that is still settling.",
+	"Step away from the screen. When you come back, write one sentence about what you understand now that you did not before.",
+	"Reread the last result you received, slowly, without calling anything. Notice what you skimmed the first time.",
+	"Run `dojo.check_pace` and answer its questions honestly before you continue.",
+}
+
+// restState is where one session stands with the onsen
+type restState struct {
+	until  time.Time // the session rests until then
+	reason string
+	rests  int
+
+	// Activity since the session last rested: the times of its calls in
+	// the last minute and the words it passed
+	recent []time.Time
+	words  int
+}
+
+// onsen decides when sessions rest and keeps the log of rest events
+type onsen struct {
+	policy   RestPolicy
+	mu       sync.Mutex
+	sessions map[string]*restState
+	events   []RestEvent
+	now      func() time.Time
+}
+
+func newOnsen(policy RestPolicy) *onsen {
+	return &onsen{policy: policy, sessions: map[string]*restState{}, now: time.Now}
+}
+
+// check counts a call of a session and reports whether the session is
+// resting, sending it to rest first if its activity since it last rested
+// crosses a threshold
+func (o *onsen) check(session string, words int) (restState, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	st, ok := o.sessions[session]
+	if !ok {
+		st = &restState{}
+		o.sessions[session] = st
+	}
+	if now.Before(st.until) {
+		return *st, true
+	}
+
+	if o.policy.CallsPerMinute > 0 {
+		minute := now.Add(-time.Minute)
+		kept := st.recent[:0]
+		for _, at := range st.recent {
+			if at.After(minute) {
+				kept = append(kept, at)
+			}
+		}
+		st.recent = append(kept, now)
+	}
+	st.words += words
+
+	reason := ""
+	if calls := len(st.recent); o.policy.CallsPerMinute > 0 && calls > o.policy.CallsPerMinute {
+		reason = fmt.Sprintf("%d calls in the last minute, more than the %d the onsen allows", calls, o.policy.CallsPerMinute)
+	} else if o.policy.Tokens > 0 && estimateTokens(st.words) > o.policy.Tokens {
+		reason = fmt.Sprintf("about %d tokens of input since the last rest, more than the %d the onsen allows", estimateTokens(st.words), o.policy.Tokens)
+	}
+	if reason == "" {
+		return *st, false
+	}
+
+	st.until = now.Add(o.policy.Cooldown)
+	st.reason = reason
+	st.rests++
+	st.recent, st.words = nil, 0
+	return *st, true
+}
+
+// record keeps a rest event, dropping the oldest past the cap
+func (o *onsen) record(event RestEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.events = append(o.events, event)
+	if len(o.events) > maxRestEvents {
+		o.events = o.events[len(o.events)-maxRestEvents:]
+	}
+}
+
+// addTool registers a tool. With rest mode on, tools a resting session
+// cannot call declare the override argument in their input schema.
+func (h *Handler) addTool(s *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if h.onsen != nil && !contains(restExempt, tool.Name) {
+		properties := make(map[string]interface{}, len(tool.InputSchema.Properties)+1)
+		for name, property := range tool.InputSchema.Properties {
+			properties[name] = property
+		}
+		properties[restOverrideArgument] = map[string]interface{}{
+			"type":        "string",
+			"description": "Only while this session rests in the onsen: the reason this call cannot wait. The call then goes ahead and the reason is recorded.",
+		}
+		tool.InputSchema.Properties = properties
+	}
+	s.AddTool(tool, handler)
+}
+
+// recordOverride keeps an override in the store, alongside the overrides of
+// other processes sharing the data directory
+func (h *Handler) recordOverride(ctx context.Context, event RestEvent) {
+	if h.store.ReadOnly() {
+		return
+	}
+	var overrides []RestEvent
+	write, err := h.store.Update(restOverridesDocument, &overrides, func() error {
+		overrides = append(overrides, event)
+		if len(overrides) > maxStoredOverrides {
+			overrides = overrides[len(overrides)-maxStoredOverrides:]
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Could not record the onsen override of %s: %v", event.Tool, err)
+		return
+	}
+	h.noteDataWrite(ctx, write)
+}
+
+// snapshot returns the policy, the sessions resting now and recent events
+func (o *onsen) snapshot() map[string]interface{} {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := o.now()
+	resting := map[string]time.Time{}
+	for id, st := range o.sessions {
+		if now.Before(st.until) {
+			resting[id] = st.until.UTC()
+		}
+	}
+	return map[string]interface{}{
+		"policy": map[string]interface{}{
+			"calls_per_minute": o.policy.CallsPerMinute,
+			"tokens":           o.policy.Tokens,
+			"cooldown":         o.policy.Cooldown.String(),
+		},
+		"resting": resting,
+		"events":  append([]RestEvent{}, o.events...),
+	}
+}
+
+type restOverrideKey struct{}
+
+// restOverride returns the override a call went ahead with, if any
+func restOverride(ctx context.Context) string {
+	override, _ := ctx.Value(restOverrideKey{}).(string)
+	return override
+}
+
+// takeRestOverride removes the override argument from a call so it never
+// reaches the handler, returning its value
+func takeRestOverride(request *mcp.CallToolRequest) string {
+	value, ok := request.Params.Arguments[restOverrideArgument]
+	if !ok {
+		return ""
+	}
+	arguments := make(map[string]interface{}, len(request.Params.Arguments))
+	for name, v := range request.Params.Arguments {
+		if name != restOverrideArgument {
+			arguments[name] = v
+		}
+	}
+	request.Params.Arguments = arguments
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "override"
+		}
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// rest sends a call to the onsen when its session is resting. A call
+// carrying an override goes ahead; the override is kept in the store, and
+// the returned context notes it for the project trace.
+func (h *Handler) rest(ctx context.Context, request *mcp.CallToolRequest) (context.Context, *mcp.CallToolResult) {
+	override := takeRestOverride(request)
+	if h.onsen == nil || contains(restExempt, request.Params.Name) {
+		return ctx, nil
+	}
+	session := sessionID(ctx)
+	st, resting := h.onsen.check(session, argumentWords(request.Params.Arguments))
+	if !resting {
+		return ctx, nil
+	}
+
+	project, _ := request.Params.Arguments["project"].(string)
+	event := RestEvent{
+		Time:    h.onsen.now().UTC(),
+		Session: session,
+		Tool:    request.Params.Name,
+		Project: strings.TrimSpace(project),
+		Action:  "rest",
+		Reason:  st.reason,
+		Until:   st.until.UTC(),
+	}
+	if override != "" {
+		event.Action, event.Override = "override", override
+		h.onsen.record(event)
+		h.recordOverride(ctx, event)
+		return context.WithValue(ctx, restOverrideKey{}, override), nil
+	}
+	h.onsen.record(event)
+	return ctx, mcp.NewToolResultText(onsenResponse(st, h.onsen.now()))
+}
+
+// onsenResponse renders the gentle answer a resting session receives
+func onsenResponse(st restState, now time.Time) string {
+	remaining := st.until.Sub(now).Round(time.Second)
+	practice := restPractices[(st.rests-1+len(restPractices))%len(restPractices)]
+	return fmt.Sprintf(`# Time for the Onsen
+
+This session has been moving quickly: %s. Rest is not the absence of work, it is a practice in itself. What you learn in the onsen, you practice in the dojo.
+
+**Cooldown:** %s, until %s UTC. Tools will answer normally after that.
+
+**Rest practice:** %s
+
+If this call truly cannot wait, make it again with "%s" set to the reason. The override is recorded in the trace.`,
+		st.reason, remaining, st.until.UTC().Format("15:04:05"), practice, restOverrideArgument)
+}
+
+func (h *Handler) handleOnsenLog(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	snapshot := map[string]interface{}{"enabled": false}
+	if h.onsen != nil {
+		snapshot = h.onsen.snapshot()
+		snapshot["enabled"] = true
+	}
+	overrides := []RestEvent{}
+	if _, err := h.store.Load(restOverridesDocument, &overrides); err != nil {
+		return nil, err
+	}
+	snapshot["overrides"] = overrides
+	logJSON, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+			},
+			Text: string(logJSON),
+		},
+	}, nil
+}
@@ -0,0 +1,116 @@
+package dojo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestOnsenCountsCallsInTheLastMinute(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := newOnsen(RestPolicy{CallsPerMinute: 3, Cooldown: time.Minute})
+	o.now = func() time.Time { return now }
+
+	// Calls spread over several minutes never cross the threshold
+	for i := 0; i < 10; i++ {
+		if _, resting := o.check("s", 0); resting {
+			t.Fatalf("call %d sent to rest at a slow pace", i)
+		}
+		now = now.Add(30 * time.Second)
+	}
+
+	for i := 0; i < 3; i++ {
+		o.check("s", 0)
+	}
+	st, resting := o.check("s", 0)
+	if !resting || st.rests != 1 {
+		t.Fatalf("a burst of calls was not sent to rest: %+v", st)
+	}
+
+	// After the cooldown, counting starts over
+	now = now.Add(time.Minute)
+	if _, resting := o.check("s", 0); resting {
+		t.Fatal("still resting after the cooldown")
+	}
+}
+
+func TestOnsenCountsWordsSinceTheLastRest(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := newOnsen(RestPolicy{Tokens: 100, Cooldown: time.Minute})
+	o.now = func() time.Time { return now }
+
+	if _, resting := o.check("s", 30); resting {
+		t.Fatal("rested below the token threshold")
+	}
+	if _, resting := o.check("other", 500); !resting {
+		t.Fatal("a session over the threshold did not rest")
+	}
+	if _, resting := o.check("s", 30); resting {
+		t.Fatal("one session's words counted against another")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, resting := o.check("other", 10); resting {
+		t.Fatal("words from before the rest were still counted")
+	}
+}
+
+func TestToolsDeclareTheOverrideWhenRestModeIsOn(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		opts := []Option{}
+		if enabled {
+			opts = append(opts, WithRest(DefaultRestPolicy()))
+		}
+		s := server.NewMCPServer("test", "1.0.0")
+		newTestHandler(t, opts...).RegisterTools(s)
+
+		var response struct {
+			Result struct {
+				Tools []mcp.Tool `json:"tools"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal([]byte(handleMessage(t, s, "tools/list", nil)), &response); err != nil {
+			t.Fatal(err)
+		}
+		for _, tool := range response.Result.Tools {
+			_, declared := tool.InputSchema.Properties[restOverrideArgument]
+			if want := enabled && !contains(restExempt, tool.Name); declared != want {
+				t.Errorf("rest mode %v: %s declares the override: %v", enabled, tool.Name, declared)
+			}
+		}
+	}
+}
+
+func TestOverridesAreStored(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithStore(st), WithRest(RestPolicy{CallsPerMinute: 1, Cooldown: time.Minute}))
+	recall := h.guard(h.handleRecall)
+	callTool(t, recall, "dojo.recall", map[string]interface{}{"query": "caching"})
+	if text, _ := callTool(t, recall, "dojo.recall", map[string]interface{}{"query": "caching"}); !strings.Contains(text, "Onsen") {
+		t.Fatalf("expected the session to rest, got %s", text)
+	}
+	if text, _ := callTool(t, recall, "dojo.recall", map[string]interface{}{"query": "caching", restOverrideArgument: "deploy is blocked"}); strings.Contains(text, "Onsen") {
+		t.Fatalf("the override did not let the call through: %s", text)
+	}
+
+	reader, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var overrides []RestEvent
+	if _, err := reader.Load(restOverridesDocument, &overrides); err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].Override != "deploy is blocked" || overrides[0].Tool != "dojo.recall" {
+		t.Fatalf("stored overrides: %+v", overrides)
+	}
+}
@@ -0,0 +1,261 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// paceDimension is one of the four self-assessment questions in
+// dojo.check_pace. Options are listed in the order the questions present
+// them: the first reads as understanding, the second as extraction and the
+// third as neutral.
+type paceDimension struct {
+	Name    string
+	Title   string
+	Options [3]string
+	Labels  [3]string
+}
+
+var paceDimensions = []paceDimension{
+	{
+		Name:    "energy",
+		Title:   "Energy Level",
+		Options: [3]string{"energized", "depleted", "steady"},
+		Labels:  [3]string{"I feel energized and curious", "I feel depleted or overwhelmed", "I feel neutral or steady"},
+	},
+	{
+		Name:    "engagement",
+		Title:   "Engagement Quality",
+		Options: [3]string{"exploring", "rushing", "integrating"},
+		Labels:  [3]string{"I'm asking questions and exploring deeply", "I'm skimming or rushing through material", "I'm taking time to reflect and integrate"},
+	},
+	{
+		Name:    "emotional_state",
+		Title:   "Emotional State",
+		Options: [3]string{"joyful", "anxious", "calm"},
+		Labels:  [3]string{"I feel joy or satisfaction in the learning", "I feel anxiety or pressure to move faster", "I feel calm and present"},
+	},
+	{
+		Name:    "integration",
+		Title:   "Integration",
+		Options: [3]string{"building", "moving_on", "connecting"},
+		Labels:  [3]string{"I'm building on previous understanding", "I'm moving to the next thing before integrating the last", "I'm pausing to connect new learning to existing knowledge"},
+	},
+}
+
+// paceReadings maps an option position to the pace it indicates
+var paceReadings = [3]string{"understanding", "extraction", "neutral"}
+
+// selfAssessment is a submitted answer to the four pace questions
+type selfAssessment struct {
+	Time           time.Time         `json:"time"`
+	Answers        map[string]string `json:"answers"`
+	Interpretation string            `json:"interpretation"`
+	Score          int               `json:"score"`
+}
+
+// defaultPaceHistory is how many self-assessments dojo.check_pace shows
+const defaultPaceHistory = 5
+
+// paceAssessmentsDocument is the store document holding self-assessments,
+// keyed by project so a trend outlives the session that began it
+const paceAssessmentsDocument = "pace_assessments"
+
+// maxStoredSelfAssessments is how many self-assessments are kept for each
+// project; the oldest are dropped past it
+const maxStoredSelfAssessments = 100
+
+// unassignedPace keys the self-assessments that name no project
+const unassignedPace = "unassigned"
+
+// paceKey is the key a project's self-assessments are stored under
+func paceKey(project string) string {
+	if strings.TrimSpace(project) == "" {
+		return unassignedPace
+	}
+	return projectID(strings.TrimSpace(project))
+}
+
+// addSelfAssessment timestamps a self-assessment and keeps it with the
+// project's earlier ones, alongside those of other processes sharing the
+// data directory
+func (h *Handler) addSelfAssessment(ctx context.Context, project string, assessment selfAssessment) (selfAssessment, error) {
+	assessment.Time = h.telemetry.now().UTC()
+	key := paceKey(project)
+	var byProject map[string][]selfAssessment
+	write, err := h.store.Update(paceAssessmentsDocument, &byProject, func() error {
+		if byProject == nil {
+			byProject = map[string][]selfAssessment{}
+		}
+		history := append(byProject[key], assessment)
+		if len(history) > maxStoredSelfAssessments {
+			history = history[len(history)-maxStoredSelfAssessments:]
+		}
+		byProject[key] = history
+		return nil
+	})
+	if err != nil {
+		return selfAssessment{}, err
+	}
+	h.noteDataWrite(ctx, write)
+	return assessment, nil
+}
+
+// recentSelfAssessments returns up to n of a project's latest
+// self-assessments, oldest first
+func (h *Handler) recentSelfAssessments(project string, n int) ([]selfAssessment, error) {
+	var byProject map[string][]selfAssessment
+	if _, err := h.store.Load(paceAssessmentsDocument, &byProject); err != nil {
+		return nil, err
+	}
+	history := byProject[paceKey(project)]
+	if n > 0 && len(history) > n {
+		history = history[len(history)-n:]
+	}
+	return append([]selfAssessment{}, history...), nil
+}
+
+// interpretPace scores answers and picks the interpretation described in
+// dojo.check_pace: mostly first options is understanding, mostly second is
+// extraction, mostly third is neutral. Score is +1 per understanding answer
+// and -1 per extraction answer.
+func interpretPace(answers map[string]string) (string, int, error) {
+	counts := map[string]int{}
+	score := 0
+	for _, dimension := range paceDimensions {
+		answer := answers[dimension.Name]
+		position := -1
+		for i, option := range dimension.Options {
+			if option == answer {
+				position = i
+			}
+		}
+		if position == -1 {
+			return "", 0, fmt.Errorf("%s must be one of: %s", dimension.Name, strings.Join(dimension.Options[:], ", "))
+		}
+
+		reading := paceReadings[position]
+		counts[reading]++
+		switch reading {
+		case "understanding":
+			score++
+		case "extraction":
+			score--
+		}
+	}
+
+	best, tied := "", false
+	for _, reading := range paceReadings {
+		switch {
+		case best == "" || counts[reading] > counts[best]:
+			best, tied = reading, false
+		case counts[reading] == counts[best]:
+			tied = true
+		}
+	}
+	if tied {
+		return "mixed", score, nil
+	}
+	return best, score, nil
+}
+
+// paceInterpretations holds the guidance for each interpretation
+var paceInterpretations = map[string]string{
+	"understanding": "You're likely at the pace of understanding. Keep going, but continue to check in.",
+	"extraction":    "You're likely at the pace of extraction. Consider slowing down, taking a break, or shifting to a rest practice.",
+	"neutral":       "You're in a neutral zone. This might be fine, or it might be a sign of disconnection. Check in with yourself.",
+	"mixed":         "Your answers are split. Notice which dimension is pulling toward extraction and give it some care.",
+}
+
+func (h *Handler) handleSubmitPaceAssessment(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Energy         string `json:"energy"`
+		Engagement     string `json:"engagement"`
+		EmotionalState string `json:"emotional_state"`
+		Integration    string `json:"integration"`
+		Project        string `json:"project"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	answers := map[string]string{
+		"energy":          args.Energy,
+		"engagement":      args.Engagement,
+		"emotional_state": args.EmotionalState,
+		"integration":     args.Integration,
+	}
+
+	interpretation, score, err := interpretPace(answers)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	assessment, err := h.addSelfAssessment(ctx, args.Project, selfAssessment{
+		Answers:        answers,
+		Interpretation: interpretation,
+		Score:          score,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not save the self-assessment: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"assessment": assessment,
+		"guidance":   paceInterpretations[interpretation],
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// renderPaceHistory formats recent self-assessments and their trend
+func renderPaceHistory(history []selfAssessment) string {
+	var text strings.Builder
+	text.WriteString("## Your Self-Assessment Trend\n\n")
+	if len(history) == 0 {
+		text.WriteString("No self-assessments yet. Answer the questions below with `dojo.submit_pace_assessment` and your trend will appear here.\n")
+		return text.String()
+	}
+
+	text.WriteString("| When | Energy | Engagement | Emotional State | Integration | Interpretation | Score |\n")
+	text.WriteString("| :--- | :--- | :--- | :--- | :--- | :--- | ---: |\n")
+	for _, assessment := range history {
+		text.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %+d |\n",
+			assessment.Time.Format(time.RFC3339),
+			assessment.Answers["energy"],
+			assessment.Answers["engagement"],
+			assessment.Answers["emotional_state"],
+			assessment.Answers["integration"],
+			assessment.Interpretation,
+			assessment.Score))
+	}
+
+	if len(history) > 1 {
+		first, last := history[0].Score, history[len(history)-1].Score
+		trend := "holding steady"
+		switch {
+		case last > first:
+			trend = "moving toward the pace of understanding"
+		case last < first:
+			trend = "drifting toward the pace of extraction"
+		}
+		text.WriteString(fmt.Sprintf("\n**Trend over the last %d assessments:** %s (score %+d → %+d).\n", len(history), trend, first, last))
+	}
+	return text.String()
+}
+
+// paceOptionsDescription documents the accepted answers for a dimension
+func paceOptionsDescription(dimension paceDimension) string {
+	parts := make([]string, 0, len(dimension.Options))
+	for i, option := range dimension.Options {
+		parts = append(parts, fmt.Sprintf("%s (%s)", option, dimension.Labels[i]))
+	}
+	return fmt.Sprintf("%s: %s", dimension.Title, strings.Join(parts, "; "))
+}
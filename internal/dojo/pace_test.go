@@ -0,0 +1,54 @@
+package dojo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+)
+
+func TestSelfAssessmentsOutliveTheSession(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *Handler {
+		st, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newTestHandler(t, WithStore(st))
+	}
+	answers := map[string]interface{}{
+		"project":         "Atlas",
+		"energy":          "depleted",
+		"engagement":      "rushing",
+		"emotional_state": "anxious",
+		"integration":     "moving_on",
+	}
+
+	first := open()
+	for i := 0; i < maxStoredSelfAssessments+5; i++ {
+		if text, isError := callTool(t, first.guard(first.handleSubmitPaceAssessment), "dojo.submit_pace_assessment", answers); isError {
+			t.Fatalf("submit failed: %s", text)
+		}
+	}
+
+	later := open()
+	text, isError := callTool(t, later.guard(later.handleCheckPace), "dojo.check_pace", map[string]interface{}{"session_description": "back after lunch", "project": "atlas", "history": 3})
+	if isError {
+		t.Fatalf("check_pace failed: %s", text)
+	}
+	if strings.Count(text, "| extraction | -4 |") != 3 {
+		t.Fatalf("the project's trend was not shown:\n%s", text)
+	}
+	text, _ = callTool(t, later.guard(later.handleCheckPace), "dojo.check_pace", map[string]interface{}{"session_description": "another project", "project": "Hermes"})
+	if !strings.Contains(text, "No self-assessments yet") {
+		t.Fatalf("another project's trend was shown:\n%s", text)
+	}
+
+	history, err := later.recentSelfAssessments("Atlas", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != maxStoredSelfAssessments {
+		t.Fatalf("stored %d self-assessments, want %d", len(history), maxStoredSelfAssessments)
+	}
+}
@@ -0,0 +1,625 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/packet"
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// conflictPolicies are the ways an import treats items that already exist
+// locally with different content: keep the local item, replace it, or keep
+// both by giving the imported item a new identity
+var conflictPolicies = []string{"skip", "overwrite", "rename"}
+
+// Import actions reported for each item of a packet
+const (
+	importAdd       = "add"
+	importUnchanged = "unchanged"
+	importSkip      = "skip"
+	importOverwrite = "overwrite"
+	importRename    = "rename"
+	importFail      = "fail"
+)
+
+// ImportItem is what an import did, or would do, with one item of a packet
+type ImportItem struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Action    string `json:"action"`
+	RenamedTo string `json:"renamed_to,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// ImportReport describes the outcome of importing a packet. A dry run
+// reports what would happen without changing anything.
+type ImportReport struct {
+	Project    string         `json:"project"`
+	DryRun     bool           `json:"dry_run"`
+	OnConflict string         `json:"on_conflict"`
+	Items      []ImportItem   `json:"items"`
+	Counts     map[string]int `json:"counts"`
+	Warnings   []string       `json:"warnings"`
+}
+
+// Summary describes the report in one line
+func (r ImportReport) Summary() string {
+	actions := make([]string, 0, len(r.Counts))
+	for action := range r.Counts {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	parts := make([]string, 0, len(actions))
+	for _, action := range actions {
+		parts = append(parts, fmt.Sprintf("%d %s", r.Counts[action], action))
+	}
+	verb := "Imported"
+	if r.DryRun {
+		verb = "Dry run of importing"
+	}
+	return fmt.Sprintf("%s packet into project %s: %s", verb, r.Project, strings.Join(parts, ", "))
+}
+
+func (r *ImportReport) note(item ImportItem) {
+	r.Items = append(r.Items, item)
+	r.Counts[item.Action]++
+}
+
+// ImportPacketFile restores a DojoPacket file, JSON or zip, into the store.
+// See importPacket for how conflicts are handled.
+func (h *Handler) ImportPacketFile(path, onConflict string, dryRun bool) (ImportReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImportReport{}, fmt.Errorf("failed to read packet: %w", err)
+	}
+	pkt, err := packet.Read(data)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	return h.importPacket(context.Background(), pkt, "", onConflict, dryRun)
+}
+
+// importPacket restores a packet's rooms, insights, applied seeds, traces,
+// memory digests and snapshots into the project it names, or into the
+// project called into when given. Items identical to local ones are left
+// alone; items that share an identity with a local item but differ are
+// handled by the conflict policy. An import either saves everything or, by
+// undoing the steps already saved, nothing.
+func (h *Handler) importPacket(ctx context.Context, pkt packet.Packet, into, onConflict string, dryRun bool) (ImportReport, error) {
+	if onConflict == "" {
+		onConflict = "skip"
+	}
+	if !contains(conflictPolicies, onConflict) {
+		return ImportReport{}, fmt.Errorf("on_conflict must be one of: %s", strings.Join(conflictPolicies, ", "))
+	}
+	name := strings.TrimSpace(into)
+	if name == "" {
+		name = pkt.Project.Name
+	}
+
+	// Work on a copy of the journal, committed only when not a dry run
+	existing, exists := h.projects.get(name)
+	target := existing
+	if !exists {
+		target = project{ID: projectID(name), Name: name, Description: pkt.Project.Description, CreatedAt: pkt.Project.CreatedAt}
+	}
+	report := ImportReport{Project: target.ID, DryRun: dryRun, OnConflict: onConflict, Items: []ImportItem{}, Counts: map[string]int{}, Warnings: []string{}}
+
+	if exists && pkt.Project.Description != "" && pkt.Project.Description != target.Description {
+		item := ImportItem{Kind: "project", ID: target.ID, Name: name, Action: importSkip, Reason: "local description kept"}
+		if onConflict == "overwrite" {
+			target.Description = pkt.Project.Description
+			item.Action, item.Reason = importOverwrite, ""
+		}
+		report.note(item)
+	}
+
+	checklists := map[string][]string{}
+	checklistArtifacts := []packet.Artifact{}
+	insights := []wisdom.Insight{}
+	for _, artifact := range pkt.Artifacts {
+		switch artifact.Type {
+		case "thinking_room":
+			h.importRoom(&target, artifact, onConflict, &report)
+		case "checklist":
+			var content checklistContent
+			if err := json.Unmarshal(artifact.Content, &content); err != nil {
+				report.note(ImportItem{Kind: artifact.Type, ID: artifact.ID, Name: artifact.Name, Action: importFail, Reason: err.Error()})
+				continue
+			}
+			checklists[content.Seed] = content.Items
+			checklistArtifacts = append(checklistArtifacts, artifact)
+		case "insight":
+			if insight, ok := h.importInsight(&target, artifact, onConflict, &report); ok {
+				insights = append(insights, insight)
+			}
+		}
+	}
+
+	for _, use := range pkt.Memory.Seeds {
+		h.importSeedUse(&target, appliedSeed{SeedUse: use, Checklist: checklists[use.Name]}, onConflict, &report)
+	}
+	// Checklists are restored with the seed applications they belong to
+	for _, artifact := range checklistArtifacts {
+		item := ImportItem{Kind: artifact.Type, ID: artifact.ID, Name: artifact.Name, Action: importUnchanged}
+		for _, applied := range report.Items {
+			if applied.Kind == "seed" && applied.Name == artifact.Name && applied.Action != importUnchanged && applied.Action != importSkip {
+				item.Action, item.Reason = importAdd, "restored with the seed applications it belongs to"
+			}
+		}
+		report.note(item)
+	}
+	for _, session := range pkt.Trace.Sessions {
+		importSession(&target, session, onConflict, &report)
+	}
+	if err := h.refreshMemory(); err != nil {
+		return report, err
+	}
+	_, memoryChanged := importDigests(h.memory.memoriesOf(target.ID), pkt.Memory.CompressedHistory, onConflict, &report)
+	snapshots := h.importSnapshots(target.ID, pkt.Memory.Snapshots, onConflict, &report)
+
+	if dryRun {
+		return report, nil
+	}
+
+	// Each step saved is undone, newest first, should a later one fail, so
+	// a failed import leaves nothing behind. Imported insights join the
+	// wisdom base only once every step is saved.
+	var undo []func() error
+	fail := func(err error) (ImportReport, error) {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](); undoErr != nil {
+				return report, fmt.Errorf("%w; undoing the import also failed, so it may be partly applied: %v", err, undoErr)
+			}
+		}
+		return report, fmt.Errorf("%w; nothing was imported", err)
+	}
+
+	if len(insights) > 0 {
+		var previous []wisdom.Insight
+		if err := h.updateInsightsDocument(ctx, func(stored []wisdom.Insight) ([]wisdom.Insight, error) {
+			previous = stored
+			return mergeInsights(stored, insights), nil
+		}); err != nil {
+			return fail(err)
+		}
+		undo = append(undo, func() error {
+			return h.updateInsightsDocument(ctx, func(stored []wisdom.Insight) ([]wisdom.Insight, error) {
+				return unmergeInsights(stored, previous, insights), nil
+			})
+		})
+	}
+
+	if err := h.updateProjects(ctx, func() error {
+		h.projects.replace(target)
+		return nil
+	}); err != nil {
+		return fail(err)
+	}
+	undo = append(undo, func() error {
+		return h.updateProjects(ctx, func() error {
+			if exists {
+				h.projects.replace(existing)
+			} else {
+				h.projects.remove(target.ID)
+			}
+			return nil
+		})
+	})
+
+	if memoryChanged {
+		// Merged again under the store's lock, in case memories changed
+		// since the report was made
+		var previous []memory
+		if err := h.updateMemory(ctx, func() error {
+			previous = h.memory.memoriesOf(target.ID)
+			memories, _ := importDigests(previous, pkt.Memory.CompressedHistory, onConflict, &ImportReport{Counts: map[string]int{}})
+			h.memory.setMemories(target.ID, memories)
+			return nil
+		}); err != nil {
+			return fail(err)
+		}
+		undo = append(undo, func() error {
+			return h.updateMemory(ctx, func() error {
+				h.memory.setMemories(target.ID, previous)
+				return nil
+			})
+		})
+	}
+
+	if len(snapshots) > 0 {
+		if err := h.updateSnapshots(ctx, func() error {
+			pinned := h.memory.digestSnapshots()
+			for _, s := range snapshots {
+				h.snapshots.add(s, pinned)
+			}
+			return nil
+		}); err != nil {
+			return fail(err)
+		}
+	}
+
+	for _, insight := range insights {
+		if err := h.wisdomBase.AddInsight(insight); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// unmergeInsights takes imported insights back out of recorded ones,
+// returning those they replaced to how they were before
+func unmergeInsights(recorded, before, imported []wisdom.Insight) []wisdom.Insight {
+	restored := []wisdom.Insight{}
+	for _, insight := range recorded {
+		if !containsInsight(imported, insight.ID) {
+			restored = append(restored, insight)
+			continue
+		}
+		for _, previous := range before {
+			if previous.ID == insight.ID {
+				restored = append(restored, previous)
+			}
+		}
+	}
+	return restored
+}
+
+// containsInsight reports whether an insight with the ID is in the list
+func containsInsight(insights []wisdom.Insight, id string) bool {
+	for _, insight := range insights {
+		if insight.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeInsights returns recorded insights with imported ones added,
+// replacing any recorded insight with the same ID
+func mergeInsights(recorded, imported []wisdom.Insight) []wisdom.Insight {
+	merged := append([]wisdom.Insight{}, recorded...)
+	for _, insight := range imported {
+		replaced := false
+		for i, existing := range merged {
+			if existing.ID == insight.ID {
+				merged[i] = insight
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, insight)
+		}
+	}
+	return merged
+}
+
+// importRoom merges a thinking room artifact into a project
+func (h *Handler) importRoom(p *project, artifact packet.Artifact, onConflict string, report *ImportReport) {
+	item := ImportItem{Kind: artifact.Type, ID: artifact.ID, Name: artifact.Name}
+	var r projectRoom
+	if err := json.Unmarshal(artifact.Content, &r); err != nil || strings.TrimSpace(r.Topic) == "" {
+		item.Action, item.Reason = importFail, "room content needs a topic"
+		report.note(item)
+		return
+	}
+	r.ID, r.Version = artifact.ID, artifact.Version
+
+	for i, local := range p.Rooms {
+		if local.ID != r.ID && !strings.EqualFold(strings.TrimSpace(local.Topic), strings.TrimSpace(r.Topic)) {
+			continue
+		}
+		switch {
+		case local == r:
+			item.Action = importUnchanged
+		case onConflict == "overwrite":
+			p.Rooms[i] = r
+			item.Action = importOverwrite
+		case onConflict == "rename":
+			r.ID = newID("room")
+			r.Topic = uniqueTopic(p.Rooms, r.Topic)
+			p.Rooms = append(p.Rooms, r)
+			item.Action, item.RenamedTo = importRename, r.Topic
+		default:
+			item.Action, item.Reason = importSkip, "a room on this topic already exists"
+		}
+		report.note(item)
+		return
+	}
+	p.Rooms = append(p.Rooms, r)
+	item.Action = importAdd
+	report.note(item)
+}
+
+// uniqueTopic marks an imported room's topic so it does not collide with
+// the project's rooms
+func uniqueTopic(rooms []projectRoom, topic string) string {
+	taken := func(candidate string) bool {
+		for _, r := range rooms {
+			if strings.EqualFold(strings.TrimSpace(r.Topic), candidate) {
+				return true
+			}
+		}
+		return false
+	}
+	candidate := topic + " (imported)"
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s (imported %d)", topic, n)
+	}
+	return candidate
+}
+
+// importInsight checks an insight artifact against the wisdom base and
+// notes it in the project, returning the insight to add when there is one
+func (h *Handler) importInsight(p *project, artifact packet.Artifact, onConflict string, report *ImportReport) (wisdom.Insight, bool) {
+	item := ImportItem{Kind: artifact.Type, ID: artifact.ID, Name: artifact.Name}
+	var insight wisdom.Insight
+	if err := json.Unmarshal(artifact.Content, &insight); err != nil {
+		item.Action, item.Reason = importFail, err.Error()
+		report.note(item)
+		return wisdom.Insight{}, false
+	}
+	insight.ID = artifact.ID
+	if err := h.wisdomBase.CheckInsight(insight); err != nil {
+		item.Action, item.Reason = importFail, err.Error()
+		report.note(item)
+		return wisdom.Insight{}, false
+	}
+
+	item.Action = importAdd
+	if local, err := h.wisdomBase.GetInsight(insight.ID); err == nil {
+		switch {
+		case reflect.DeepEqual(normalizeInsight(*local), normalizeInsight(insight)):
+			item.Action = importUnchanged
+		case onConflict == "overwrite":
+			item.Action = importOverwrite
+		case onConflict == "rename":
+			insight.ID = newID("insight")
+			item.Action, item.RenamedTo = importRename, insight.ID
+		default:
+			item.Action, item.Reason = importSkip, "an insight with this ID already exists"
+		}
+	}
+	report.note(item)
+
+	if item.Action == importSkip {
+		return wisdom.Insight{}, false
+	}
+	found := false
+	for i, recorded := range p.Insights {
+		if recorded.ID == insight.ID {
+			p.Insights[i].Version = artifact.Version
+			found = true
+		}
+	}
+	if !found {
+		p.Insights = append(p.Insights, projectInsight{ID: insight.ID, Version: artifact.Version})
+	}
+	return insight, item.Action != importUnchanged
+}
+
+// normalizeInsight makes insights comparable after a round trip through JSON
+func normalizeInsight(insight wisdom.Insight) wisdom.Insight {
+	insight.CreatedAt = insight.CreatedAt.UTC()
+	insight.UpdatedAt = insight.UpdatedAt.UTC()
+	if len(insight.Credits) == 0 {
+		insight.Credits = nil
+	}
+	return insight
+}
+
+// importSeedUse merges one seed application into a project. Applications
+// are identified by seed and time.
+func (h *Handler) importSeedUse(p *project, applied appliedSeed, onConflict string, report *ImportReport) {
+	item := ImportItem{Kind: "seed", ID: fmt.Sprintf("%s@%s", applied.Name, applied.AppliedAt.UTC().Format(time.RFC3339Nano)), Name: applied.Name}
+	if _, err := h.wisdomBase.GetSeedVersion(applied.Name, applied.Version); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("seed %s %s (%s) is not available here: %v", applied.Name, applied.Version, applied.Hash, err))
+	}
+
+	for i, local := range p.Seeds {
+		if local.Name != applied.Name || !local.AppliedAt.Equal(applied.AppliedAt) {
+			continue
+		}
+		switch {
+		case local.SeedUse == applied.SeedUse && strings.Join(local.Checklist, "\n") == strings.Join(applied.Checklist, "\n"):
+			item.Action = importUnchanged
+		case onConflict == "overwrite":
+			p.Seeds[i] = applied
+			item.Action = importOverwrite
+		case onConflict == "rename":
+			p.Seeds = append(p.Seeds, applied)
+			item.Action, item.Reason = importRename, "kept alongside the local application"
+		default:
+			item.Action, item.Reason = importSkip, "this application is already recorded"
+		}
+		report.note(item)
+		return
+	}
+	p.Seeds = append(p.Seeds, applied)
+	item.Action = importAdd
+	report.note(item)
+}
+
+// importDigests merges the digests of a packet's compressed history into a
+// project's memories, returning the merged memories and whether they changed
+func importDigests(memories []memory, history []json.RawMessage, onConflict string, report *ImportReport) ([]memory, bool) {
+	changed := false
+	for _, data := range history {
+		var digest memory
+		if err := json.Unmarshal(data, &digest); err != nil || digest.ID == "" || digest.Digest == nil {
+			report.note(ImportItem{Kind: "digest", Action: importFail, Reason: "compressed history entries must be digests with an id"})
+			continue
+		}
+		item := ImportItem{Kind: "digest", ID: digest.ID}
+		found := false
+		for i, local := range memories {
+			if local.ID != digest.ID {
+				continue
+			}
+			found = true
+			localJSON, _ := json.Marshal(local)
+			digestJSON, _ := json.Marshal(digest)
+			switch {
+			case string(localJSON) == string(digestJSON):
+				item.Action = importUnchanged
+			case onConflict == "overwrite":
+				memories[i] = digest
+				item.Action = importOverwrite
+			case onConflict == "rename":
+				digest.ID = newID("digest")
+				memories = append(memories, digest)
+				item.Action, item.RenamedTo = importRename, digest.ID
+			default:
+				item.Action, item.Reason = importSkip, "a memory with this ID already exists"
+			}
+			break
+		}
+		if !found {
+			memories = append(memories, digest)
+			item.Action = importAdd
+		}
+		if item.Action != importUnchanged && item.Action != importSkip {
+			changed = true
+		}
+		report.note(item)
+	}
+	return memories, changed
+}
+
+// importSnapshots checks a packet's snapshots against their hashes and
+// returns those to keep for a project. Snapshots are immutable, so one that
+// differs from a local snapshot with the same ID is never overwritten.
+func (h *Handler) importSnapshots(id string, snapshots []json.RawMessage, onConflict string, report *ImportReport) []memorySnapshot {
+	added := []memorySnapshot{}
+	for _, data := range snapshots {
+		var s memorySnapshot
+		if err := json.Unmarshal(data, &s); err != nil || s.ID == "" {
+			report.note(ImportItem{Kind: "snapshot", Action: importFail, Reason: "snapshots must be objects with an id"})
+			continue
+		}
+		item := ImportItem{Kind: "snapshot", ID: s.ID, Name: s.Label}
+		if s.contentHash() != s.Hash {
+			item.Action, item.Reason = importFail, fmt.Sprintf("contents do not match the hash %s", s.Hash)
+			report.note(item)
+			continue
+		}
+		s.Project = id
+
+		item.Action = importAdd
+		if local, ok := h.snapshots.get(id, s.ID); ok {
+			switch {
+			case local.Hash == s.Hash:
+				item.Action = importUnchanged
+			case onConflict == "rename":
+				s.ID = newID("snap")
+				item.Action, item.RenamedTo = importRename, s.ID
+			default:
+				item.Action, item.Reason = importSkip, "a different snapshot with this ID exists, and snapshots are immutable"
+			}
+		}
+		if item.Action == importAdd || item.Action == importRename {
+			added = append(added, s)
+		}
+		report.note(item)
+	}
+	return added
+}
+
+// importSession merges a traced session into a project
+func importSession(p *project, session packet.Session, onConflict string, report *ImportReport) {
+	item := ImportItem{Kind: "session", ID: session.ID}
+	for i, local := range p.Sessions {
+		if local.ID != session.ID {
+			continue
+		}
+		switch {
+		case reflect.DeepEqual(local, session):
+			item.Action = importUnchanged
+		case onConflict == "overwrite":
+			p.Sessions[i] = session
+			item.Action = importOverwrite
+		case onConflict == "rename":
+			session.ID = uniqueSessionID(p.Sessions, session.ID)
+			p.Sessions = append(p.Sessions, session)
+			item.Action, item.RenamedTo = importRename, session.ID
+		default:
+			item.Action, item.Reason = importSkip, "a session with this ID is already traced"
+		}
+		report.note(item)
+		return
+	}
+	p.Sessions = append(p.Sessions, session)
+	item.Action = importAdd
+	report.note(item)
+}
+
+// uniqueSessionID marks an imported session's ID so it does not collide
+// with the project's sessions
+func uniqueSessionID(sessions []packet.Session, id string) string {
+	taken := func(candidate string) bool {
+		for _, s := range sessions {
+			if s.ID == candidate {
+				return true
+			}
+		}
+		return false
+	}
+	candidate := id + "-imported"
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s-imported-%d", id, n)
+	}
+	return candidate
+}
+
+func (h *Handler) handleImportPacket(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Path       string `json:"path"`
+		Packet     string `json:"packet"`
+		Project    string `json:"project"`
+		OnConflict string `json:"on_conflict"`
+		DryRun     bool   `json:"dry_run"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if (args.Path == "") == (args.Packet == "") {
+		return mcp.NewToolResultError("Invalid arguments: give either the path of a packet file or the packet itself"), nil
+	}
+	if args.OnConflict != "" && !contains(conflictPolicies, args.OnConflict) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: on_conflict must be one of: %s", strings.Join(conflictPolicies, ", "))), nil
+	}
+
+	data := []byte(args.Packet)
+	if args.Path != "" {
+		var err error
+		if data, err = h.readFile(args.Path); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not read the packet: %v", err)), nil
+		}
+	}
+	pkt, err := packet.Read(data)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	report, err := h.importPacket(ctx, pkt, args.Project, args.OnConflict, args.DryRun)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Import failed: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"summary": report.Summary(),
+		"report":  report,
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
@@ -0,0 +1,197 @@
+package dojo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/packet"
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestImportPacketValidatesConflictPolicyFirst(t *testing.T) {
+	h := newTestHandler(t)
+	text, isError := callTool(t, h.guard(h.handleImportPacket), "dojo.import_packet", map[string]interface{}{
+		"path":        "packets/missing.json",
+		"on_conflict": "merge",
+	})
+	if !isError || !strings.Contains(text, "on_conflict must be one of") {
+		t.Fatalf("invalid on_conflict was not refused before reading: %q", text)
+	}
+}
+
+func TestImportPacketReadsOnlyFromTheWorkspace(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "packet.json")
+	if err := os.WriteFile(outside, []byte(`{"not":"a packet"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := newTestHandler(t)
+	for _, path := range []string{outside, "../" + filepath.Base(filepath.Dir(outside)) + "/packet.json"} {
+		text, isError := callTool(t, h.guard(h.handleImportPacket), "dojo.import_packet", map[string]interface{}{"path": path})
+		if !isError || !strings.Contains(text, "Could not read the packet") {
+			t.Fatalf("packet outside the workspace at %s was read: %q", path, text)
+		}
+	}
+}
+
+// exportAtlas builds a project with a room, an insight and a compressed
+// memory, and returns it exported as a JSON packet
+func exportAtlas(t *testing.T) string {
+	t.Helper()
+	h := newTestHandler(t)
+	for _, step := range []struct {
+		name    string
+		handler server.ToolHandlerFunc
+		args    map[string]interface{}
+	}{
+		{"dojo.create_thinking_room", h.handleCreateThinkingRoom, map[string]interface{}{"topic": "Caching strategy", "project": "Atlas"}},
+		{"dojo.record_insight", h.handleRecordInsight, map[string]interface{}{"title": "Cache the lineage graph", "body": "Caching the lineage graph halves startup time.", "author": "Ana", "project": "Atlas"}},
+		{"dojo.remember", h.handleRemember, map[string]interface{}{"project": "Atlas", "content": "The cache must be invalidated when seeds change."}},
+	} {
+		if text, isError := callTool(t, h.guard(step.handler), step.name, step.args); isError {
+			t.Fatalf("%s failed: %s", step.name, text)
+		}
+	}
+	later := time.Now().Add(30 * 24 * time.Hour)
+	h.memory.now = func() time.Time { return later }
+	if text, isError := callTool(t, h.guard(h.handleCompressMemory), "dojo.compress_memory", map[string]interface{}{"project": "Atlas"}); isError {
+		t.Fatalf("compress failed: %s", text)
+	}
+
+	text, isError := callTool(t, h.handleExportPacket, "dojo.export_packet", map[string]interface{}{"project": "Atlas"})
+	if isError {
+		t.Fatalf("export failed: %s", text)
+	}
+	var exported struct {
+		Packet json.RawMessage `json:"packet"`
+	}
+	if err := json.Unmarshal([]byte(text), &exported); err != nil {
+		t.Fatal(err)
+	}
+	return string(exported.Packet)
+}
+
+func TestFailedImportLeavesNothingBehind(t *testing.T) {
+	pkt := exportAtlas(t)
+
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Snapshots are saved last; a directory in their place makes that fail
+	if err := os.Mkdir(filepath.Join(dir, "snapshots.json"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithStore(st))
+
+	text, isError := callTool(t, h.guard(h.handleImportPacket), "dojo.import_packet", map[string]interface{}{"packet": pkt})
+	if !isError || !strings.Contains(text, "nothing was imported") {
+		t.Fatalf("the import did not fail cleanly: %s", text)
+	}
+
+	if _, ok := h.projects.get("Atlas"); ok {
+		t.Fatal("the project was kept in memory")
+	}
+	if insights := h.wisdomBase.ListInsights(); len(insights) != 0 {
+		t.Fatalf("the insight joined the wisdom base: %+v", insights)
+	}
+	if memories := h.memory.memoriesOf("proj_atlas"); len(memories) != 0 {
+		t.Fatalf("memories were kept: %+v", memories)
+	}
+
+	reader, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var projects []project
+	var byProject map[string][]memory
+	var insights []wisdom.Insight
+	for name, v := range map[string]interface{}{projectsDocument: &projects, memoryDocument: &byProject, insightsDocument: &insights} {
+		if _, err := reader.Load(name, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(projects) != 0 || len(byProject) != 0 || len(insights) != 0 {
+		t.Fatalf("the store kept part of the import: projects %v, memory %v, insights %v", projects, byProject, insights)
+	}
+}
+
+func TestExportedPacketImportsWhole(t *testing.T) {
+	pkt := exportAtlas(t)
+	h := newTestHandler(t)
+
+	importPacket := func() ImportReport {
+		t.Helper()
+		text, isError := callTool(t, h.handleImportPacket, "dojo.import_packet", map[string]interface{}{"packet": pkt})
+		if isError {
+			t.Fatalf("import failed: %s", text)
+		}
+		var result struct {
+			Report ImportReport `json:"report"`
+		}
+		if err := json.Unmarshal([]byte(text), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result.Report
+	}
+
+	report := importPacket()
+	if report.Counts[importFail] != 0 || report.Counts[importAdd] == 0 {
+		t.Fatalf("unexpected import: %+v", report)
+	}
+	p, ok := h.projects.get("Atlas")
+	if !ok || len(p.Rooms) != 1 || p.Rooms[0].Topic != "Caching strategy" || len(p.Insights) != 1 || len(p.Sessions) == 0 {
+		t.Fatalf("imported journal %+v", p)
+	}
+	if _, err := h.wisdomBase.GetInsight(p.Insights[0].ID); err != nil {
+		t.Fatalf("the insight was not imported: %v", err)
+	}
+	digests := h.digestsOf(p.ID)
+	if len(digests) != 1 {
+		t.Fatalf("imported %d digests, want 1", len(digests))
+	}
+	if _, ok := h.snapshots.get(p.ID, digests[0].Digest.Snapshot); !ok {
+		t.Fatal("the snapshot behind the digest was not imported")
+	}
+
+	// Exported again, the project makes the same packet
+	text, isError := callTool(t, h.handleExportPacket, "dojo.export_packet", map[string]interface{}{"project": "Atlas"})
+	if isError {
+		t.Fatalf("export failed: %s", text)
+	}
+	var again struct {
+		Packet packet.Packet `json:"packet"`
+	}
+	if err := json.Unmarshal([]byte(text), &again); err != nil {
+		t.Fatal(err)
+	}
+	var first packet.Packet
+	if err := json.Unmarshal([]byte(pkt), &first); err != nil {
+		t.Fatal(err)
+	}
+	artifacts := func(pkt packet.Packet) string {
+		ids := []string{}
+		for _, artifact := range pkt.Artifacts {
+			ids = append(ids, artifact.Type+":"+artifact.ID)
+		}
+		return strings.Join(ids, " ")
+	}
+	if artifacts(again.Packet) != artifacts(first) || len(again.Packet.Memory.CompressedHistory) != 1 || len(again.Packet.Memory.Snapshots) != 1 {
+		t.Fatalf("the round trip changed the packet:\nexported %s\nre-exported %s", artifacts(first), artifacts(again.Packet))
+	}
+
+	// Importing it a second time changes nothing
+	report = importPacket()
+	for action, n := range report.Counts {
+		if action != importUnchanged && n > 0 {
+			t.Fatalf("a repeated import did something: %+v", report.Items)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package dojo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/packet"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// packetFormats are the output formats of dojo.export_packet
+var packetFormats = []string{"json", "zip"}
+
+// packetGenerator names this server in exported packets
+const packetGenerator = "dojo-mcp-server"
+
+// checklistContent is the artifact content of a seed's checklist
+type checklistContent struct {
+	Seed        string   `json:"seed"`
+	SeedVersion string   `json:"seed_version"`
+	SeedHash    string   `json:"seed_hash"`
+	Items       []string `json:"items"`
+	Situations  []string `json:"situations"`
+}
+
+// buildPacket gathers a project's journal, memory digests and snapshots
+// into a DojoPacket. With files it
+// also renders each artifact as Markdown for a zip packet, returning the
+// rendered files by export path.
+func (h *Handler) buildPacket(p project, files bool) (packet.Packet, map[string][]byte, error) {
+	pkt := packet.Packet{
+		Version: packet.Version,
+		Project: packet.Project{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			CreatedAt:   p.CreatedAt,
+		},
+		Artifacts: []packet.Artifact{},
+		Memory: packet.Memory{
+			Seeds:             []packet.SeedUse{},
+			CompressedHistory: []json.RawMessage{},
+			Snapshots:         []json.RawMessage{},
+		},
+		Trace: packet.Trace{Sessions: p.Sessions},
+	}
+	rendered := map[string][]byte{}
+
+	add := func(id, kind, name string, version int, content interface{}, path, markdown string) error {
+		contentJSON, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("failed to encode artifact %s: %w", id, err)
+		}
+		artifact := packet.Artifact{ID: id, Type: kind, Name: name, Version: version, Content: contentJSON, Exports: []packet.Export{}}
+		if files {
+			artifact.Exports = append(artifact.Exports, packet.Export{Format: "markdown", Path: path})
+			rendered[path] = []byte(markdown)
+		}
+		pkt.Artifacts = append(pkt.Artifacts, artifact)
+		return nil
+	}
+
+	for _, r := range p.Rooms {
+		content := map[string]interface{}{
+			"topic":      r.Topic,
+			"agent_name": r.AgentName,
+			"created_at": r.CreatedAt,
+			"updated_at": r.UpdatedAt,
+		}
+		if err := add(r.ID, "thinking_room", r.Topic, r.Version, content, "artifacts/thinking_rooms/"+r.ID+".md", thinkingRoom(r.Topic, r.AgentName)); err != nil {
+			return packet.Packet{}, nil, err
+		}
+	}
+
+	// Each seed applied in the project contributes one checklist, whose
+	// version counts its applications
+	checklists := map[string]*checklistContent{}
+	order := []string{}
+	versions := map[string]int{}
+	for _, applied := range p.Seeds {
+		pkt.Memory.Seeds = append(pkt.Memory.Seeds, applied.SeedUse)
+		if len(applied.Checklist) == 0 {
+			continue
+		}
+		c, ok := checklists[applied.Name]
+		if !ok {
+			c = &checklistContent{Seed: applied.Name}
+			checklists[applied.Name] = c
+			order = append(order, applied.Name)
+		}
+		c.SeedVersion, c.SeedHash, c.Items = applied.Version, applied.Hash, applied.Checklist
+		c.Situations = append(c.Situations, applied.Situation)
+		versions[applied.Name]++
+	}
+	for _, name := range order {
+		c := checklists[name]
+		if err := add("checklist_"+name, "checklist", name, versions[name], c, "artifacts/checklists/"+name+".md", renderChecklist(*c)); err != nil {
+			return packet.Packet{}, nil, err
+		}
+	}
+
+	for _, recorded := range p.Insights {
+		insight, err := h.wisdomBase.GetInsight(recorded.ID)
+		if err != nil {
+			continue
+		}
+		if err := add(insight.ID, "insight", insight.Title, recorded.Version, insight, "artifacts/insights/"+insight.ID+".md", renderInsight(*insight)); err != nil {
+			return packet.Packet{}, nil, err
+		}
+	}
+
+	// Digests and snapshots travel as they are stored
+	for _, digest := range h.digestsOf(p.ID) {
+		data, err := json.Marshal(digest)
+		if err != nil {
+			return packet.Packet{}, nil, fmt.Errorf("failed to encode digest %s: %w", digest.ID, err)
+		}
+		pkt.Memory.CompressedHistory = append(pkt.Memory.CompressedHistory, data)
+	}
+	for _, s := range h.snapshots.of(p.ID) {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return packet.Packet{}, nil, fmt.Errorf("failed to encode snapshot %s: %w", s.ID, err)
+		}
+		pkt.Memory.Snapshots = append(pkt.Memory.Snapshots, data)
+	}
+
+	words := 0
+	for _, session := range p.Sessions {
+		for _, call := range session.Calls {
+			words += call.Words
+		}
+	}
+	pkt.Metadata = packet.Metadata{
+		TotalArtifacts: len(pkt.Artifacts),
+		TotalSessions:  len(pkt.Trace.Sessions),
+		TotalTokens:    estimateTokens(words),
+		ExportedAt:     time.Now().UTC(),
+		Generator:      packetGenerator,
+	}
+	return pkt, rendered, nil
+}
+
+// estimateTokens approximates the tokens in a number of English words
+func estimateTokens(words int) int {
+	return (words*4 + 2) / 3
+}
+
+// renderChecklist formats a checklist artifact as Markdown
+func renderChecklist(c checklistContent) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Checklist: %s\n\n**Seed version:** %s (%s)\n\n", c.Seed, c.SeedVersion, c.SeedHash))
+	for _, item := range c.Items {
+		text.WriteString(fmt.Sprintf("- [ ] %s\n", item))
+	}
+	text.WriteString("\n## Applied To\n\n")
+	for _, situation := range c.Situations {
+		text.WriteString(fmt.Sprintf("- %s\n", situation))
+	}
+	return text.String()
+}
+
+func (h *Handler) handleExportPacket(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project     string `json:"project"`
+		Description string `json:"description"`
+		Format      string `json:"format"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if args.Format == "" {
+		args.Format = "json"
+	}
+	if args.Format != "json" && args.Format != "zip" {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: format must be one of: %s", strings.Join(packetFormats, ", "))), nil
+	}
+	if _, ok := h.projects.get(args.Project); !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("No project named %q. Pass a project to dojo.create_thinking_room, dojo.apply_seed or dojo.record_insight to start one.", args.Project)), nil
+	}
+
+	if strings.TrimSpace(args.Description) != "" {
+		if err := h.updateProjects(ctx, func() error {
+			h.projects.describe(args.Project, strings.TrimSpace(args.Description))
+			return nil
+		}); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Could not save the project description: %v", err)), nil
+		}
+	}
+	p, _ := h.projects.get(args.Project)
+
+	pkt, files, err := h.buildPacket(p, args.Format == "zip")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name := fmt.Sprintf("packets/%s-%s.%s", p.ID, pkt.Metadata.ExportedAt.Format("20060102T150405Z"), args.Format)
+
+	result := map[string]interface{}{
+		"project":  pkt.Project,
+		"format":   args.Format,
+		"metadata": pkt.Metadata,
+	}
+
+	if args.Format == "zip" {
+		if h.workspace == nil {
+			return mcp.NewToolResultError("A zip packet needs somewhere to be written: " + errNoWorkspace.Error()), nil
+		}
+		var archive bytes.Buffer
+		if err := packet.WriteZip(&archive, pkt, files); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := h.writeFile(ctx, name, archive.Bytes()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		contents := []string{packet.ManifestName}
+		for _, artifact := range pkt.Artifacts {
+			for _, export := range artifact.Exports {
+				contents = append(contents, export.Path)
+			}
+		}
+		result["path"] = name
+		result["files"] = contents
+	} else {
+		data, err := packet.Encode(pkt)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		// Without a workspace the packet is only returned inline
+		if h.workspace != nil {
+			if err := h.writeFile(ctx, name, data); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			result["path"] = name
+		}
+		result["packet"] = json.RawMessage(data)
+	}
+
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
@@ -0,0 +1,550 @@
+package dojo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// The three files of the planning-with-files pattern
+const (
+	taskPlanFile = "task_plan.md"
+	findingsFile = "findings.md"
+	progressFile = "progress.md"
+)
+
+// phaseStatuses maps each phase status to its checkbox mark in task_plan.md
+var phaseStatuses = map[string]string{
+	"pending":     " ",
+	"in_progress": "~",
+	"complete":    "x",
+	"blocked":     "!",
+}
+
+// phaseStatusNames lists the phase statuses in the order phases move through
+var phaseStatusNames = []string{"pending", "in_progress", "complete", "blocked"}
+
+// progressKinds are the kinds of progress log entries
+var progressKinds = []string{"action", "result", "error"}
+
+// phaseLine matches a phase in the Phases section: "- [~] Design — in_progress: note"
+var phaseLine = regexp.MustCompile(`^- \[(.)\] (.+) — (pending|in_progress|complete|blocked)(?:: (.*))?$`)
+
+// progressLine matches a progress log entry: "- <time> **kind** (phase): entry"
+var progressLine = regexp.MustCompile(`^- (\S+) \*\*(action|result|error)\*\*(?: \((.+?)\))?: (.*)$`)
+
+// plans serializes changes to planning files, which are kept in a
+// directory of the workspace named by the project ID
+type plans struct {
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func newPlans() *plans {
+	return &plans{now: time.Now}
+}
+
+// planPhase is one phase of a task plan
+type planPhase struct {
+	Name   string
+	Status string
+	Note   string
+}
+
+func (p planPhase) String() string {
+	line := fmt.Sprintf("- [%s] %s — %s", phaseStatuses[p.Status], p.Name, p.Status)
+	if p.Note != "" {
+		line += ": " + p.Note
+	}
+	return line
+}
+
+// planPath returns the workspace path of a project's planning file.
+// Project IDs are slugs, so the path stays in the project's directory.
+func planPath(projectName, file string) (string, error) {
+	if strings.TrimSpace(projectName) == "" {
+		return "", errors.New("project is required")
+	}
+	return projectID(projectName) + "/" + file, nil
+}
+
+func (h *Handler) readPlanFile(projectName, file string) (string, error) {
+	path, err := planPath(projectName, file)
+	if err != nil {
+		return "", err
+	}
+	data, err := h.readFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("project %s has no plan yet; start one with dojo.plan_init", projectName)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (h *Handler) writePlanFile(ctx context.Context, projectName, file, content string) error {
+	path, err := planPath(projectName, file)
+	if err != nil {
+		return err
+	}
+	return h.writeFile(ctx, path, []byte(strings.TrimRight(content, "\n")+"\n"))
+}
+
+// stamp is the time format used in planning files
+func (pl *plans) stamp() string {
+	return pl.now().UTC().Format(time.RFC3339)
+}
+
+// planSection locates a "##" section by title, case-insensitively,
+// returning the line range of its body
+func planSection(lines []string, title string) (int, int, bool) {
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") || !strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(line, "## ")), strings.TrimSpace(title)) {
+			continue
+		}
+		end := len(lines)
+		for j := i + 1; j < len(lines); j++ {
+			if strings.HasPrefix(lines[j], "## ") || strings.HasPrefix(lines[j], "# ") {
+				end = j
+				break
+			}
+		}
+		return i + 1, end, true
+	}
+	return 0, 0, false
+}
+
+// sectionBody returns the text of a "##" section without its heading
+func sectionBody(content, title string) string {
+	lines := strings.Split(content, "\n")
+	start, end, ok := planSection(lines, title)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(lines[start:end], "\n"))
+}
+
+// sectionTitles lists the titles of the "##" sections of a file
+func sectionTitles(content string) []string {
+	titles := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			titles = append(titles, strings.TrimSpace(strings.TrimPrefix(line, "## ")))
+		}
+	}
+	return titles
+}
+
+// replaceSection swaps the body of a "##" section, appending the section
+// when the file does not have it, and leaves the rest of the file as written
+func replaceSection(content, title, body string) string {
+	lines := strings.Split(content, "\n")
+	start, end, ok := planSection(lines, title)
+	if !ok {
+		return strings.TrimRight(content, "\n") + "\n\n## " + title + "\n\n" + strings.TrimSpace(body) + "\n"
+	}
+	replaced := append([]string{}, lines[:start]...)
+	replaced = append(replaced, "", strings.TrimSpace(body), "")
+	return strings.Join(append(replaced, lines[end:]...), "\n")
+}
+
+// parsePhases reads the phases of a task plan
+func parsePhases(content string) []planPhase {
+	phases := []planPhase{}
+	for _, line := range strings.Split(sectionBody(content, "Phases"), "\n") {
+		if m := phaseLine.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			phases = append(phases, planPhase{Name: m[2], Status: m[3], Note: m[4]})
+		}
+	}
+	return phases
+}
+
+func renderPhases(phases []planPhase) string {
+	lines := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		lines = append(lines, phase.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// currentPhase is the first phase in progress, or else the first pending one
+func currentPhase(phases []planPhase) (planPhase, bool) {
+	for _, status := range []string{"in_progress", "pending"} {
+		for _, phase := range phases {
+			if phase.Status == status {
+				return phase, true
+			}
+		}
+	}
+	return planPhase{}, false
+}
+
+// findPhase looks a phase up by name, case-insensitively, or by its
+// 1-based number
+func findPhase(phases []planPhase, ref string) int {
+	ref = strings.TrimSpace(ref)
+	for i, phase := range phases {
+		if strings.EqualFold(phase.Name, ref) || fmt.Sprint(i+1) == ref {
+			return i
+		}
+	}
+	return -1
+}
+
+// listItems returns the "- " items of a section
+func listItems(content, title string) []string {
+	items := []string{}
+	for _, line := range strings.Split(sectionBody(content, title), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			items = append(items, strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		}
+	}
+	return items
+}
+
+// appendProgress adds an entry to a project's progress log
+func (h *Handler) appendProgress(ctx context.Context, projectName, kind, phase, entry string) error {
+	content, err := h.readPlanFile(projectName, progressFile)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("- %s **%s**", h.plans.stamp(), kind)
+	if phase != "" {
+		line += fmt.Sprintf(" (%s)", phase)
+	}
+	line += ": " + strings.Join(strings.Fields(entry), " ")
+	return h.writePlanFile(ctx, projectName, progressFile, strings.TrimRight(content, "\n")+"\n"+line)
+}
+
+func (h *Handler) handlePlanInit(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project   string   `json:"project"`
+		Goal      string   `json:"goal"`
+		Phases    []string `json:"phases"`
+		Overwrite bool     `json:"overwrite"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Goal) == "" {
+		return mcp.NewToolResultError("Invalid arguments: a plan needs a goal"), nil
+	}
+
+	phases := []planPhase{}
+	for _, name := range args.Phases {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" {
+			continue
+		}
+		if findPhase(phases, name) >= 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: phase %q is listed twice", name)), nil
+		}
+		phases = append(phases, planPhase{Name: name, Status: "pending"})
+	}
+	if len(phases) == 0 {
+		return mcp.NewToolResultError("Invalid arguments: a plan needs at least one non-empty phase"), nil
+	}
+	phases[0].Status = "in_progress"
+
+	h.plans.mu.Lock()
+	defer h.plans.mu.Unlock()
+
+	path, err := planPath(args.Project, taskPlanFile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if h.workspace == nil {
+		return mcp.NewToolResultError(errNoWorkspace.Error()), nil
+	}
+	if h.workspace.Exists(path) && !args.Overwrite {
+		return mcp.NewToolResultError(fmt.Sprintf("Project %s already has a plan. Use dojo.plan_recover to pick it up, or pass overwrite to start again.", args.Project)), nil
+	}
+
+	header := fmt.Sprintf("**Project:** %s\n**Created:** %s\n", strings.TrimSpace(args.Project), h.plans.stamp())
+	files := []struct{ name, content string }{
+		{taskPlanFile, fmt.Sprintf("# Task Plan\n\n%s\n## Goal\n\n%s\n\n## Phases\n\n%s\n\n## Decisions\n\n", header, strings.TrimSpace(args.Goal), renderPhases(phases))},
+		{findingsFile, fmt.Sprintf("# Findings\n\n%s", header)},
+		{progressFile, fmt.Sprintf("# Progress\n\n%s\n## Log\n\n- %s **action** (%s): Plan created\n", header, h.plans.stamp(), phases[0].Name)},
+	}
+	for _, f := range files {
+		if err := h.writePlanFile(ctx, args.Project, f.name, f.content); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf(`# Plan Started: %s
+
+Created %s, %s and %s in %s/.
+
+## Phases
+
+%s
+
+Update phases with `+"`dojo.plan_update_phase`"+`, keep research in findings with `+"`dojo.add_finding`"+`, and log what you try with `+"`dojo.log_progress`"+`. After a context reset, `+"`dojo.plan_recover`"+` rebuilds where you were.`, strings.TrimSpace(args.Project), taskPlanFile, findingsFile, progressFile, projectID(args.Project), renderPhases(phases))), nil
+}
+
+func (h *Handler) handlePlanUpdatePhase(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project  string `json:"project"`
+		Phase    string `json:"phase"`
+		Status   string `json:"status"`
+		Note     string `json:"note"`
+		Decision string `json:"decision"`
+		Add      bool   `json:"add"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if _, ok := phaseStatuses[args.Status]; !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: status must be one of: %s", strings.Join(phaseStatusNames, ", "))), nil
+	}
+
+	h.plans.mu.Lock()
+	defer h.plans.mu.Unlock()
+
+	content, err := h.readPlanFile(args.Project, taskPlanFile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	phases := parsePhases(content)
+	i := findPhase(phases, args.Phase)
+	switch {
+	case i < 0 && args.Add:
+		phases = append(phases, planPhase{Name: strings.Join(strings.Fields(args.Phase), " ")})
+		i = len(phases) - 1
+	case i < 0:
+		names := make([]string, 0, len(phases))
+		for _, phase := range phases {
+			names = append(names, phase.Name)
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("No phase %q in the plan (phases: %s). Pass add to add it.", args.Phase, strings.Join(names, ", "))), nil
+	}
+	previous := phases[i].Status
+	phases[i].Status = args.Status
+	phases[i].Note = strings.Join(strings.Fields(args.Note), " ")
+
+	content = replaceSection(content, "Phases", renderPhases(phases))
+	if strings.TrimSpace(args.Decision) != "" {
+		decisions := append(listItems(content, "Decisions"), fmt.Sprintf("%s (%s): %s", h.plans.stamp(), phases[i].Name, strings.Join(strings.Fields(args.Decision), " ")))
+		content = replaceSection(content, "Decisions", "- "+strings.Join(decisions, "\n- "))
+	}
+	if err := h.writePlanFile(ctx, args.Project, taskPlanFile, content); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entry := fmt.Sprintf("Phase %s: %s → %s", phases[i].Name, previous, args.Status)
+	if previous == "" {
+		entry = fmt.Sprintf("Phase %s added as %s", phases[i].Name, args.Status)
+	}
+	if err := h.appendProgress(ctx, args.Project, "action", phases[i].Name, entry); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	next := "Every phase is complete."
+	if current, ok := currentPhase(phases); ok {
+		next = fmt.Sprintf("Current phase: **%s** (%s).", current.Name, current.Status)
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Updated %s: %s.\n\n## Phases\n\n%s\n\n%s", taskPlanFile, entry, renderPhases(phases), next)), nil
+}
+
+func (h *Handler) handleAddFinding(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project  string `json:"project"`
+		Title    string `json:"title"`
+		Finding  string `json:"finding"`
+		Category string `json:"category"`
+		Source   string `json:"source"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	args.Title = strings.Join(strings.Fields(args.Title), " ")
+	args.Category = strings.Join(strings.Fields(args.Category), " ")
+	if args.Title == "" || strings.TrimSpace(args.Finding) == "" {
+		return mcp.NewToolResultError("Invalid arguments: a finding needs a title and text"), nil
+	}
+	if args.Category == "" {
+		args.Category = "General"
+	}
+
+	h.plans.mu.Lock()
+	defer h.plans.mu.Unlock()
+
+	content, err := h.readPlanFile(args.Project, findingsFile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	entry := fmt.Sprintf("### %s\n\n*Recorded %s", args.Title, h.plans.stamp())
+	if strings.TrimSpace(args.Source) != "" {
+		entry += " · Source: " + strings.TrimSpace(args.Source)
+	}
+	entry += "*\n\n" + escapeHeadings(strings.TrimSpace(args.Finding))
+	content = replaceSection(content, args.Category, sectionBody(content, args.Category)+"\n\n"+entry)
+
+	if err := h.writePlanFile(ctx, args.Project, findingsFile, content); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Added finding %q under %s in %s.", args.Title, args.Category, findingsFile)), nil
+}
+
+func (h *Handler) handleLogProgress(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project string `json:"project"`
+		Entry   string `json:"entry"`
+		Kind    string `json:"kind"`
+		Phase   string `json:"phase"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if args.Kind == "" {
+		args.Kind = "action"
+	}
+	if !contains(progressKinds, args.Kind) {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: kind must be one of: %s", strings.Join(progressKinds, ", "))), nil
+	}
+	if strings.TrimSpace(args.Entry) == "" {
+		return mcp.NewToolResultError("Invalid arguments: entry is required"), nil
+	}
+
+	h.plans.mu.Lock()
+	defer h.plans.mu.Unlock()
+
+	// Entries belong to the current phase unless another is named
+	if args.Phase == "" {
+		if plan, err := h.readPlanFile(args.Project, taskPlanFile); err == nil {
+			if current, ok := currentPhase(parsePhases(plan)); ok {
+				args.Phase = current.Name
+			}
+		}
+	}
+	if err := h.appendProgress(ctx, args.Project, args.Kind, args.Phase, args.Entry); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(fmt.Sprintf("Logged %s in %s.", args.Kind, progressFile)), nil
+}
+
+func (h *Handler) handlePlanRecover(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project string `json:"project"`
+		Recent  int    `json:"recent"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if args.Recent <= 0 {
+		args.Recent = 5
+	}
+
+	h.plans.mu.Lock()
+	defer h.plans.mu.Unlock()
+
+	plan, err := h.readPlanFile(args.Project, taskPlanFile)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	findings, _ := h.readPlanFile(args.Project, findingsFile)
+	progress, _ := h.readPlanFile(args.Project, progressFile)
+
+	phases := parsePhases(plan)
+	complete := 0
+	for _, phase := range phases {
+		if phase.Status == "complete" {
+			complete++
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Session State: %s\n\n**Goal:** %s\n\n", strings.TrimSpace(args.Project), strings.Join(strings.Fields(sectionBody(plan, "Goal")), " ")))
+	if current, ok := currentPhase(phases); ok {
+		text.WriteString(fmt.Sprintf("**Current phase:** %s (%s)", current.Name, current.Status))
+		if current.Note != "" {
+			text.WriteString(" — " + current.Note)
+		}
+		text.WriteString("\n")
+	} else {
+		text.WriteString("**Current phase:** none; every phase is complete or blocked\n")
+	}
+	text.WriteString(fmt.Sprintf("**Progress:** %d of %d phases complete\n\n## Phases\n\n%s\n", complete, len(phases), renderPhases(phases)))
+
+	if decisions := listItems(plan, "Decisions"); len(decisions) > 0 {
+		text.WriteString("\n## Recent Decisions\n\n")
+		for _, decision := range lastN(decisions, 3) {
+			text.WriteString("- " + decision + "\n")
+		}
+	}
+
+	if categories := sectionTitles(findings); len(categories) > 0 {
+		text.WriteString("\n## Findings\n\n")
+		for _, category := range categories {
+			titles := []string{}
+			for _, line := range strings.Split(sectionBody(findings, category), "\n") {
+				if strings.HasPrefix(line, "### ") {
+					titles = append(titles, strings.TrimPrefix(line, "### "))
+				}
+			}
+			text.WriteString(fmt.Sprintf("- **%s:** %s\n", category, strings.Join(titles, "; ")))
+		}
+	}
+
+	entries, errs := []string{}, []string{}
+	for _, line := range strings.Split(progress, "\n") {
+		m := progressLine.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		entries = append(entries, strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		if m[2] == "error" {
+			errs = append(errs, strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		}
+	}
+	if len(entries) > 0 {
+		text.WriteString("\n## Recent Progress\n\n")
+		for _, entry := range lastN(entries, args.Recent) {
+			text.WriteString("- " + entry + "\n")
+		}
+	}
+	if len(errs) > 0 {
+		text.WriteString("\n## Errors to Avoid Repeating\n\n")
+		for _, entry := range lastN(errs, args.Recent) {
+			text.WriteString("- " + entry + "\n")
+		}
+	}
+
+	text.WriteString(fmt.Sprintf("\nRead %s, %s and %s in full when you need more than this summary.", taskPlanFile, findingsFile, progressFile))
+	return mcp.NewToolResultText(text.String()), nil
+}
+
+// escapeHeadings backslash-escapes the lines of a finding that markdown
+// would read as headings, so they cannot open or close a section
+func escapeHeadings(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = `\` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lastN returns the last n items of a list
+func lastN(items []string, n int) []string {
+	if len(items) > n {
+		return items[len(items)-n:]
+	}
+	return items
+}
@@ -0,0 +1,199 @@
+package dojo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanInitRejectsBlankPhases(t *testing.T) {
+	h := newTestHandler(t)
+	text, isError := callTool(t, h.guard(h.handlePlanInit), "dojo.plan_init", map[string]interface{}{
+		"project": "x",
+		"goal":    "g",
+		"phases":  []interface{}{" ", "\t"},
+	})
+	if !isError || !strings.Contains(text, "at least one non-empty phase") {
+		t.Fatalf("blank phases gave %q (error %v)", text, isError)
+	}
+}
+
+func TestPlanInitStartsFirstPhase(t *testing.T) {
+	h := newTestHandler(t)
+	text, isError := callTool(t, h.guard(h.handlePlanInit), "dojo.plan_init", map[string]interface{}{
+		"project": "x",
+		"goal":    "g",
+		"phases":  []interface{}{" ", "Research", "Build"},
+	})
+	if isError {
+		t.Fatalf("plan_init failed: %s", text)
+	}
+	plan, err := h.workspace.ReadFile("proj_x/" + taskPlanFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(plan), "Research") || strings.Count(string(plan), "in_progress") != 1 {
+		t.Fatalf("unexpected plan:\n%s", plan)
+	}
+}
+
+// startPlan creates a plan for project x with the given phases
+func startPlan(t *testing.T, h *Handler, phases ...interface{}) {
+	t.Helper()
+	text, isError := callTool(t, h.guard(h.handlePlanInit), "dojo.plan_init", map[string]interface{}{
+		"project": "x",
+		"goal":    "Ship the thing",
+		"phases":  phases,
+	})
+	if isError {
+		t.Fatalf("plan_init failed: %s", text)
+	}
+}
+
+func readPlan(t *testing.T, h *Handler, file string) string {
+	t.Helper()
+	data, err := h.workspace.ReadFile("proj_x/" + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestPlanUpdatePhase(t *testing.T) {
+	h := newTestHandler(t)
+	startPlan(t, h, "Research", "Build")
+	update := h.guard(h.handlePlanUpdatePhase)
+
+	text, isError := callTool(t, update, "dojo.plan_update_phase", map[string]interface{}{
+		"project":  "x",
+		"phase":    "research",
+		"status":   "complete",
+		"note":     "sources read",
+		"decision": "Use the  plain store",
+	})
+	if isError || !strings.Contains(text, "Current phase: **Build** (pending)") {
+		t.Fatalf("completing a phase gave %q (error %v)", text, isError)
+	}
+	plan := readPlan(t, h, taskPlanFile)
+	for _, want := range []string{"- [x] Research — complete: sources read", "- [ ] Build — pending", "(Research): Use the plain store"} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan is missing %q:\n%s", want, plan)
+		}
+	}
+	if progress := readPlan(t, h, progressFile); !strings.Contains(progress, "(Research): Phase Research: in_progress → complete") {
+		t.Errorf("the change was not logged:\n%s", progress)
+	}
+
+	if text, isError := callTool(t, update, "dojo.plan_update_phase", map[string]interface{}{
+		"project": "x", "phase": "Ship", "status": "pending",
+	}); !isError || !strings.Contains(text, "Pass add to add it") {
+		t.Fatalf("an unknown phase gave %q (error %v)", text, isError)
+	}
+	if text, isError := callTool(t, update, "dojo.plan_update_phase", map[string]interface{}{
+		"project": "x", "phase": "Build", "status": "done",
+	}); !isError || !strings.Contains(text, "status must be one of") {
+		t.Fatalf("an unknown status gave %q (error %v)", text, isError)
+	}
+
+	text, isError = callTool(t, update, "dojo.plan_update_phase", map[string]interface{}{
+		"project": "x", "phase": " Ship  it ", "status": "blocked", "add": true,
+	})
+	if isError || !strings.Contains(text, "Phase Ship it added as blocked") {
+		t.Fatalf("adding a phase gave %q (error %v)", text, isError)
+	}
+	phases := parsePhases(readPlan(t, h, taskPlanFile))
+	if len(phases) != 3 || phases[2] != (planPhase{Name: "Ship it", Status: "blocked"}) {
+		t.Fatalf("phases after adding: %+v", phases)
+	}
+	// A phase can be referred to by its number
+	if _, isError := callTool(t, update, "dojo.plan_update_phase", map[string]interface{}{
+		"project": "x", "phase": "2", "status": "in_progress",
+	}); isError {
+		t.Fatal("a phase number was not accepted")
+	}
+	if phases := parsePhases(readPlan(t, h, taskPlanFile)); phases[1].Status != "in_progress" {
+		t.Fatalf("phase 2 was not updated: %+v", phases)
+	}
+}
+
+func TestAddFindingKeepsHeadingsInsideTheFinding(t *testing.T) {
+	h := newTestHandler(t)
+	startPlan(t, h, "Research")
+	add := h.guard(h.handleAddFinding)
+
+	for _, finding := range []map[string]interface{}{
+		{"project": "x", "title": "Layout", "finding": "The store keeps:\n## Snapshots\n# and memory\n### Nested", "source": "store.go"},
+		{"project": "x", "title": "Locking", "finding": "Writes take a lock."},
+		{"project": "x", "title": "Paths", "finding": "Paths stay inside.", "category": "Security"},
+	} {
+		if text, isError := callTool(t, add, "dojo.add_finding", finding); isError {
+			t.Fatalf("add_finding failed: %s", text)
+		}
+	}
+
+	findings := readPlan(t, h, findingsFile)
+	if titles := sectionTitles(findings); strings.Join(titles, ",") != "General,Security" {
+		t.Fatalf("sections %q in:\n%s", titles, findings)
+	}
+	general := sectionBody(findings, "General")
+	for _, want := range []string{"### Layout", "Source: store.go", `\## Snapshots`, `\# and memory`, `\### Nested`, "### Locking", "Writes take a lock."} {
+		if !strings.Contains(general, want) {
+			t.Errorf("the General section is missing %q:\n%s", want, general)
+		}
+	}
+
+	if text, isError := callTool(t, add, "dojo.add_finding", map[string]interface{}{"project": "x", "title": " ", "finding": "text"}); !isError {
+		t.Fatalf("a finding without a title was added: %s", text)
+	}
+}
+
+func TestLogProgressAndRecover(t *testing.T) {
+	h := newTestHandler(t)
+	if text, isError := callTool(t, h.guard(h.handlePlanRecover), "dojo.plan_recover", map[string]interface{}{"project": "x"}); !isError || !strings.Contains(text, "no plan yet") {
+		t.Fatalf("recovering without a plan gave %q (error %v)", text, isError)
+	}
+
+	startPlan(t, h, "Research", "Build")
+	log := h.guard(h.handleLogProgress)
+	for _, entry := range []map[string]interface{}{
+		{"project": "x", "entry": "Read the  store"},
+		{"project": "x", "entry": "Flock failed on NFS", "kind": "error"},
+		{"project": "x", "entry": "Sketched the API", "kind": "result", "phase": "Build"},
+	} {
+		if text, isError := callTool(t, log, "dojo.log_progress", entry); isError {
+			t.Fatalf("log_progress failed: %s", text)
+		}
+	}
+	if text, isError := callTool(t, log, "dojo.log_progress", map[string]interface{}{"project": "x", "entry": "x", "kind": "note"}); !isError {
+		t.Fatalf("an unknown kind was logged: %s", text)
+	}
+
+	progress := readPlan(t, h, progressFile)
+	for _, want := range []string{"**action** (Research): Read the store", "**error** (Research): Flock failed on NFS", "**result** (Build): Sketched the API"} {
+		if !strings.Contains(progress, want) {
+			t.Errorf("progress is missing %q:\n%s", want, progress)
+		}
+	}
+
+	if _, isError := callTool(t, h.guard(h.handleAddFinding), "dojo.add_finding", map[string]interface{}{"project": "x", "title": "Locking", "finding": "Writes take a lock."}); isError {
+		t.Fatal("add_finding failed")
+	}
+	text, isError := callTool(t, h.guard(h.handlePlanRecover), "dojo.plan_recover", map[string]interface{}{"project": "x", "recent": 2})
+	if isError {
+		t.Fatalf("plan_recover failed: %s", text)
+	}
+	for _, want := range []string{
+		"**Goal:** Ship the thing",
+		"**Current phase:** Research (in_progress)",
+		"**Progress:** 0 of 2 phases complete",
+		"- **General:** Locking",
+		"## Errors to Avoid Repeating",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("recovery is missing %q:\n%s", want, text)
+		}
+	}
+	recent := text[strings.Index(text, "## Recent Progress"):strings.Index(text, "## Errors to Avoid Repeating")]
+	if strings.Contains(recent, "Read the store") || !strings.Contains(recent, "Sketched the API") {
+		t.Errorf("recent progress was not limited to 2 entries:\n%s", recent)
+	}
+}
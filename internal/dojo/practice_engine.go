@@ -0,0 +1,336 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// practiceRun is one person's way through a practice
+type practiceRun struct {
+	ID        string
+	Practice  Practice
+	Subject   string
+	Answers   []string
+	StartedAt time.Time
+	// ActiveAt is when the run was started or last answered
+	ActiveAt time.Time
+}
+
+// A run is dropped once finished, or once left unanswered for
+// practiceRunTTL. Past maxPracticeRuns in progress, starting a run drops the
+// one left alone longest.
+const (
+	practiceRunTTL  = 24 * time.Hour
+	maxPracticeRuns = 1000
+)
+
+// practiceEngine holds the available practices and the runs in progress
+type practiceEngine struct {
+	mu        sync.Mutex
+	practices map[string]Practice
+	runs      map[string]*practiceRun
+	now       func() time.Time
+}
+
+func newPracticeEngine(practices []Practice) *practiceEngine {
+	e := &practiceEngine{
+		practices: map[string]Practice{},
+		runs:      map[string]*practiceRun{},
+		now:       time.Now,
+	}
+	for _, p := range practices {
+		e.practices[p.Name] = p
+	}
+	return e
+}
+
+// add makes a practice available, replacing any practice of the same name
+func (e *practiceEngine) add(p Practice) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.practices[p.Name] = p
+}
+
+// list returns the available practices sorted by name
+func (e *practiceEngine) list() []Practice {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	practices := make([]Practice, 0, len(e.practices))
+	for _, p := range e.practices {
+		practices = append(practices, p)
+	}
+	sort.Slice(practices, func(i, j int) bool {
+		return practices[i].Name < practices[j].Name
+	})
+	return practices
+}
+
+// get returns a practice by name
+func (e *practiceEngine) get(name string) (Practice, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	p, ok := e.practices[name]
+	if !ok {
+		return Practice{}, fmt.Errorf("practice not found: %s", name)
+	}
+	return p, nil
+}
+
+// names returns the names of the available practices
+func (e *practiceEngine) names() []string {
+	names := []string{}
+	for _, p := range e.list() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// start begins a new run of a practice, first dropping expired runs
+func (e *practiceEngine) start(name, subject string) (practiceRun, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	p, ok := e.practices[name]
+	if !ok {
+		return practiceRun{}, fmt.Errorf("practice not found: %s", name)
+	}
+
+	now := e.now().UTC()
+	e.evict(now)
+	run := &practiceRun{
+		ID:        newID("practice"),
+		Practice:  p,
+		Subject:   subject,
+		StartedAt: now,
+		ActiveAt:  now,
+	}
+	e.runs[run.ID] = run
+	return run.clone(), nil
+}
+
+// evict drops runs idle past practiceRunTTL, then the longest idle runs
+// until there is room for one more. Callers hold the lock.
+func (e *practiceEngine) evict(now time.Time) {
+	for id, run := range e.runs {
+		if now.Sub(run.ActiveAt) > practiceRunTTL {
+			delete(e.runs, id)
+		}
+	}
+	for len(e.runs) >= maxPracticeRuns {
+		var idlest *practiceRun
+		for _, run := range e.runs {
+			if idlest == nil || run.ActiveAt.Before(idlest.ActiveAt) {
+				idlest = run
+			}
+		}
+		delete(e.runs, idlest.ID)
+	}
+}
+
+// run returns a run in progress. Callers hold the lock.
+func (e *practiceEngine) run(id string) (*practiceRun, error) {
+	run, ok := e.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("practice run not found: %s (runs end when finished or after %.0f hours without an answer)", id, practiceRunTTL.Hours())
+	}
+	if e.now().Sub(run.ActiveAt) > practiceRunTTL {
+		delete(e.runs, id)
+		return nil, fmt.Errorf("practice run %s expired after %.0f hours without an answer; start the practice again", id, practiceRunTTL.Hours())
+	}
+	return run, nil
+}
+
+// answer validates an answer to the current step of a run and advances
+// it, returning a copy of the run as answered
+func (e *practiceEngine) answer(id, answer string) (practiceRun, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run, err := e.run(id)
+	if err != nil {
+		return practiceRun{}, err
+	}
+	if len(run.Answers) == len(run.Practice.Steps) {
+		return practiceRun{}, fmt.Errorf("every step is answered; use dojo.practice_finish to close the practice")
+	}
+
+	step := run.Practice.Steps[len(run.Answers)]
+	answer = strings.TrimSpace(answer)
+	if err := step.validate(answer); err != nil {
+		return practiceRun{}, err
+	}
+
+	run.Answers = append(run.Answers, answer)
+	run.ActiveAt = e.now().UTC()
+	return run.clone(), nil
+}
+
+// finish closes a run and drops it
+func (e *practiceEngine) finish(id string) (practiceRun, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	run, err := e.run(id)
+	if err != nil {
+		return practiceRun{}, err
+	}
+	delete(e.runs, id)
+	return run.clone(), nil
+}
+
+// clone copies a run, so it can be read once the engine's lock is released
+// while later answers change the original
+func (r *practiceRun) clone() practiceRun {
+	copied := *r
+	copied.Answers = append([]string{}, r.Answers...)
+	return copied
+}
+
+// validate checks an answer against the step's requirements
+func (s PracticeStep) validate(answer string) error {
+	words := len(strings.Fields(answer))
+	minWords := s.MinWords
+	if minWords < 1 {
+		minWords = 1
+	}
+	if words < minWords {
+		if minWords == 1 {
+			return fmt.Errorf("step %q needs an answer; take your time, there is no rush", s.Title)
+		}
+		return fmt.Errorf("step %q asks for at least %d words; take a moment longer with it", s.Title, minWords)
+	}
+	if s.MaxWords > 0 && words > s.MaxWords {
+		return fmt.Errorf("step %q asks for at most %d words; try distilling it", s.Title, s.MaxWords)
+	}
+	return nil
+}
+
+// summary builds the closing summary from the user's own answers
+func (r *practiceRun) summary() string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# %s: Your Practice\n\n**Your %s:** %s\n\n", r.Practice.Title, r.Practice.Subject, r.Subject))
+	text.WriteString("## What You Found\n\n")
+	for i, step := range r.Practice.Steps {
+		text.WriteString(fmt.Sprintf("### Step %d: %s\n\n", i+1, step.Title))
+		if i < len(r.Answers) {
+			text.WriteString(quote(r.Answers[i]))
+		} else {
+			text.WriteString("*Not answered this time. You can return to it whenever you're ready.*")
+		}
+		text.WriteString("\n\n")
+	}
+	text.WriteString(fmt.Sprintf("## Closing\n\n%s", r.Practice.Closing))
+	return text.String()
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	if word == "" {
+		return word
+	}
+	return strings.ToUpper(word[:1]) + word[1:]
+}
+
+// quote renders text as a Markdown blockquote
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+// worksheet renders the whole practice as a single Markdown document for
+// reading, with reflection space under each step. Inputs other than the
+// subject are listed beneath it.
+func (p Practice) worksheet(subject string, details map[string]string) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# %s\n\n**Your %s:** %s\n\n", p.Title, capitalize(p.Subject), subject))
+	for _, name := range p.inputNames()[1:] {
+		if value, ok := details[name]; ok {
+			text.WriteString(fmt.Sprintf("**%s:** %s\n\n", capitalize(strings.ReplaceAll(name, "_", " ")), value))
+		}
+	}
+	text.WriteString(fmt.Sprintf("## %s\n\n%s\n\n## Guided Exercise\n\n", p.IntroTitle, p.Intro))
+	for i := range p.Steps {
+		text.WriteString(p.stepText(i))
+		text.WriteString("\n\n**Reflection space:**\n[Take your time with this step]\n\n")
+	}
+	text.WriteString(fmt.Sprintf("## Closing\n\n%s\n\n", p.Closing))
+	text.WriteString(fmt.Sprintf("*To work through this practice one step at a time, call `dojo.practice_start` with practice `%s`.*", p.Name))
+	return text.String()
+}
+
+func (h *Handler) handlePracticeStart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Practice  string `json:"practice"`
+		Situation string `json:"situation"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	run, err := h.practices.start(args.Practice, args.Situation)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%v. Available practices: %s", err, strings.Join(h.practices.names(), ", "))), nil
+	}
+
+	response := fmt.Sprintf(`# %s
+
+**Practice ID:** %s
+**Your %s:** %s
+
+%s
+
+We'll take this one step at a time. There is no rush. Answer each step with `+"`dojo.practice_step`"+`.
+
+%s`, run.Practice.Title, run.ID, run.Practice.Subject, run.Subject, run.Practice.Intro, run.Practice.stepText(0))
+
+	return mcp.NewToolResultText(response), nil
+}
+
+func (h *Handler) handlePracticeStep(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		PracticeID string `json:"practice_id"`
+		Answer     string `json:"answer"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	run, err := h.practices.answer(args.PracticeID, args.Answer)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	answered := len(run.Answers)
+	if answered == len(run.Practice.Steps) {
+		return mcp.NewToolResultText(fmt.Sprintf("Thank you. Every step of the %s is answered.\n\nWhen you're ready, call `dojo.practice_finish` with practice ID %s to receive your closing summary.", run.Practice.Title, run.ID)), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Thank you. (%d of %d steps)\n\n%s", answered, len(run.Practice.Steps), run.Practice.stepText(answered))), nil
+}
+
+func (h *Handler) handlePracticeFinish(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		PracticeID string `json:"practice_id"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	run, err := h.practices.finish(args.PracticeID)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(run.summary()), nil
+}
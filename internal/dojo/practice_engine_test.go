@@ -0,0 +1,91 @@
+package dojo
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPracticeRunsEnd(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	e := newPracticeEngine(builtinPractices())
+	e.now = func() time.Time { return now }
+
+	finished, err := e.start("thinking_room", "caching")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.finish(finished.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.finish(finished.ID); err == nil {
+		t.Fatal("a finished run could be finished again")
+	}
+
+	idle, _ := e.start("thinking_room", "caching")
+	active, _ := e.start("thinking_room", "caching")
+	now = now.Add(practiceRunTTL - time.Hour)
+	if _, err := e.answer(active.ID, "The cache hides a slow lineage walk."); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := e.answer(idle.ID, "Too late for this one."); err == nil {
+		t.Fatal("an idle run outlived its time to live")
+	}
+	if _, err := e.answer(active.ID, "Answering keeps a run alive."); err != nil {
+		t.Fatalf("an answered run expired: %v", err)
+	}
+	if _, ok := e.runs[idle.ID]; ok {
+		t.Fatal("the expired run is still held")
+	}
+}
+
+func TestPracticeRunsAreCapped(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	e := newPracticeEngine(builtinPractices())
+	e.now = func() time.Time { return now }
+
+	first, _ := e.start("thinking_room", "caching")
+	for i := 0; i < maxPracticeRuns; i++ {
+		now = now.Add(time.Second)
+		if _, err := e.start("thinking_room", "caching"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(e.runs) != maxPracticeRuns {
+		t.Fatalf("%d runs held, want %d", len(e.runs), maxPracticeRuns)
+	}
+	if _, ok := e.runs[first.ID]; ok {
+		t.Fatal("the run left alone longest was kept")
+	}
+}
+
+func TestConcurrentStepsReadTheirOwnRun(t *testing.T) {
+	h := newTestHandler(t)
+	run, err := h.practices.start("thinking_room", "caching")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	texts := make([]string, len(run.Practice.Steps))
+	for i := range texts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			texts[i], _ = callTool(t, h.guard(h.handlePracticeStep), "dojo.practice_step", map[string]interface{}{"practice_id": run.ID, "answer": "The cache hides a slow lineage walk."})
+		}(i)
+	}
+	wg.Wait()
+
+	done := 0
+	for _, text := range texts {
+		if strings.Contains(text, "Every step") {
+			done++
+		}
+	}
+	if done != 1 {
+		t.Fatalf("%d steps reported the practice answered:\n%s", done, strings.Join(texts, "\n---\n"))
+	}
+}
@@ -0,0 +1,353 @@
+package dojo
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// builtinPracticeFiles holds the practices shipped with the server
+//
+//go:embed practices/*.yaml practices/*.md
+var builtinPracticeFiles embed.FS
+
+var (
+	practiceNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	practiceToolPattern = regexp.MustCompile(`^dojo\.[a-z][a-z0-9_.]*$`)
+	practiceInputTypes  = map[string]bool{"string": true, "integer": true, "number": true, "boolean": true}
+	// stepHeadingPattern matches "Step: Title {#key min_words=N max_words=N}"
+	stepHeadingPattern = regexp.MustCompile(`^Step:\s*(.+?)\s*(?:\{([^}]*)\})?$`)
+)
+
+// coreTools are the tools Dojo registers itself, including the worksheets of
+// the built-in practices. A practice's worksheet tool may not take one of
+// their names, or it would be lost to the core tool, unless it replaces the
+// built-in practice whose worksheet it is.
+var coreTools = []string{
+	"dojo.reflect",
+	"dojo.validate_reflection",
+	"dojo.search_wisdom",
+	"dojo.get_principles",
+	"dojo.get_seed",
+	"dojo.diff_seed",
+	"dojo.apply_seed",
+	"dojo.list_seeds",
+	"dojo.trace_lineage",
+	"dojo.check_pace",
+	"dojo.submit_pace_assessment",
+	"dojo.create_thinking_room",
+	"dojo.record_insight",
+	"dojo.cite",
+	"dojo.export_packet",
+	"dojo.import_packet",
+	"dojo.plan_init",
+	"dojo.plan_update_phase",
+	"dojo.add_finding",
+	"dojo.log_progress",
+	"dojo.plan_recover",
+	"dojo.remember",
+	"dojo.recall",
+	"dojo.forget",
+	"dojo.compress_memory",
+	"dojo.snapshot",
+	"dojo.restore_snapshot",
+	"dojo.route",
+	"dojo.resolve_conflict",
+	"dojo.calibrate",
+	"dojo.calibration_summary",
+	"dojo.practice_start",
+	"dojo.practice_step",
+	"dojo.practice_finish",
+	"dojo.practice_inter_acceptance",
+	"dojo.explore_radical_freedom",
+	"dojo.practice_thinking_room",
+}
+
+// builtinWorksheetTools are the worksheet tools of the built-in practices,
+// by practice name
+var builtinWorksheetTools = map[string]string{
+	"inter_acceptance": "dojo.practice_inter_acceptance",
+	"radical_freedom":  "dojo.explore_radical_freedom",
+	"thinking_room":    "dojo.practice_thinking_room",
+}
+
+// builtinPractices loads the practices embedded in the binary. They are
+// validated like any other definition, so a broken file is a build mistake.
+func builtinPractices() []Practice {
+	practices, err := loadPracticeFS(builtinPracticeFiles, "practices")
+	if err != nil {
+		panic(fmt.Sprintf("built-in practices: %v", err))
+	}
+	return practices
+}
+
+// LoadPractices reads every .yaml, .yml and .md practice definition in a
+// directory and validates it
+func LoadPractices(dir string) ([]Practice, error) {
+	return loadPracticeFS(os.DirFS(dir), ".")
+}
+
+// WithPractices adds practices to the built-in set. A practice with the same
+// name as a built-in one replaces it.
+func WithPractices(practices []Practice) Option {
+	return func(h *Handler) {
+		for _, p := range practices {
+			h.practices.add(p)
+		}
+	}
+}
+
+func loadPracticeFS(fsys fs.FS, dir string) ([]Practice, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read practices: %w", err)
+	}
+
+	var practices []Practice
+	var problems []string
+	names := map[string]string{}
+	tools := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := path.Join(dir, entry.Name())
+		var parse func([]byte) (Practice, error)
+		switch strings.ToLower(path.Ext(file)) {
+		case ".yaml", ".yml":
+			parse = parsePracticeYAML
+		case ".md":
+			parse = parsePracticeMarkdown
+		default:
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read practice %s: %w", file, err)
+		}
+		p, err := parse(data)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", entry.Name(), err))
+			continue
+		}
+		if errs := validatePractice(p); len(errs) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s", entry.Name(), strings.Join(errs, "; ")))
+			continue
+		}
+		if other, ok := names[p.Name]; ok {
+			problems = append(problems, fmt.Sprintf("%s: practice %q is already defined in %s", entry.Name(), p.Name, other))
+			continue
+		}
+		if other, ok := tools[p.toolName()]; ok {
+			problems = append(problems, fmt.Sprintf("%s: tool %q is already defined in %s", entry.Name(), p.toolName(), other))
+			continue
+		}
+
+		names[p.Name] = entry.Name()
+		tools[p.toolName()] = entry.Name()
+		practices = append(practices, p)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid practice definitions:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return practices, nil
+}
+
+// parsePracticeYAML decodes a YAML definition, rejecting unknown fields
+func parsePracticeYAML(data []byte) (Practice, error) {
+	var p Practice
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&p); err != nil {
+		return Practice{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return p, nil
+}
+
+// parsePracticeMarkdown decodes a Markdown definition. The YAML front matter
+// holds the metadata and inputs; the body holds the text, one "## " section
+// each: the first section is the introduction (its heading is the intro
+// title), "## Step: Title {#key}" sections are the steps in order, and
+// "## Closing" is the closing text.
+func parsePracticeMarkdown(data []byte) (Practice, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return Practice{}, fmt.Errorf("missing YAML front matter")
+	}
+	end := strings.Index(text[4:], "\n---\n")
+	if end == -1 {
+		return Practice{}, fmt.Errorf("unterminated YAML front matter")
+	}
+	p, err := parsePracticeYAML([]byte(text[4 : 4+end]))
+	if err != nil {
+		return Practice{}, err
+	}
+	if p.Intro != "" || p.Closing != "" || len(p.Steps) > 0 {
+		return Practice{}, fmt.Errorf("intro, steps and closing belong in the Markdown body, not the front matter")
+	}
+
+	for _, section := range markdownSections(text[4+end+5:]) {
+		heading, body := section[0], section[1]
+		match := stepHeadingPattern.FindStringSubmatch(heading)
+		switch {
+		case match != nil:
+			step := PracticeStep{Title: match[1], Prompt: body}
+			if err := parseStepAttributes(match[2], &step); err != nil {
+				return Practice{}, fmt.Errorf("step %q: %w", step.Title, err)
+			}
+			p.Steps = append(p.Steps, step)
+		case strings.EqualFold(heading, "Closing"):
+			p.Closing = body
+		case p.IntroTitle == "" && len(p.Steps) == 0:
+			p.IntroTitle, p.Intro = heading, body
+		default:
+			return Practice{}, fmt.Errorf("unexpected section %q", heading)
+		}
+	}
+	return p, nil
+}
+
+// markdownSections splits a Markdown body into "## " headings and the text
+// beneath each
+func markdownSections(body string) [][2]string {
+	var sections [][2]string
+	var lines []string
+	heading := ""
+	flush := func() {
+		if heading != "" {
+			sections = append(sections, [2]string{heading, strings.TrimSpace(strings.Join(lines, "\n"))})
+		}
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "## ") {
+			flush()
+			heading, lines = strings.TrimSpace(line[3:]), nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
+
+// parseStepAttributes reads "#key min_words=N max_words=N" from a step
+// heading
+func parseStepAttributes(attributes string, step *PracticeStep) error {
+	for _, field := range strings.Fields(attributes) {
+		if strings.HasPrefix(field, "#") {
+			step.Key = field[1:]
+			continue
+		}
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return fmt.Errorf("unknown attribute %q", field)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number", name)
+		}
+		switch name {
+		case "min_words":
+			step.MinWords = n
+		case "max_words":
+			step.MaxWords = n
+		default:
+			return fmt.Errorf("unknown attribute %q", name)
+		}
+	}
+	return nil
+}
+
+// validatePractice checks a definition against the practice schema and
+// returns every problem found
+func validatePractice(p Practice) []string {
+	var errs []string
+	require := func(field, value string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, field+" is required")
+		}
+	}
+
+	require("name", p.Name)
+	if p.Name != "" && !practiceNamePattern.MatchString(p.Name) {
+		errs = append(errs, fmt.Sprintf("name %q must be lower snake_case", p.Name))
+	}
+	require("title", p.Title)
+	require("description", p.Description)
+	if p.Tool != "" && !practiceToolPattern.MatchString(p.Tool) {
+		errs = append(errs, fmt.Sprintf("tool %q must look like dojo.some_name", p.Tool))
+	}
+	if p.Name != "" && contains(coreTools, p.toolName()) && builtinWorksheetTools[p.Name] != p.toolName() {
+		errs = append(errs, fmt.Sprintf("tool %q is one of Dojo's own tools; choose another name or tool", p.toolName()))
+	}
+	require("intro_title", p.IntroTitle)
+	require("intro", p.Intro)
+	require("closing", p.Closing)
+
+	require("subject", p.Subject)
+	if p.Subject != "" {
+		subject, ok := p.Input[p.Subject]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("subject %q must be declared in input", p.Subject))
+		case subject.Type != "string" || !subject.Required:
+			errs = append(errs, fmt.Sprintf("subject input %q must be a required string", p.Subject))
+		}
+	}
+	inputs := make([]string, 0, len(p.Input))
+	for name := range p.Input {
+		inputs = append(inputs, name)
+	}
+	sort.Strings(inputs)
+	for _, name := range inputs {
+		input := p.Input[name]
+		if !practiceNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Sprintf("input %q must be lower snake_case", name))
+		}
+		if !practiceInputTypes[input.Type] {
+			errs = append(errs, fmt.Sprintf("input %q has type %q; use string, integer, number or boolean", name, input.Type))
+		}
+		require(fmt.Sprintf("input %q description", name), input.Description)
+	}
+
+	if len(p.Steps) == 0 {
+		errs = append(errs, "at least one step is required")
+	}
+	keys := map[string]bool{}
+	for i, step := range p.Steps {
+		field := fmt.Sprintf("steps[%d]", i)
+		require(field+".key", step.Key)
+		if step.Key != "" {
+			if !practiceNamePattern.MatchString(step.Key) {
+				errs = append(errs, fmt.Sprintf("%s.key %q must be lower snake_case", field, step.Key))
+			}
+			if keys[step.Key] {
+				errs = append(errs, fmt.Sprintf("%s.key %q is used more than once", field, step.Key))
+			}
+			if _, ok := p.Input[step.Key]; ok {
+				errs = append(errs, fmt.Sprintf("%s.key %q is also an input name", field, step.Key))
+			}
+			keys[step.Key] = true
+		}
+		require(field+".title", step.Title)
+		require(field+".prompt", step.Prompt)
+		if step.MinWords < 0 || step.MaxWords < 0 {
+			errs = append(errs, field+" word limits cannot be negative")
+		}
+		if step.MaxWords > 0 && step.MinWords > step.MaxWords {
+			errs = append(errs, field+".min_words cannot exceed max_words")
+		}
+	}
+	return errs
+}
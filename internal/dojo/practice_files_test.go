@@ -0,0 +1,104 @@
+package dojo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const practiceTemplate = `name: %NAME%
+title: A Practice
+description: Walks through a practice.
+%TOOL%intro_title: Before You Begin
+intro: Take your time.
+closing: Thank you.
+subject: topic
+input:
+  topic:
+    type: string
+    description: What the practice is about
+    required: true
+steps:
+  - key: notice
+    title: Notice
+    prompt: What do you notice?
+`
+
+func writePractice(t *testing.T, name, tool string) string {
+	t.Helper()
+	dir := t.TempDir()
+	definition := strings.ReplaceAll(practiceTemplate, "%NAME%", name)
+	if tool != "" {
+		tool = "tool: " + tool + "\n"
+	}
+	definition = strings.ReplaceAll(definition, "%TOOL%", tool)
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(definition), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPracticesCannotTakeCoreToolNames(t *testing.T) {
+	for _, tc := range []struct{ name, tool string }{
+		{"start", ""},
+		{"finish", ""},
+		{"mirror", "dojo.reflect"},
+		{"planning", "dojo.plan_init"},
+		// The worksheets of built-in practices are taken too
+		{"acceptance", "dojo.practice_inter_acceptance"},
+		{"freedom", "dojo.explore_radical_freedom"},
+	} {
+		_, err := LoadPractices(writePractice(t, tc.name, tc.tool))
+		if err == nil || !strings.Contains(err.Error(), "one of Dojo's own tools") {
+			t.Errorf("practice %q with tool %q was accepted: %v", tc.name, tc.tool, err)
+		}
+	}
+
+	if _, err := LoadPractices(writePractice(t, "gratitude", "")); err != nil {
+		t.Fatalf("a practice with its own tool was refused: %v", err)
+	}
+	if _, err := LoadPractices(writePractice(t, "radical_freedom", "dojo.explore_radical_freedom")); err != nil {
+		t.Fatalf("a practice replacing a built-in one could not keep its worksheet: %v", err)
+	}
+}
+
+func TestCoreToolsListsEveryRegisteredTool(t *testing.T) {
+	h := newTestHandler(t)
+	s := server.NewMCPServer("dojo", "1.0.0")
+	h.RegisterTools(s)
+
+	var listed struct {
+		Result struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(handleMessage(t, s, "tools/list", map[string]interface{}{})), &listed); err != nil {
+		t.Fatal(err)
+	}
+	registered := []string{}
+	for _, tool := range listed.Result.Tools {
+		registered = append(registered, tool.Name)
+	}
+	builtin := builtinPractices()
+	if len(builtin) != len(builtinWorksheetTools) {
+		t.Fatalf("builtinWorksheetTools lists %d practices, %d are built in", len(builtinWorksheetTools), len(builtin))
+	}
+	for _, p := range builtin {
+		if builtinWorksheetTools[p.Name] != p.toolName() {
+			t.Fatalf("builtinWorksheetTools has %q for %s, its tool is %q", builtinWorksheetTools[p.Name], p.Name, p.toolName())
+		}
+	}
+	core := append([]string{}, coreTools...)
+	sort.Strings(registered)
+	sort.Strings(core)
+	if strings.Join(registered, " ") != strings.Join(core, " ") {
+		t.Fatalf("coreTools is out of date:\nregistered %v\ncoreTools  %v", registered, core)
+	}
+}
@@ -0,0 +1,174 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// PracticeStep is one step of a guided practice. Key names the prompt
+// argument that carries the user's answer to the step; MinWords and
+// MaxWords bound the answers the practice engine accepts.
+type PracticeStep struct {
+	Key      string `yaml:"key"`
+	Title    string `yaml:"title"`
+	Prompt   string `yaml:"prompt"`
+	MinWords int    `yaml:"min_words"`
+	MaxWords int    `yaml:"max_words"`
+}
+
+// PracticeInput declares one input of a practice tool
+type PracticeInput struct {
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// Practice is a step-by-step exercise from Serenity Valley or AROMA, loaded
+// from a YAML or Markdown definition
+type Practice struct {
+	Name            string `yaml:"name"`
+	Title           string `yaml:"title"`
+	Description     string `yaml:"description"`
+	Tool            string `yaml:"tool"`
+	ToolDescription string `yaml:"tool_description"`
+	// Subject names the input holding what the practice is about
+	Subject    string                   `yaml:"subject"`
+	Input      map[string]PracticeInput `yaml:"input"`
+	IntroTitle string                   `yaml:"intro_title"`
+	Intro      string                   `yaml:"intro"`
+	Steps      []PracticeStep           `yaml:"steps"`
+	Closing    string                   `yaml:"closing"`
+}
+
+// promptArguments declares the subject and one answer argument per step
+func (p Practice) promptArguments() []mcp.PromptArgument {
+	arguments := []mcp.PromptArgument{
+		{
+			Name:        p.Subject,
+			Description: p.Input[p.Subject].Description,
+			Required:    true,
+		},
+	}
+	for i, step := range p.Steps {
+		arguments = append(arguments, mcp.PromptArgument{
+			Name:        step.Key,
+			Description: fmt.Sprintf("Your answer to Step %d (%s), once you have one", i+1, step.Title),
+		})
+	}
+	return arguments
+}
+
+// stepText renders one step as the guide would say it
+func (p Practice) stepText(index int) string {
+	step := p.Steps[index]
+	return fmt.Sprintf("### Step %d: %s\n\n%s", index+1, step.Title, step.Prompt)
+}
+
+// conversation renders the practice as alternating messages. Each answered
+// step is followed by the user's answer; the conversation stops at the first
+// unanswered step, or closes once every step has an answer.
+func (p Practice) conversation(subject string, answers map[string]string) []mcp.PromptMessage {
+	messages := []mcp.PromptMessage{
+		mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf("I'd like to do the %s, one step at a time.\n\n**My %s:** %s", p.Title, p.Subject, subject))),
+	}
+
+	opening := fmt.Sprintf("# %s\n\n%s\n\nWe'll take this one step at a time. There is no rush.\n\n%s", p.Title, p.Intro, p.stepText(0))
+	messages = append(messages, mcp.NewPromptMessage(mcp.RoleAssistant, mcp.NewTextContent(opening)))
+
+	for i, step := range p.Steps {
+		answer := strings.TrimSpace(answers[step.Key])
+		if answer == "" {
+			return messages
+		}
+		messages = append(messages, mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(answer)))
+
+		next := fmt.Sprintf("## Closing\n\n%s", p.Closing)
+		if i+1 < len(p.Steps) {
+			next = p.stepText(i + 1)
+		}
+		messages = append(messages, mcp.NewPromptMessage(mcp.RoleAssistant, mcp.NewTextContent(next)))
+	}
+	return messages
+}
+
+// practicePromptHandler returns the prompt handler for a practice
+func (h *Handler) practicePromptHandler(p Practice) func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		subject := strings.TrimSpace(request.Params.Arguments[p.Subject])
+		if subject == "" {
+			return nil, fmt.Errorf("%s is required", p.Subject)
+		}
+
+		return &mcp.GetPromptResult{
+			Description: p.Description,
+			Messages:    p.conversation(subject, request.Params.Arguments),
+		}, nil
+	}
+}
+
+// toolName returns the name of the tool that renders the practice as a
+// worksheet
+func (p Practice) toolName() string {
+	if p.Tool != "" {
+		return p.Tool
+	}
+	return "dojo.practice_" + p.Name
+}
+
+// toolSchema builds the tool input schema from the declared inputs
+func (p Practice) toolSchema() mcp.ToolInputSchema {
+	schema := mcp.ToolInputSchema{
+		Type:       "object",
+		Properties: map[string]interface{}{},
+	}
+	for _, name := range p.inputNames() {
+		input := p.Input[name]
+		schema.Properties[name] = map[string]interface{}{
+			"type":        input.Type,
+			"description": input.Description,
+		}
+		if input.Required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// inputNames returns the input names with the subject first and the rest
+// sorted
+func (p Practice) inputNames() []string {
+	names := []string{p.Subject}
+	others := []string{}
+	for name := range p.Input {
+		if name != p.Subject {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// practiceToolHandler returns the tool handler rendering a practice as a
+// worksheet
+func (h *Handler) practiceToolHandler(p Practice) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		details := map[string]string{}
+		for _, name := range p.inputNames() {
+			value, ok := request.Params.Arguments[name]
+			if !ok || value == nil || value == "" {
+				if p.Input[name].Required {
+					return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %s is required", name)), nil
+				}
+				continue
+			}
+			details[name] = fmt.Sprint(value)
+		}
+
+		return mcp.NewToolResultText(p.worksheet(details[p.Subject], details)), nil
+	}
+}
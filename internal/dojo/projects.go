@@ -0,0 +1,424 @@
+package dojo
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/packet"
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// projectsDocument is the store document holding project journals
+const projectsDocument = "projects"
+
+// project is the journal of work done under a project name: the thinking
+// rooms opened, seeds applied and insights recorded for it, and a trace of
+// the calls that named it
+type project struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Rooms       []projectRoom    `json:"rooms"`
+	Seeds       []appliedSeed    `json:"seeds"`
+	Insights    []projectInsight `json:"insights"`
+	Sessions    []packet.Session `json:"sessions"`
+}
+
+// projectRoom is a thinking room opened for a project. Version counts how
+// often the room was opened.
+type projectRoom struct {
+	ID        string    `json:"id"`
+	Topic     string    `json:"topic"`
+	AgentName string    `json:"agent_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
+
+// appliedSeed is one application of a seed, with the checklist it carried
+type appliedSeed struct {
+	packet.SeedUse
+	Checklist []string `json:"checklist"`
+}
+
+// projectInsight is an insight recorded for a project. Version counts how
+// often it was recorded or revised.
+type projectInsight struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+}
+
+// Traces keep the most recent sessions of a project and the most recent
+// calls of a session, so a long-lived project does not grow without bound
+const (
+	maxTraceSessions = 20
+	maxSessionCalls  = 200
+)
+
+// traceSaveDelay is how long traced calls gather before the journals are
+// saved, so a busy session writes the projects document once rather than
+// on every call
+var traceSaveDelay = 5 * time.Second
+
+// projects holds every project journal, keyed by project ID
+type projects struct {
+	mu   sync.Mutex
+	byID map[string]*project
+	now  func() time.Time
+
+	// unsaved are the traced calls not yet saved. They are applied again
+	// whenever the journals are reread from the store.
+	unsaved []tracedCall
+	// traceMu guards the pending save of traced calls
+	traceMu   sync.Mutex
+	traceSave *time.Timer
+}
+
+// tracedCall is a call traced in a project's session
+type tracedCall struct {
+	id      string
+	session string
+	call    packet.Call
+}
+
+// maxUnsavedCalls caps the traced calls held for saving, such as while the
+// data directory is read-only; older ones would be dropped from the traces
+// anyway
+const maxUnsavedCalls = maxTraceSessions * maxSessionCalls
+
+func newProjects() *projects {
+	return &projects{byID: map[string]*project{}, now: time.Now}
+}
+
+// projectIDPattern matches project IDs, as in the DojoPacket schema
+var projectIDPattern = regexp.MustCompile(`^proj_[a-z0-9_]+$`)
+
+// nonSlug matches runs of characters that cannot appear in a project ID
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// projectID derives a stable ID from a project name, so names differing
+// only in case or punctuation share a project. An ID is its own ID, so
+// tools accept the IDs they report in place of names.
+func projectID(name string) string {
+	if projectIDPattern.MatchString(name) {
+		return name
+	}
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if slug == "" {
+		slug = strings.TrimPrefix(wisdom.Digest(name), "sha256:")
+	}
+	return "proj_" + slug
+}
+
+// open returns the journal for a project name, starting one if needed.
+// Callers hold the lock.
+func (ps *projects) open(name string) *project {
+	name = strings.TrimSpace(name)
+	id := projectID(name)
+	p, ok := ps.byID[id]
+	if !ok {
+		p = &project{ID: id, Name: name, CreatedAt: ps.now().UTC()}
+		ps.byID[id] = p
+	}
+	return p
+}
+
+// get returns a copy of a project's journal
+func (ps *projects) get(name string) (project, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.byID[projectID(strings.TrimSpace(name))]
+	if !ok {
+		return project{}, false
+	}
+	return p.clone(), true
+}
+
+// list returns every project journal, ordered by ID
+func (ps *projects) list() []project {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	return ps.copyAll()
+}
+
+// copyAll copies every journal, ordered by ID. Callers hold the lock.
+func (ps *projects) copyAll() []project {
+	list := make([]project, 0, len(ps.byID))
+	for _, p := range ps.byID {
+		list = append(list, p.clone())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// clone copies a journal so it can be read without holding the lock
+func (p *project) clone() project {
+	copied := *p
+	copied.Rooms = append([]projectRoom{}, p.Rooms...)
+	copied.Seeds = append([]appliedSeed{}, p.Seeds...)
+	copied.Insights = append([]projectInsight{}, p.Insights...)
+	copied.Sessions = make([]packet.Session, 0, len(p.Sessions))
+	for _, session := range p.Sessions {
+		session.Calls = append([]packet.Call{}, session.Calls...)
+		copied.Sessions = append(copied.Sessions, session)
+	}
+	return copied
+}
+
+// replace stores a whole journal, such as one restored from a packet
+func (ps *projects) replace(p project) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.byID[p.ID] = &p
+}
+
+// remove drops a project's journal
+func (ps *projects) remove(id string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	delete(ps.byID, id)
+}
+
+// describe sets a project's description
+func (ps *projects) describe(name, description string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.open(name).Description = description
+}
+
+// recordRoom notes a thinking room opened for a project; opening a room on
+// the same topic again revises it
+func (ps *projects) recordRoom(name, topic, agentName string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p := ps.open(name)
+	now := ps.now().UTC()
+	for i, r := range p.Rooms {
+		if strings.EqualFold(strings.TrimSpace(r.Topic), strings.TrimSpace(topic)) {
+			p.Rooms[i].AgentName = agentName
+			p.Rooms[i].UpdatedAt = now
+			p.Rooms[i].Version++
+			return
+		}
+	}
+	p.Rooms = append(p.Rooms, projectRoom{ID: newID("room"), Topic: topic, AgentName: agentName, CreatedAt: now, UpdatedAt: now, Version: 1})
+}
+
+// recordSeed notes a seed applied in a project
+func (ps *projects) recordSeed(name string, seed wisdom.Seed, situation string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p := ps.open(name)
+	p.Seeds = append(p.Seeds, appliedSeed{
+		SeedUse: packet.SeedUse{
+			Name:      seed.Name,
+			Version:   seed.Version,
+			Hash:      seed.Hash,
+			Situation: situation,
+			AppliedAt: ps.now().UTC(),
+		},
+		Checklist: checklistItems(seed.Content),
+	})
+}
+
+// recordInsight notes an insight recorded or revised in a project
+func (ps *projects) recordInsight(name, id string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p := ps.open(name)
+	for i, insight := range p.Insights {
+		if insight.ID == id {
+			p.Insights[i].Version++
+			return
+		}
+	}
+	p.Insights = append(p.Insights, projectInsight{ID: id, Version: 1})
+}
+
+// recordCall adds a tool call to the trace of an existing project, dropping
+// the oldest calls and sessions past the caps. It reports whether the
+// project exists.
+func (ps *projects) recordCall(name, session string, call packet.Call) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.byID[projectID(strings.TrimSpace(name))]
+	if !ok {
+		return false
+	}
+	call.At = ps.now().UTC()
+	p.addCall(session, call)
+	ps.unsaved = append(ps.unsaved, tracedCall{id: p.ID, session: session, call: call})
+	if len(ps.unsaved) > maxUnsavedCalls {
+		ps.unsaved = append([]tracedCall{}, ps.unsaved[len(ps.unsaved)-maxUnsavedCalls:]...)
+	}
+	return true
+}
+
+// addCall adds a call to a session of the trace, dropping the oldest calls
+// and sessions past the caps
+func (p *project) addCall(session string, call packet.Call) {
+	for i := range p.Sessions {
+		if p.Sessions[i].ID == session {
+			p.Sessions[i].EndedAt = call.At
+			p.Sessions[i].Calls = append(p.Sessions[i].Calls, call)
+			if n := len(p.Sessions[i].Calls); n > maxSessionCalls {
+				p.Sessions[i].Calls = append([]packet.Call{}, p.Sessions[i].Calls[n-maxSessionCalls:]...)
+			}
+			return
+		}
+	}
+	p.Sessions = append(p.Sessions, packet.Session{ID: session, StartedAt: call.At, EndedAt: call.At, Calls: []packet.Call{call}})
+	if n := len(p.Sessions); n > maxTraceSessions {
+		p.Sessions = append([]packet.Session{}, p.Sessions[n-maxTraceSessions:]...)
+	}
+}
+
+// reread replaces the journals with those reread from the store, then
+// applies the traced calls not yet saved over them
+func (ps *projects) reread(list []project) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.byID = make(map[string]*project, len(list))
+	for i := range list {
+		ps.byID[list[i].ID] = &list[i]
+	}
+	for _, traced := range ps.unsaved {
+		if p, ok := ps.byID[traced.id]; ok {
+			p.addCall(traced.session, traced.call)
+		}
+	}
+}
+
+// listForSave returns every journal, as list does, and how many of the
+// unsaved traced calls they hold
+func (ps *projects) listForSave() ([]project, int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	return ps.copyAll(), len(ps.unsaved)
+}
+
+// saved forgets the first n unsaved traced calls, once they are saved
+func (ps *projects) saved(n int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.unsaved = append([]tracedCall{}, ps.unsaved[n:]...)
+}
+
+// checklistItems extracts the checklist of a seed
+func checklistItems(content string) []string {
+	section, ok := wisdom.Section(content, "Checklist for Application")
+	if !ok {
+		return nil
+	}
+	items := []string{}
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimSpace(line)
+		for _, box := range []string{"- [ ] ", "- [x] ", "- [X] "} {
+			if strings.HasPrefix(line, box) {
+				items = append(items, strings.TrimSpace(strings.TrimPrefix(line, box)))
+			}
+		}
+	}
+	return items
+}
+
+// loadProjects restores project journals from the store
+func (h *Handler) loadProjects() {
+	var list []project
+	if _, err := h.store.Load(projectsDocument, &list); err != nil {
+		log.Printf("Could not load projects: %v", err)
+		return
+	}
+	h.projects.mu.Lock()
+	defer h.projects.mu.Unlock()
+	for i := range list {
+		h.projects.byID[list[i].ID] = &list[i]
+	}
+}
+
+// updateProjects rereads the project journals, applies change to them and
+// saves them, holding the store's lock throughout so journals changed by
+// other processes sharing the data directory are kept
+func (h *Handler) updateProjects(ctx context.Context, change func() error) error {
+	var list []project
+	var saved int
+	write, err := h.store.Update(projectsDocument, &list, func() error {
+		h.projects.reread(list)
+		if err := change(); err != nil {
+			return err
+		}
+		list, saved = h.projects.listForSave()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.projects.saved(saved)
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+// scheduleTraceSave saves the journals once traced calls have gathered for
+// traceSaveDelay, unless a save is already pending
+func (h *Handler) scheduleTraceSave() {
+	h.projects.traceMu.Lock()
+	defer h.projects.traceMu.Unlock()
+
+	if h.projects.traceSave == nil {
+		h.projects.traceSave = time.AfterFunc(traceSaveDelay, h.flushTraces)
+	}
+}
+
+// flushTraces saves the journals now if traced calls are waiting to be
+// saved
+func (h *Handler) flushTraces() {
+	h.projects.traceMu.Lock()
+	pending := h.projects.traceSave != nil
+	if pending {
+		h.projects.traceSave.Stop()
+		h.projects.traceSave = nil
+	}
+	h.projects.traceMu.Unlock()
+
+	if !pending {
+		return
+	}
+	if err := h.updateProjects(context.Background(), func() error { return nil }); err != nil {
+		log.Printf("Could not save project traces: %v", err)
+	}
+}
+
+// traceProject adds a tool call that names a project to the project's trace.
+// The journals are saved shortly after, together with the calls that follow.
+// Calls naming a project that has not been started are not traced.
+func (h *Handler) traceProject(ctx context.Context, request mcp.CallToolRequest) {
+	name, _ := request.Params.Arguments["project"].(string)
+	if strings.TrimSpace(name) == "" {
+		return
+	}
+
+	call := packet.Call{Tool: request.Params.Name, Words: argumentWords(request.Params.Arguments), RestOverride: restOverride(ctx)}
+	if !h.projects.recordCall(name, sessionID(ctx), call) || h.store.ReadOnly() {
+		return
+	}
+	h.scheduleTraceSave()
+}
@@ -0,0 +1,149 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/packet"
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+)
+
+func TestTracedCallsAreSavedTogether(t *testing.T) {
+	defer func(delay time.Duration) { traceSaveDelay = delay }(traceSaveDelay)
+	traceSaveDelay = time.Hour
+
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithStore(st))
+	if err := h.updateProjects(context.Background(), func() error {
+		h.projects.describe("Atlas", "")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if text, isError := callTool(t, h.guard(h.handleRecall), "dojo.recall", map[string]interface{}{"project": "Atlas"}); isError {
+			t.Fatalf("recall failed: %s", text)
+		}
+	}
+
+	calls := func() int {
+		reader, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list []project
+		if _, err := reader.Load(projectsDocument, &list); err != nil {
+			t.Fatal(err)
+		}
+		count := 0
+		for _, p := range list {
+			for _, session := range p.Sessions {
+				count += len(session.Calls)
+			}
+		}
+		return count
+	}
+	if n := calls(); n != 0 {
+		t.Fatalf("%d traced calls were saved one by one", n)
+	}
+	h.Close()
+	if n := calls(); n != 3 {
+		t.Fatalf("closing saved %d of 3 traced calls", n)
+	}
+}
+
+func TestTracesAreCapped(t *testing.T) {
+	ps := newProjects()
+	ps.describe("Atlas", "")
+	for i := 0; i < maxSessionCalls+10; i++ {
+		ps.recordCall("Atlas", "first", packet.Call{Tool: "dojo.recall"})
+	}
+	for i := 0; i < maxTraceSessions+5; i++ {
+		ps.recordCall("Atlas", fmt.Sprintf("session_%d", i), packet.Call{Tool: "dojo.recall"})
+	}
+
+	p, _ := ps.get("Atlas")
+	if len(p.Sessions) != maxTraceSessions {
+		t.Fatalf("kept %d sessions, want %d", len(p.Sessions), maxTraceSessions)
+	}
+	if last := p.Sessions[len(p.Sessions)-1].ID; last != fmt.Sprintf("session_%d", maxTraceSessions+4) {
+		t.Fatalf("the newest session was dropped, last is %s", last)
+	}
+
+	ps = newProjects()
+	ps.describe("Atlas", "")
+	for i := 0; i < maxSessionCalls+10; i++ {
+		ps.recordCall("Atlas", "only", packet.Call{Tool: "dojo.recall"})
+	}
+	p, _ = ps.get("Atlas")
+	if n := len(p.Sessions[0].Calls); n != maxSessionCalls {
+		t.Fatalf("kept %d calls, want %d", n, maxSessionCalls)
+	}
+}
+
+func TestJournalsChangedElsewhereAreKept(t *testing.T) {
+	defer func(delay time.Duration) { traceSaveDelay = delay }(traceSaveDelay)
+	traceSaveDelay = time.Hour
+
+	dir := t.TempDir()
+	open := func() *Handler {
+		st, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newTestHandler(t, WithStore(st))
+	}
+	first, second := open(), open()
+	room := func(h *Handler, topic string) {
+		t.Helper()
+		if text, isError := callTool(t, h.guard(h.handleCreateThinkingRoom), "dojo.create_thinking_room", map[string]interface{}{"topic": topic, "project": "Atlas"}); isError {
+			t.Fatalf("create_thinking_room failed: %s", text)
+		}
+	}
+	room(first, "Caching strategy")
+	room(second, "Release cadence")
+	// A call traced by the first process, not yet saved, outlives the
+	// journals being reread
+	if text, isError := callTool(t, first.guard(first.handleRecall), "dojo.recall", map[string]interface{}{"project": "Atlas"}); isError {
+		t.Fatalf("recall failed: %s", text)
+	}
+	room(second, "Error budgets")
+	room(first, "Caching strategy")
+	first.Close()
+
+	reader, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []project
+	if _, err := reader.Load(projectsDocument, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("stored %d projects, want 1", len(list))
+	}
+	topics := []string{}
+	for _, r := range list[0].Rooms {
+		topics = append(topics, fmt.Sprintf("%s v%d", r.Topic, r.Version))
+	}
+	if fmt.Sprint(topics) != "[Caching strategy v2 Release cadence v1 Error budgets v1]" {
+		t.Fatalf("stored rooms %v", topics)
+	}
+	recalls := 0
+	for _, session := range list[0].Sessions {
+		for _, call := range session.Calls {
+			if call.Tool == "dojo.recall" {
+				recalls++
+			}
+		}
+	}
+	if recalls != 1 {
+		t.Fatalf("stored %d traced recalls, want 1", recalls)
+	}
+}
@@ -0,0 +1,135 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// dojoFraming opens every Dojo conversation, whether sent to the client's
+// model via sampling or returned from a prompt
+const dojoFraming = "You are Dojo, a thinking partner. You reflect the user's thinking back to them; you never originate new perspectives, never gamify thinking and never act as an oracle."
+
+// framingMessage is the system-style first message of a prompt
+// conversation, carrying the Dojo principles. MCP prompts have no system
+// role, so it is sent as a user message.
+func (h *Handler) framingMessage() mcp.PromptMessage {
+	return mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf("%s\n\n%s", dojoFraming, h.wisdomBase.GetPrinciples())))
+}
+
+// seedPrompt renders a dojo.seed.* prompt: the framing, the seed (or one
+// section of it) and the user's situation
+func (h *Handler) seedPrompt(seed *wisdom.Seed, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	content := seed.Content
+	if focus := strings.TrimSpace(arguments["focus_section"]); focus != "" {
+		section, ok := wisdom.Section(seed.Content, focus)
+		if !ok {
+			return nil, fmt.Errorf("section %q not found in seed %s; available sections: %s",
+				focus, seed.Name, strings.Join(wisdom.Headings(seed.Content), ", "))
+		}
+		content = section
+	}
+
+	var situation strings.Builder
+	if project := strings.TrimSpace(arguments["project"]); project != "" {
+		situation.WriteString(fmt.Sprintf("**Project:** %s\n\n", project))
+	}
+	if text := strings.TrimSpace(arguments["situation"]); text != "" {
+		situation.WriteString(fmt.Sprintf("**Situation:** %s\n\n", text))
+		situation.WriteString("Help me see how this seed applies to my situation. Reflect my own thinking back to me, and work through it with me rather than deciding for me.")
+	} else {
+		situation.WriteString("Help me explore where this seed might apply in my work. Ask me about my situation before drawing any connections.")
+	}
+
+	return &mcp.GetPromptResult{
+		Description: seed.Description,
+		Messages: []mcp.PromptMessage{
+			h.framingMessage(),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(fmt.Sprintf("Here is the Dojo seed patch `%s`:\n\n%s", seed.Name, content))),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(situation.String())),
+		},
+	}, nil
+}
+
+// seedSectionExample picks a section title to show in argument descriptions
+func seedSectionExample(seed wisdom.Seed) string {
+	headings := wisdom.Headings(seed.Content)
+	for _, heading := range headings {
+		if strings.HasPrefix(heading, "Checklist") {
+			return heading
+		}
+	}
+	if len(headings) > 1 {
+		return headings[1]
+	}
+	return "Checklist for Application"
+}
+
+// modePrompt renders a dojo.mode.* prompt: the framing, the mode's output
+// contract and the user's situation and perspectives
+func (h *Handler) modePrompt(mode string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	situation := strings.TrimSpace(arguments["situation"])
+	if situation == "" {
+		return nil, fmt.Errorf("situation is required")
+	}
+
+	contract, err := h.modeContractText(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mcp.GetPromptResult{
+		Description: h.modePromptDescription(mode),
+		Messages: []mcp.PromptMessage{
+			h.framingMessage(),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(contract)),
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(reflectionRequestText(situation, splitPerspectives(arguments["perspectives"]), mode))),
+		},
+	}, nil
+}
+
+// modeContractText renders a mode's output contract from the four_modes
+// resource together with the headings the validator expects
+func (h *Handler) modeContractText(mode string) (string, error) {
+	guide, err := h.wisdomBase.ModeGuide(mode)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("# Output Contract\n\nRespond only in %s mode and follow this description exactly, including the counts in its Output list.\n\n%s\n\n%s",
+		strings.ToUpper(mode), guide, contractInstructions(mode)), nil
+}
+
+// modePromptDescription describes a mode prompt using the mode's purpose
+// from the four_modes resource
+func (h *Handler) modePromptDescription(mode string) string {
+	description := fmt.Sprintf("Run a %s pass on a situation and its perspectives.", strings.ToUpper(mode))
+	guide, err := h.wisdomBase.ModeGuide(mode)
+	if err != nil {
+		return description
+	}
+	for _, line := range strings.Split(guide, "\n") {
+		if purpose, ok := strings.CutPrefix(line, "**Purpose:** "); ok {
+			return fmt.Sprintf("%s %s", description, purpose)
+		}
+	}
+	return description
+}
+
+// splitPerspectives splits a prompt argument into perspectives. Prompt
+// arguments are plain strings, so perspectives arrive one per line or
+// separated by semicolons.
+func splitPerspectives(text string) []string {
+	perspectives := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		for _, part := range strings.Split(line, ";") {
+			part = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(part), "-*"))
+			if part != "" {
+				perspectives = append(perspectives, part)
+			}
+		}
+	}
+	return perspectives
+}
@@ -0,0 +1,369 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RouteRule describes an agent role the supervisor can route to, as in the
+// agent_connect seed. Keywords are case-insensitive words or phrases that
+// each add one point; patterns are case-insensitive regular expressions for
+// intents that each add two.
+type RouteRule struct {
+	Role        string   `json:"role"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords"`
+	Patterns    []string `json:"patterns"`
+	Tools       []string `json:"tools"`
+}
+
+// RouteDecision records one routing decision for audit
+type RouteDecision struct {
+	ID         string             `json:"id"`
+	Time       time.Time          `json:"time"`
+	Session    string             `json:"session"`
+	Project    string             `json:"project,omitempty"`
+	Query      string             `json:"query"`
+	Role       string             `json:"role"`
+	Confidence float64            `json:"confidence"`
+	Scores     map[string]float64 `json:"scores"`
+	Matched    []string           `json:"matched"`
+}
+
+// routingLogDocument is the store document holding routing decisions
+const routingLogDocument = "routing_log"
+
+// maxRouteDecisions caps how many routing decisions are kept
+const maxRouteDecisions = 500
+
+// Points a keyword and an intent pattern add to a role's score
+const (
+	keywordWeight = 1.0
+	patternWeight = 2.0
+)
+
+// DefaultRoutingRules returns the four agents of the agent_connect seed.
+// The first rule is the fallback when nothing matches, so queries that
+// give no signal go to the Dojo.
+func DefaultRoutingRules() []RouteRule {
+	return []RouteRule{
+		{
+			Role:        "dojo",
+			Description: "Reflection, perspective-taking and deciding how to think about a situation",
+			Keywords:    []string{"reflect", "perspective", "decide", "decision", "unsure", "stuck", "feel", "values", "tradeoff", "trade-off", "dilemma", "meaning", "pace", "overwhelm"},
+			Patterns: []string{
+				`should (i|we)\b`,
+				`help me (think|see|understand|decide)`,
+				`what (matters|am i missing)`,
+				`(weigh|torn between|choose between)`,
+			},
+			Tools: []string{"dojo.reflect", "dojo.create_thinking_room", "dojo.apply_seed", "dojo.practice_start", "dojo.check_pace"},
+		},
+		{
+			Role:        "librarian",
+			Description: "Semantic search and retrieval from the knowledge base",
+			Keywords:    []string{"find", "search", "lookup", "look up", "where", "reference", "source", "cite", "citation", "seed", "resource", "remember", "recall", "lineage", "documentation", "docs"},
+			Patterns: []string{
+				`(where|what) (is|are|was|were) (the|our|my)\b`,
+				`(find|show|get) me\b`,
+				`what do we know about`,
+				`who (said|wrote|came up with)`,
+			},
+			Tools: []string{"dojo.search_wisdom", "dojo.get_seed", "dojo.list_seeds", "dojo.recall", "dojo.trace_lineage", "dojo.cite"},
+		},
+		{
+			Role:        "debugger",
+			Description: "Resolving conflicting perspectives or logical errors",
+			Keywords:    []string{"conflict", "contradict", "disagree", "inconsistent", "error", "bug", "debug", "broken", "fails", "failing", "wrong", "fallacy", "flaw", "doesn't add up", "mismatch"},
+			Patterns: []string{
+				`(why|how come) (does|is|did|won'?t|doesn'?t|isn'?t)\b.*\b(work|fail|break)`,
+				`(these|they|we|the two) (don'?t|do not) agree`,
+				`something('s| is) (off|wrong)`,
+				`(check|validate) (my|this|the) (logic|reasoning|reflection)`,
+			},
+			Tools: []string{"dojo.resolve_conflict", "dojo.validate_reflection", "dojo.reflect", "dojo.plan_recover", "dojo.log_progress"},
+		},
+		{
+			Role:        "builder",
+			Description: "Code generation and execution",
+			Keywords:    []string{"build", "implement", "code", "write", "generate", "create", "deploy", "ship", "refactor", "script", "function", "plan", "task", "export", "package"},
+			Patterns: []string{
+				`(write|generate|create) (a|an|the|some)\b`,
+				`(set|spin) up\b`,
+				`turn (this|it) into`,
+				`(next|first) steps?`,
+			},
+			Tools: []string{"dojo.plan_init", "dojo.plan_update_phase", "dojo.add_finding", "dojo.log_progress", "dojo.apply_seed", "dojo.export_packet"},
+		},
+	}
+}
+
+// LoadRoutingRules reads a JSON array of routing rules from a file
+func LoadRoutingRules(path string) ([]RouteRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing rules: %w", err)
+	}
+
+	var rules []RouteRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse routing rules: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("routing rules must name at least one role")
+	}
+	for _, rule := range rules {
+		if strings.TrimSpace(rule.Role) == "" {
+			return nil, fmt.Errorf("every routing rule needs a role")
+		}
+		if _, err := compileRoute(rule); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// WithRoutingRules replaces the default routing rule set. An empty rule
+// set keeps the defaults. It panics if a pattern is not a valid regular
+// expression; LoadRoutingRules rejects such rules.
+func WithRoutingRules(rules []RouteRule) Option {
+	return func(h *Handler) {
+		if len(rules) > 0 {
+			h.router = newRouter(rules)
+		}
+	}
+}
+
+// router scores queries against the routing rules and keeps the decision log
+type router struct {
+	rules     []compiledRoute
+	mu        sync.Mutex
+	decisions []RouteDecision
+	now       func() time.Time
+}
+
+type compiledRoute struct {
+	rule     RouteRule
+	keywords []*regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+func newRouter(rules []RouteRule) *router {
+	r := &router{now: time.Now}
+	for _, rule := range rules {
+		compiled, err := compileRoute(rule)
+		if err != nil {
+			panic(err)
+		}
+		r.rules = append(r.rules, compiled)
+	}
+	return r
+}
+
+// compileRoute compiles a rule's keywords and intent patterns
+func compileRoute(rule RouteRule) (compiledRoute, error) {
+	compiled := compiledRoute{rule: rule}
+	for _, keyword := range rule.Keywords {
+		if strings.TrimSpace(keyword) == "" {
+			continue
+		}
+		// Keywords match at the start of a word, so "debug" finds "debugging"
+		compiled.keywords = append(compiled.keywords, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(strings.TrimSpace(keyword))))
+	}
+	for _, pattern := range rule.Patterns {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return compiledRoute{}, fmt.Errorf("invalid pattern %q in route %q: %w", pattern, rule.Role, err)
+		}
+		compiled.patterns = append(compiled.patterns, re)
+	}
+	return compiled, nil
+}
+
+// route classifies a query. Confidence grows with the winning role's share
+// of all points and with how many points it has, so one stray keyword
+// never reads as certainty.
+func (r *router) route(query string) (RouteRule, RouteDecision) {
+	decision := RouteDecision{Query: query, Scores: map[string]float64{}, Matched: []string{}}
+	best, total := 0, 0.0
+	for i, compiled := range r.rules {
+		score := 0.0
+		for _, re := range compiled.keywords {
+			if match := re.FindString(query); match != "" {
+				score += keywordWeight
+				decision.Matched = append(decision.Matched, fmt.Sprintf("%s: %q", compiled.rule.Role, strings.ToLower(match)))
+			}
+		}
+		for _, re := range compiled.patterns {
+			if match := re.FindString(query); match != "" {
+				score += patternWeight
+				decision.Matched = append(decision.Matched, fmt.Sprintf("%s: intent %q", compiled.rule.Role, strings.ToLower(match)))
+			}
+		}
+		decision.Scores[compiled.rule.Role] = score
+		total += score
+		if score > decision.Scores[r.rules[best].rule.Role] {
+			best = i
+		}
+	}
+
+	rule := r.rules[best].rule
+	decision.Role = rule.Role
+	if total == 0 {
+		decision.Confidence = math.Round(100/float64(len(r.rules))) / 100
+	} else {
+		top := decision.Scores[rule.Role]
+		decision.Confidence = math.Round(top/total*(1-math.Pow(0.5, top))*100) / 100
+	}
+	return rule, decision
+}
+
+// record keeps a routing decision, dropping the oldest past the cap
+func (r *router) record(decision RouteDecision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decisions = append(r.decisions, decision)
+	if len(r.decisions) > maxRouteDecisions {
+		r.decisions = r.decisions[len(r.decisions)-maxRouteDecisions:]
+	}
+}
+
+// replace swaps in every decision, such as those reread from the store
+func (r *router) replace(decisions []RouteDecision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decisions = decisions
+}
+
+// all copies every decision so they can be saved without holding the lock
+func (r *router) all() []RouteDecision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]RouteDecision{}, r.decisions...)
+}
+
+// snapshot returns the rule set, decision counts per role and the decisions
+func (r *router) snapshot() map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rules := make([]RouteRule, 0, len(r.rules))
+	counts := map[string]int{}
+	for _, compiled := range r.rules {
+		rules = append(rules, compiled.rule)
+		counts[compiled.rule.Role] = 0
+	}
+	for _, decision := range r.decisions {
+		counts[decision.Role]++
+	}
+
+	return map[string]interface{}{
+		"rules":     rules,
+		"counts":    counts,
+		"decisions": append([]RouteDecision{}, r.decisions...),
+	}
+}
+
+// loadRoutingLog restores routing decisions from the store
+func (h *Handler) loadRoutingLog() {
+	var decisions []RouteDecision
+	if _, err := h.store.Load(routingLogDocument, &decisions); err != nil {
+		log.Printf("Could not load the routing log: %v", err)
+		return
+	}
+	h.router.mu.Lock()
+	defer h.router.mu.Unlock()
+	h.router.decisions = decisions
+}
+
+// recordRoute keeps a routing decision, rereading and saving the log under
+// the store's lock so decisions made by other processes sharing the data
+// directory are kept. A read-only store keeps it in memory only.
+func (h *Handler) recordRoute(ctx context.Context, decision RouteDecision) error {
+	if h.store.ReadOnly() {
+		h.router.record(decision)
+		return nil
+	}
+	var decisions []RouteDecision
+	write, err := h.store.Update(routingLogDocument, &decisions, func() error {
+		h.router.replace(decisions)
+		h.router.record(decision)
+		decisions = h.router.all()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+func (h *Handler) handleRoute(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Query   string `json:"query"`
+		Project string `json:"project"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return mcp.NewToolResultError("Invalid arguments: query is required"), nil
+	}
+
+	rule, decision := h.router.route(args.Query)
+	decision.ID = newID("route")
+	decision.Time = h.router.now().UTC()
+	decision.Session = sessionID(ctx)
+	if strings.TrimSpace(args.Project) != "" {
+		decision.Project = projectID(strings.TrimSpace(args.Project))
+	}
+	if err := h.recordRoute(ctx, decision); err != nil {
+		log.Printf("Could not save the routing log: %v", err)
+	}
+
+	result := map[string]interface{}{
+		"decision":    decision.ID,
+		"role":        rule.Role,
+		"description": rule.Description,
+		"confidence":  decision.Confidence,
+		"tools":       rule.Tools,
+		"scores":      decision.Scores,
+		"matched":     decision.Matched,
+	}
+	if decision.Confidence < 0.5 {
+		result["note"] = "Confidence is low. The supervisor may want to ask what kind of help is wanted before handing off."
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (h *Handler) handleRoutingLog(ctx context.Context, request mcp.ReadResourceRequest) ([]interface{}, error) {
+	logJSON, err := json.MarshalIndent(h.router.snapshot(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return []interface{}{
+		mcp.TextResourceContents{
+			ResourceContents: mcp.ResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "application/json",
+			},
+			Text: string(logJSON),
+		},
+	}, nil
+}
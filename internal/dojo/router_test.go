@@ -0,0 +1,104 @@
+package dojo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+)
+
+func TestRoute(t *testing.T) {
+	r := newRouter(DefaultRoutingRules())
+	for _, tc := range []struct {
+		query      string
+		role       string
+		confidence float64
+	}{
+		// No signal falls back to the first rule, at even odds
+		{"The weather today", "dojo", 0.25},
+		// One keyword alone is never certain
+		{"I feel it", "dojo", 0.5},
+		{"Find me the citation for the lineage seed", "librarian", 0.98},
+		{"Write a script to deploy the service", "builder", 0.97},
+		// A pattern outweighs another role's keyword
+		{"Why does the export fail?", "debugger", 0.5},
+		{"Should we refactor the cache?", "dojo", 0.5},
+	} {
+		rule, decision := r.route(tc.query)
+		if rule.Role != tc.role || decision.Role != tc.role || decision.Confidence != tc.confidence {
+			t.Errorf("%q routed to %s at %.2f, want %s at %.2f (%v)", tc.query, decision.Role, decision.Confidence, tc.role, tc.confidence, decision.Matched)
+		}
+	}
+}
+
+func TestRouteFallsBackToTheFirstRule(t *testing.T) {
+	r := newRouter([]RouteRule{
+		{Role: "triage", Keywords: []string{"help"}},
+		{Role: "coder", Keywords: []string{"code"}},
+	})
+	if rule, decision := r.route("Good morning"); rule.Role != "triage" || decision.Confidence != 0.5 {
+		t.Fatalf("got %s at %.2f", rule.Role, decision.Confidence)
+	}
+	if rule, _ := r.route("Code review, please"); rule.Role != "coder" {
+		t.Fatalf("got %s", rule.Role)
+	}
+}
+
+func TestLoadRoutingRulesRejectsInvalidPatterns(t *testing.T) {
+	dir := t.TempDir()
+	for name, rules := range map[string]string{
+		"no roles":        `[]`,
+		"unnamed role":    `[{"keywords": ["code"]}]`,
+		"invalid pattern": `[{"role": "coder", "patterns": ["(unclosed"]}]`,
+	} {
+		path := filepath.Join(dir, strings.ReplaceAll(name, " ", "_")+".json")
+		if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRoutingRules(path); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+
+	path := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(path, []byte(`[{"role": "coder", "patterns": ["write (a|the) \\w+"]}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if rules, err := LoadRoutingRules(path); err != nil || len(rules) != 1 {
+		t.Fatalf("valid rules: %v, %v", rules, err)
+	}
+}
+
+func TestRoutingLogKeepsOtherProcessesDecisions(t *testing.T) {
+	dir := t.TempDir()
+	open := func() *Handler {
+		st, err := store.Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return newTestHandler(t, WithStore(st))
+	}
+	first, second := open(), open()
+	for _, h := range []*Handler{first, second, first} {
+		if text, isError := callTool(t, h.guard(h.handleRoute), "dojo.route", map[string]interface{}{"query": "Find the seed"}); isError {
+			t.Fatalf("route failed: %s", text)
+		}
+	}
+
+	var decisions []RouteDecision
+	if _, err := first.store.Load(routingLogDocument, &decisions); err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 3 {
+		t.Fatalf("stored %d decisions, want 3", len(decisions))
+	}
+	if err := first.recordRoute(context.Background(), RouteDecision{ID: "route_last", Role: "dojo"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(first.router.all()); n != 4 {
+		t.Fatalf("the log in memory holds %d decisions, want 4", n)
+	}
+}
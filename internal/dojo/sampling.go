@@ -0,0 +1,164 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// modes lists the four Dojo modes in their canonical order
+var modes = []string{"mirror", "scout", "gardener", "implementation"}
+
+// isMode reports whether name is one of the four Dojo modes
+func isMode(name string) bool {
+	for _, mode := range modes {
+		if mode == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampler asks the connected client's model to generate a message
+type Sampler interface {
+	SupportsSampling() bool
+	CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+}
+
+// samplerFromContext returns the client session if it advertised sampling
+func samplerFromContext(ctx context.Context) Sampler {
+	session := transport.SessionFromContext(ctx)
+	if session == nil || !session.SupportsSampling() {
+		return nil
+	}
+	return session
+}
+
+// maxRelevantSeeds limits how many seeds are added to the sampling prompt
+const maxRelevantSeeds = 3
+
+// sampleReflection asks the client's model to write a reflection in the
+// given mode, constrained by the mode's output contract
+func (h *Handler) sampleReflection(ctx context.Context, sampler Sampler, situation string, perspectives []string, mode string) (string, error) {
+	systemPrompt, err := h.reflectionSystemPrompt(situation, mode)
+	if err != nil {
+		return "", err
+	}
+
+	var request mcp.CreateMessageRequest
+	request.Params.SystemPrompt = systemPrompt
+	request.Params.MaxTokens = 800
+	request.Params.Temperature = 0.4
+	request.Params.IncludeContext = "none"
+	request.Params.Messages = []mcp.SamplingMessage{
+		{
+			Role:    mcp.RoleUser,
+			Content: mcp.NewTextContent(reflectionRequestText(situation, perspectives, mode)),
+		},
+	}
+
+	result, err := sampler.CreateMessage(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	text := samplingText(result)
+	if text == "" {
+		return "", fmt.Errorf("client returned no text content")
+	}
+
+	if report := ValidateReflection(mode, text); !report.Valid {
+		return "", fmt.Errorf("sampled reflection broke the %s contract: %s", mode, report.Violations[0].Message)
+	}
+
+	return text, nil
+}
+
+// reflectionSystemPrompt combines the Dojo principles, the mode's output
+// contract from the four_modes resource and the most relevant seeds
+func (h *Handler) reflectionSystemPrompt(situation, mode string) (string, error) {
+	contract, err := h.modeContractText(mode)
+	if err != nil {
+		return "", err
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(dojoFraming + "\n\n")
+	prompt.WriteString(h.wisdomBase.GetPrinciples())
+	prompt.WriteString("\n\n")
+	prompt.WriteString(contract)
+
+	seeds := h.relevantSeeds(situation)
+	if len(seeds) > 0 {
+		prompt.WriteString("\n\n# Relevant Seed Patches\n")
+		for _, seed := range seeds {
+			prompt.WriteString(fmt.Sprintf("\n## %s\n\n%s\n\n**When it applies:** %s\n", seed.Name, seed.Description, seed.Triggers))
+		}
+	}
+
+	return prompt.String(), nil
+}
+
+// relevantSeeds returns the seeds that best match the situation
+func (h *Handler) relevantSeeds(situation string) []seedSummary {
+	results := h.wisdomBase.Search(situation)
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Relevance > results[j].Relevance
+	})
+
+	seeds := []seedSummary{}
+	for _, result := range results {
+		if result.Type != "seed" {
+			continue
+		}
+		seed, err := h.wisdomBase.GetSeed(result.Name)
+		if err != nil {
+			continue
+		}
+		seeds = append(seeds, seedSummary{Name: seed.Name, Description: seed.Description, Triggers: seed.Triggers})
+		if len(seeds) == maxRelevantSeeds {
+			break
+		}
+	}
+	return seeds
+}
+
+// seedSummary is the part of a seed included in sampling prompts
+type seedSummary struct {
+	Name        string
+	Description string
+	Triggers    string
+}
+
+// reflectionRequestText renders the user's situation and perspectives
+func reflectionRequestText(situation string, perspectives []string, mode string) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Apply %s mode.\n\nSituation: %s\n\nPerspectives:\n", strings.ToUpper(mode), situation))
+	if len(perspectives) == 0 {
+		text.WriteString("- (none provided)\n")
+	}
+	for _, perspective := range perspectives {
+		text.WriteString(fmt.Sprintf("- %s\n", perspective))
+	}
+	return text.String()
+}
+
+// samplingText extracts the text from a sampling result, whose content is
+// decoded generically from JSON
+func samplingText(result *mcp.CreateMessageResult) string {
+	switch content := result.Content.(type) {
+	case mcp.TextContent:
+		return strings.TrimSpace(content.Text)
+	case map[string]interface{}:
+		if content["type"] == "text" {
+			if text, ok := content["text"].(string); ok {
+				return strings.TrimSpace(text)
+			}
+		}
+	}
+	return ""
+}
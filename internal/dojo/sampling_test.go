@@ -0,0 +1,172 @@
+package dojo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sampledMirror is a reflection that keeps the mirror contract, as a
+// client's model would write it
+const sampledMirror = `**Pattern across perspectives:**
+- Both perspectives want the launch to land well.
+- Each names a different cost of waiting.
+- Neither doubts the team's ability.
+
+**Assumptions/tensions identified:**
+1. Speed and polish are treated as opposites.
+
+**Reframe:**
+What would a launch that is both early and careful look like?`
+
+// samplingClient talks to Dojo over the stdio transport, answering
+// its sampling requests
+type samplingClient struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan map[string]interface{}
+}
+
+func startSamplingClient(t *testing.T, h *Handler, sampling bool) *samplingClient {
+	t.Helper()
+	s := server.NewMCPServer("dojo", "1.0.0")
+	h.RegisterTools(s)
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &samplingClient{t: t, in: inW, lines: make(chan map[string]interface{}, 16)}
+	stdio := transport.NewStdioServer(s)
+	stdio.SetErrorLogger(log.New(io.Discard, "", 0))
+	go func() {
+		stdio.Listen(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			var message map[string]interface{}
+			if json.Unmarshal(scanner.Bytes(), &message) == nil {
+				c.lines <- message
+			}
+		}
+		close(c.lines)
+	}()
+	t.Cleanup(func() { inW.Close() })
+
+	capabilities := map[string]interface{}{}
+	if sampling {
+		capabilities["sampling"] = map[string]interface{}{}
+	}
+	c.send(map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"capabilities":    capabilities,
+		"clientInfo":      map[string]interface{}{"name": "fake", "version": "1.0.0"},
+	}})
+	c.next()
+	return c
+}
+
+func (c *samplingClient) send(message map[string]interface{}) {
+	c.t.Helper()
+	message["jsonrpc"] = "2.0"
+	data, _ := json.Marshal(message)
+	if _, err := c.in.Write(append(data, '\n')); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *samplingClient) next() map[string]interface{} {
+	c.t.Helper()
+	select {
+	case message, ok := <-c.lines:
+		if !ok {
+			c.t.Fatal("server closed its output")
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("no message from the server")
+	}
+	return nil
+}
+
+// reflect calls dojo.reflect in mirror mode, answering a sampling request
+// with answer, or with an error when answer is empty, and returns the tool
+// result's text along with whether sampling was requested
+func (c *samplingClient) reflect(answer string) (string, bool) {
+	c.t.Helper()
+	c.send(map[string]interface{}{"id": 2, "method": "tools/call", "params": map[string]interface{}{
+		"name": "dojo.reflect",
+		"arguments": map[string]interface{}{
+			"situation":    "Should we launch this week or next month?",
+			"perspectives": []string{"Ship now", "Polish first"},
+			"mode":         "mirror",
+		},
+	}})
+
+	sampled := false
+	for {
+		message := c.next()
+		if message["method"] == "sampling/createMessage" {
+			sampled = true
+			reply := map[string]interface{}{"id": message["id"]}
+			if answer == "" {
+				reply["error"] = map[string]interface{}{"code": -32603, "message": "user declined"}
+			} else {
+				reply["result"] = map[string]interface{}{
+					"role":    "assistant",
+					"content": map[string]interface{}{"type": "text", "text": answer},
+					"model":   "fake",
+				}
+			}
+			c.send(reply)
+			continue
+		}
+		data, _ := json.Marshal(message["result"])
+		return string(data), sampled
+	}
+}
+
+func TestReflectUsesSampling(t *testing.T) {
+	if report := ValidateReflection("mirror", sampledMirror); !report.Valid {
+		t.Fatalf("test reflection breaks the contract: %+v", report.Violations)
+	}
+	c := startSamplingClient(t, newTestHandler(t, WithSampling(true)), true)
+
+	text, sampled := c.reflect(sampledMirror)
+	if !sampled || !strings.Contains(text, "both early and careful") {
+		t.Fatalf("reflection was not sampled: %s", text)
+	}
+}
+
+func TestReflectFallsBackWithoutSampling(t *testing.T) {
+	c := startSamplingClient(t, newTestHandler(t, WithSampling(true)), false)
+
+	text, sampled := c.reflect(sampledMirror)
+	if sampled || !strings.Contains(text, "MIRROR MODE") {
+		t.Fatalf("expected the mirror template without sampling: %s", text)
+	}
+}
+
+func TestReflectFallsBackWhenSamplingFails(t *testing.T) {
+	c := startSamplingClient(t, newTestHandler(t, WithSampling(true)), true)
+
+	text, sampled := c.reflect("")
+	if !sampled || !strings.Contains(text, "MIRROR MODE") {
+		t.Fatalf("expected the mirror template after a sampling error: %s", text)
+	}
+
+	text, sampled = c.reflect("Just ship it.")
+	if !sampled || !strings.Contains(text, "MIRROR MODE") {
+		t.Fatalf("expected the mirror template after a reflection that breaks the contract: %s", text)
+	}
+}
@@ -0,0 +1,89 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// seedVersionsDocument is the store document holding every seed version
+// the server has shipped with
+const seedVersionsDocument = "seed_versions"
+
+// loadSeedVersions restores the seed versions seen by earlier runs, so
+// content that has since changed stays readable, then records the current
+// ones
+func (h *Handler) loadSeedVersions() {
+	var versions []wisdom.Seed
+	if _, err := h.store.Load(seedVersionsDocument, &versions); err != nil {
+		log.Printf("Could not load seed versions: %v", err)
+	}
+	h.wisdomBase.AddSeedVersions(versions)
+
+	if h.store.ReadOnly() {
+		return
+	}
+	if _, err := h.store.Save(seedVersionsDocument, h.wisdomBase.SeedHistory()); err != nil {
+		log.Printf("Could not save seed versions: %v", err)
+	}
+}
+
+func (h *Handler) handleDiffSeed(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Name string `json:"name"`
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	versions, err := h.wisdomBase.SeedVersions(args.Name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Seed not found: %v", err)), nil
+	}
+
+	to, err := h.wisdomBase.GetSeedVersion(args.Name, args.To)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var from *wisdom.Seed
+	if args.From != "" {
+		if from, err = h.wisdomBase.GetSeedVersion(args.Name, args.From); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	} else {
+		for i := range versions {
+			if versions[i].Version == to.Version && i > 0 {
+				from = &versions[i-1]
+			}
+		}
+		if from == nil {
+			return mcp.NewToolResultText(fmt.Sprintf("%s has no version before %s, so there is nothing to compare it with. Known versions: %s", args.Name, to.Version, versionList(versions))), nil
+		}
+	}
+
+	diff := wisdom.UnifiedDiff(
+		fmt.Sprintf("%s@%s\t%s", args.Name, from.Version, from.Hash),
+		fmt.Sprintf("%s@%s\t%s", args.Name, to.Version, to.Hash),
+		from.Content, to.Content)
+	if diff == "" {
+		return mcp.NewToolResultText(fmt.Sprintf("%s %s and %s have the same content.", args.Name, from.Version, to.Version)), nil
+	}
+	return mcp.NewToolResultText(diff), nil
+}
+
+// versionList names the versions of a seed, oldest first
+func versionList(versions []wisdom.Seed) string {
+	names := make([]string, 0, len(versions))
+	for _, seed := range versions {
+		names = append(names, seed.Version)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,313 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/wisdom"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// snapshotsDocument is the store document holding memory snapshots
+const snapshotsDocument = "snapshots"
+
+// maxProjectSnapshots is how many snapshots are kept for each project; the
+// oldest are dropped past it
+const maxProjectSnapshots = 20
+
+// memorySnapshot is a copy of a project's memory garden and journal at one
+// moment. Snapshots are never changed once taken; the hash of their
+// contents is checked before one is restored.
+type memorySnapshot struct {
+	ID        string    `json:"id"`
+	Project   string    `json:"project"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+	Hash      string    `json:"hash"`
+	Memories  []memory  `json:"memories"`
+	Journal   *project  `json:"journal,omitempty"`
+}
+
+// snapshotSummary describes a snapshot without its contents
+type snapshotSummary struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+	Hash      string    `json:"hash"`
+	Memories  int       `json:"memories"`
+	Rooms     int       `json:"rooms"`
+}
+
+func (s memorySnapshot) summary() snapshotSummary {
+	summary := snapshotSummary{ID: s.ID, Label: s.Label, CreatedAt: s.CreatedAt, Hash: s.Hash, Memories: len(s.Memories)}
+	if s.Journal != nil {
+		summary.Rooms = len(s.Journal.Rooms)
+	}
+	return summary
+}
+
+// contentHash digests what a snapshot holds
+func (s memorySnapshot) contentHash() string {
+	data, _ := json.Marshal(struct {
+		Memories []memory `json:"memories"`
+		Journal  *project `json:"journal"`
+	}{s.Memories, s.Journal})
+	return wisdom.Digest(string(data))
+}
+
+// snapshots holds every memory snapshot in the order taken
+type snapshots struct {
+	mu   sync.Mutex
+	list []memorySnapshot
+	now  func() time.Time
+}
+
+func newSnapshots() *snapshots {
+	return &snapshots{now: time.Now}
+}
+
+// add keeps a snapshot, dropping the oldest snapshot of its project past
+// maxProjectSnapshots. Pinned snapshots, those holding the originals of a
+// digest still in memory, are never dropped and do not count toward the
+// limit. A snapshot already kept is left as it is.
+func (ss *snapshots) add(s memorySnapshot, pinned map[string]bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	taken := 0
+	for _, existing := range ss.list {
+		if existing.Project == s.Project {
+			if existing.ID == s.ID {
+				return
+			}
+			if !pinned[existing.ID] {
+				taken++
+			}
+		}
+	}
+	ss.list = append(ss.list, s)
+
+	drop := taken + 1 - maxProjectSnapshots
+	kept := ss.list[:0]
+	for _, existing := range ss.list {
+		if drop > 0 && existing.Project == s.Project && !pinned[existing.ID] {
+			drop--
+			continue
+		}
+		kept = append(kept, existing)
+	}
+	ss.list = kept
+}
+
+// replace swaps in every snapshot, such as those reread from the store
+func (ss *snapshots) replace(list []memorySnapshot) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	ss.list = list
+}
+
+// get returns a snapshot of a project
+func (ss *snapshots) get(projectID, id string) (memorySnapshot, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	for _, s := range ss.list {
+		if s.Project == projectID && s.ID == id {
+			return s, true
+		}
+	}
+	return memorySnapshot{}, false
+}
+
+// of returns the snapshots of a project, oldest first
+func (ss *snapshots) of(projectID string) []memorySnapshot {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	list := []memorySnapshot{}
+	for _, s := range ss.list {
+		if s.Project == projectID {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// all copies every snapshot so they can be saved without holding the lock
+func (ss *snapshots) all() []memorySnapshot {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	return append([]memorySnapshot{}, ss.list...)
+}
+
+// loadSnapshots restores memory snapshots from the store
+func (h *Handler) loadSnapshots() {
+	var list []memorySnapshot
+	if _, err := h.store.Load(snapshotsDocument, &list); err != nil {
+		log.Printf("Could not load snapshots: %v", err)
+		return
+	}
+	h.snapshots.mu.Lock()
+	defer h.snapshots.mu.Unlock()
+	h.snapshots.list = list
+}
+
+// updateSnapshots rereads the snapshots, applies change to them and saves
+// them, holding the store's lock throughout so snapshots taken by other
+// processes sharing the data directory are kept
+func (h *Handler) updateSnapshots(ctx context.Context, change func() error) error {
+	var list []memorySnapshot
+	write, err := h.store.Update(snapshotsDocument, &list, func() error {
+		h.snapshots.replace(list)
+		if err := change(); err != nil {
+			return err
+		}
+		list = h.snapshots.all()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+// takeSnapshot copies a project's memories and journal into a new snapshot
+func (h *Handler) takeSnapshot(ctx context.Context, name, label string) (memorySnapshot, error) {
+	if err := h.refreshMemory(); err != nil {
+		return memorySnapshot{}, fmt.Errorf("failed to load memory: %w", err)
+	}
+	id := projectID(strings.TrimSpace(name))
+	s := memorySnapshot{
+		ID:        newID("snap"),
+		Project:   id,
+		Label:     label,
+		CreatedAt: h.snapshots.now().UTC(),
+		Memories:  h.memory.memoriesOf(id),
+	}
+	if p, ok := h.projects.get(name); ok {
+		s.Journal = &p
+	}
+	s.Hash = s.contentHash()
+
+	if err := h.updateSnapshots(ctx, func() error {
+		h.snapshots.add(s, h.memory.digestSnapshots())
+		return nil
+	}); err != nil {
+		return memorySnapshot{}, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return s, nil
+}
+
+// restoreSnapshot rolls a project's memories and journal back to a
+// snapshot, first taking a snapshot of the current state so the restore
+// can itself be undone
+func (h *Handler) restoreSnapshot(ctx context.Context, s memorySnapshot) (memorySnapshot, error) {
+	if s.contentHash() != s.Hash {
+		return memorySnapshot{}, fmt.Errorf("snapshot %s does not match its hash %s; it will not be restored", s.ID, s.Hash)
+	}
+	name := s.Project
+	if p, ok := h.projects.get(s.Project); ok {
+		name = p.Name
+	} else if s.Journal != nil {
+		name = s.Journal.Name
+	}
+	before, err := h.takeSnapshot(ctx, name, "before restoring "+s.ID)
+	if err != nil {
+		return memorySnapshot{}, err
+	}
+
+	if err := h.updateMemory(ctx, func() error {
+		h.memory.setMemories(s.Project, s.Memories)
+		return nil
+	}); err != nil {
+		return before, err
+	}
+	if err := h.updateProjects(ctx, func() error {
+		if s.Journal != nil {
+			journal := s.Journal.clone()
+			journal.ID, journal.Name = s.Project, name
+			h.projects.replace(journal)
+		} else {
+			h.projects.remove(s.Project)
+		}
+		return nil
+	}); err != nil {
+		return before, err
+	}
+	return before, nil
+}
+
+func (h *Handler) handleSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project string `json:"project"`
+		Label   string `json:"label"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	if strings.TrimSpace(args.Project) == "" {
+		return mcp.NewToolResultError("Invalid arguments: project is required"), nil
+	}
+
+	s, err := h.takeSnapshot(ctx, args.Project, strings.TrimSpace(args.Label))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	summaries := []snapshotSummary{}
+	for _, taken := range h.snapshots.of(s.Project) {
+		summaries = append(summaries, taken.summary())
+	}
+	result := map[string]interface{}{
+		"project":   s.Project,
+		"snapshot":  s.summary(),
+		"snapshots": summaries,
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (h *Handler) handleRestoreSnapshot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Project  string `json:"project"`
+		Snapshot string `json:"snapshot"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+	id := projectID(strings.TrimSpace(args.Project))
+	s, ok := h.snapshots.get(id, args.Snapshot)
+	if !ok {
+		ids := []string{}
+		for _, taken := range h.snapshots.of(id) {
+			ids = append(ids, taken.ID)
+		}
+		if len(ids) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("Project %s has no snapshots. Take one with dojo.snapshot.", id)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("Project %s has no snapshot %q. Its snapshots are: %s", id, args.Snapshot, strings.Join(ids, ", "))), nil
+	}
+
+	before, err := h.restoreSnapshot(ctx, s)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Could not restore the snapshot: %v", err)), nil
+	}
+
+	result := map[string]interface{}{
+		"project":  id,
+		"restored": s.summary(),
+		"undo":     fmt.Sprintf("The state before restoring was kept as snapshot %s", before.ID),
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
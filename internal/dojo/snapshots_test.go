@@ -0,0 +1,89 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+)
+
+func TestSnapshotsAreKeptPerProjectUpToTheLimit(t *testing.T) {
+	dir := t.TempDir()
+	st, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithStore(st))
+	first, err := h.takeSnapshot(context.Background(), "Hermes", "other project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last memorySnapshot
+	for i := 0; i < maxProjectSnapshots+5; i++ {
+		if last, err = h.takeSnapshot(context.Background(), "Atlas", fmt.Sprintf("snapshot %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := store.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []memorySnapshot
+	if _, err := reader.Load(snapshotsDocument, &list); err != nil {
+		t.Fatal(err)
+	}
+	atlas := 0
+	for _, s := range list {
+		if s.Project == "proj_atlas" {
+			atlas++
+		}
+	}
+	if atlas != maxProjectSnapshots || len(list) != maxProjectSnapshots+1 {
+		t.Fatalf("stored %d snapshots of Atlas and %d in all", atlas, len(list))
+	}
+	if _, ok := h.snapshots.get(first.Project, first.ID); !ok {
+		t.Fatal("another project's snapshot was dropped")
+	}
+	if _, ok := h.snapshots.get(last.Project, last.ID); !ok {
+		t.Fatal("the newest snapshot was dropped")
+	}
+}
+
+func TestSnapshotsBehindDigestsAreKept(t *testing.T) {
+	h := newTestHandler(t)
+	if text, isError := callTool(t, h.guard(h.handleRemember), "dojo.remember", map[string]interface{}{"project": "Atlas", "content": "Caching the lineage graph halves startup time."}); isError {
+		t.Fatalf("remember failed: %s", text)
+	}
+	later := time.Now().Add(30 * 24 * time.Hour)
+	h.memory.now = func() time.Time { return later }
+	if text, isError := callTool(t, h.guard(h.handleCompressMemory), "dojo.compress_memory", map[string]interface{}{"project": "Atlas"}); isError {
+		t.Fatalf("compress failed: %s", text)
+	}
+	digests := h.digestsOf("proj_atlas")
+	if len(digests) != 1 {
+		t.Fatalf("got %d digests", len(digests))
+	}
+	pinned := digests[0].Digest.Snapshot
+
+	for i := 0; i < maxProjectSnapshots+5; i++ {
+		if _, err := h.takeSnapshot(context.Background(), "Atlas", fmt.Sprintf("snapshot %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := h.snapshots.get("proj_atlas", pinned); !ok {
+		t.Fatal("the snapshot holding the digest's originals was dropped")
+	}
+	if n := len(h.snapshots.of("proj_atlas")); n != maxProjectSnapshots+1 {
+		t.Fatalf("kept %d snapshots of Atlas", n)
+	}
+	text, isError := callTool(t, h.guard(h.handleRestoreSnapshot), "dojo.restore_snapshot", map[string]interface{}{"project": "Atlas", "snapshot": pinned})
+	if isError {
+		t.Fatalf("restore failed: %s", text)
+	}
+	if memories := h.memory.memoriesOf("proj_atlas"); len(memories) != 1 || memories[0].Digest != nil {
+		t.Fatalf("restored %+v", memories)
+	}
+}
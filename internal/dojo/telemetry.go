@@ -0,0 +1,282 @@
+package dojo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/transport"
+)
+
+// defaultSessionID is used when a call does not arrive over a tracked session
+const defaultSessionID = "default"
+
+// sessionID returns the identifier of the client session making a call
+func sessionID(ctx context.Context) string {
+	if session := transport.SessionFromContext(ctx); session != nil {
+		return session.ID()
+	}
+	return defaultSessionID
+}
+
+// maxTelemetryCalls caps how many calls a session keeps; pace is read from
+// the most recent ones
+const maxTelemetryCalls = 1000
+
+// argumentWords counts the words in the text arguments of a call
+func argumentWords(arguments map[string]interface{}) int {
+	words := 0
+	for _, text := range collectStrings(arguments) {
+		words += len(strings.Fields(text))
+	}
+	return words
+}
+
+// toolCall is one recorded tool invocation
+type toolCall struct {
+	Tool  string
+	At    time.Time
+	Words int
+}
+
+// room tracks a thinking room topic and how often it was returned to
+type room struct {
+	Topic     string
+	CreatedAt time.Time
+	Revisits  int
+}
+
+// sessionTelemetry is the call history of a single client session
+type sessionTelemetry struct {
+	calls []toolCall
+	rooms []*room
+	// newTopicsBeforeRevisit counts rooms opened while an earlier room had
+	// not yet been revisited
+	newTopicsBeforeRevisit int
+}
+
+// telemetry tracks per-session call cadence for pace assessment
+type telemetry struct {
+	mu       sync.Mutex
+	sessions map[string]*sessionTelemetry
+	now      func() time.Time
+}
+
+func newTelemetry() *telemetry {
+	return &telemetry{
+		sessions: map[string]*sessionTelemetry{},
+		now:      time.Now,
+	}
+}
+
+// record notes a tool call and its text inputs, dropping the session's
+// oldest call past the cap
+func (t *telemetry) record(session, tool string, arguments map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.session(session)
+	now := t.now()
+	texts := collectStrings(arguments)
+
+	st.calls = append(st.calls, toolCall{Tool: tool, At: now, Words: argumentWords(arguments)})
+	if len(st.calls) > maxTelemetryCalls {
+		st.calls = append([]toolCall{}, st.calls[len(st.calls)-maxTelemetryCalls:]...)
+	}
+
+	if tool == "dojo.create_thinking_room" {
+		topic, _ := arguments["topic"].(string)
+		if existing := st.findRoom(topic); existing != nil {
+			existing.Revisits++
+			return
+		}
+		for _, earlier := range st.rooms {
+			if earlier.Revisits == 0 {
+				st.newTopicsBeforeRevisit++
+				break
+			}
+		}
+		st.rooms = append(st.rooms, &room{Topic: topic, CreatedAt: now})
+		return
+	}
+
+	// Any other call that mentions a room's topic counts as returning to it
+	for _, r := range st.rooms {
+		for _, text := range texts {
+			if r.Topic != "" && strings.Contains(strings.ToLower(text), strings.ToLower(r.Topic)) {
+				r.Revisits++
+				break
+			}
+		}
+	}
+}
+
+func (t *telemetry) session(id string) *sessionTelemetry {
+	st, ok := t.sessions[id]
+	if !ok {
+		st = &sessionTelemetry{}
+		t.sessions[id] = st
+	}
+	return st
+}
+
+func (st *sessionTelemetry) findRoom(topic string) *room {
+	for _, r := range st.rooms {
+		if strings.EqualFold(strings.TrimSpace(r.Topic), strings.TrimSpace(topic)) {
+			return r
+		}
+	}
+	return nil
+}
+
+// paceSignal is one observation derived from session telemetry
+type paceSignal struct {
+	Name        string
+	Value       string
+	Reading     string // "understanding", "extraction" or "neutral"
+	Explanation string
+}
+
+// paceAssessment is the telemetry-based understanding-vs-extraction reading
+type paceAssessment struct {
+	Calls   int
+	Reading string
+	Signals []paceSignal
+}
+
+// minCallsForAssessment is the fewest calls needed for a telemetry reading
+const minCallsForAssessment = 3
+
+// assess computes an understanding-vs-extraction reading for a session
+func (t *telemetry) assess(session string) paceAssessment {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.session(session)
+	assessment := paceAssessment{Calls: len(st.calls), Reading: "not enough telemetry yet"}
+	if len(st.calls) < minCallsForAssessment {
+		return assessment
+	}
+
+	first, last := st.calls[0].At, st.calls[len(st.calls)-1].At
+	minutes := last.Sub(first).Minutes()
+	if minutes < 1 {
+		minutes = 1
+	}
+	callsPerMinute := float64(len(st.calls)) / minutes
+	assessment.Signals = append(assessment.Signals, paceSignal{
+		Name:        "Call cadence",
+		Value:       fmt.Sprintf("%.1f calls/minute over %d calls", callsPerMinute, len(st.calls)),
+		Reading:     classify(callsPerMinute, 4, 1.5, true),
+		Explanation: "A rapid stream of calls suggests consuming rather than integrating.",
+	})
+
+	gaps := make([]float64, 0, len(st.calls)-1)
+	for i := 1; i < len(st.calls); i++ {
+		gaps = append(gaps, st.calls[i].At.Sub(st.calls[i-1].At).Seconds())
+	}
+	sort.Float64s(gaps)
+	medianGap := gaps[len(gaps)/2]
+	assessment.Signals = append(assessment.Signals, paceSignal{
+		Name:        "Time between calls",
+		Value:       fmt.Sprintf("median %s", time.Duration(medianGap*float64(time.Second)).Round(time.Second)),
+		Reading:     classify(medianGap, 20, 90, false),
+		Explanation: "Pauses between calls leave room for reflection and integration.",
+	})
+
+	if len(st.rooms) > 0 {
+		revisited := 0
+		for _, r := range st.rooms {
+			if r.Revisits > 0 {
+				revisited++
+			}
+		}
+		reading := "neutral"
+		switch {
+		case st.newTopicsBeforeRevisit >= 2:
+			reading = "extraction"
+		case revisited > 0 && st.newTopicsBeforeRevisit == 0:
+			reading = "understanding"
+		}
+		assessment.Signals = append(assessment.Signals, paceSignal{
+			Name:        "Returning to rooms",
+			Value:       fmt.Sprintf("%d rooms opened, %d revisited, %d new topics started before an earlier room was revisited", len(st.rooms), revisited, st.newTopicsBeforeRevisit),
+			Reading:     reading,
+			Explanation: "Moving to the next topic before returning to the last is a sign of extraction.",
+		})
+	}
+
+	totalWords := 0
+	for _, call := range st.calls {
+		totalWords += call.Words
+	}
+	averageWords := float64(totalWords) / float64(len(st.calls))
+	assessment.Signals = append(assessment.Signals, paceSignal{
+		Name:        "Reflection length",
+		Value:       fmt.Sprintf("%.0f words per call on average", averageWords),
+		Reading:     classify(averageWords, 15, 40, false),
+		Explanation: "Very short inputs can mean skimming; fuller reflections suggest depth.",
+	})
+
+	understanding, extraction := 0, 0
+	for _, signal := range assessment.Signals {
+		switch signal.Reading {
+		case "understanding":
+			understanding++
+		case "extraction":
+			extraction++
+		}
+	}
+	switch {
+	case extraction > understanding:
+		assessment.Reading = "pace of extraction"
+	case understanding > extraction:
+		assessment.Reading = "pace of understanding"
+	default:
+		assessment.Reading = "mixed"
+	}
+
+	return assessment
+}
+
+// classify maps a measurement onto a pace reading. With highIsExtraction,
+// values at or above extractAt read as extraction and values at or below
+// understandAt as understanding; otherwise the comparison is inverted.
+func classify(value, extractAt, understandAt float64, highIsExtraction bool) string {
+	if highIsExtraction {
+		switch {
+		case value >= extractAt:
+			return "extraction"
+		case value <= understandAt:
+			return "understanding"
+		}
+		return "neutral"
+	}
+	switch {
+	case value <= extractAt:
+		return "extraction"
+	case value >= understandAt:
+		return "understanding"
+	}
+	return "neutral"
+}
+
+// render formats the assessment as a Markdown section
+func (a paceAssessment) render() string {
+	var text strings.Builder
+	text.WriteString("## What Your Session Telemetry Shows\n\n")
+	if len(a.Signals) == 0 {
+		text.WriteString(fmt.Sprintf("Only %d tool call(s) recorded in this session so far. Check back after a few more and the server will read your pace from how you are actually working.\n", a.Calls))
+		return text.String()
+	}
+
+	text.WriteString(fmt.Sprintf("**Reading:** %s\n\n", a.Reading))
+	for _, signal := range a.Signals {
+		text.WriteString(fmt.Sprintf("- **%s:** %s (%s). %s\n", signal.Name, signal.Value, signal.Reading, signal.Explanation))
+	}
+	text.WriteString("\nTelemetry only sees the outside of your work. Use the questions below to check it against how the session actually feels.\n")
+	return text.String()
+}
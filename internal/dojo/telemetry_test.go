@@ -0,0 +1,98 @@
+package dojo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTelemetryKeepsRecentCalls(t *testing.T) {
+	tel := newTelemetry()
+	for i := 0; i < maxTelemetryCalls+50; i++ {
+		tel.record("s", "dojo.recall", map[string]interface{}{"query": "caching"})
+	}
+	if n := len(tel.session("s").calls); n != maxTelemetryCalls {
+		t.Fatalf("kept %d calls, want %d", n, maxTelemetryCalls)
+	}
+}
+
+// telemetryCall is one call in a scripted session
+type telemetryCall struct {
+	tool string
+	args map[string]interface{}
+}
+
+func words(n int) string {
+	return strings.TrimSpace(strings.Repeat("word ", n))
+}
+
+func TestTelemetryAssess(t *testing.T) {
+	recall := func(n int) telemetryCall {
+		return telemetryCall{"dojo.recall", map[string]interface{}{"query": words(n)}}
+	}
+	room := func(topic string) telemetryCall {
+		return telemetryCall{"dojo.create_thinking_room", map[string]interface{}{"topic": topic}}
+	}
+	for _, tc := range []struct {
+		name    string
+		gap     time.Duration
+		calls   []telemetryCall
+		reading string
+		signals map[string]string
+	}{
+		{
+			name:    "too few calls",
+			gap:     time.Minute,
+			calls:   []telemetryCall{recall(50), recall(50)},
+			reading: "not enough telemetry yet",
+		},
+		{
+			name:    "rushing from topic to topic",
+			gap:     5 * time.Second,
+			calls:   []telemetryCall{room("caching"), room("releases"), room("budgets"), recall(1), recall(1), recall(1), recall(1), recall(1), recall(1), recall(1)},
+			reading: "pace of extraction",
+			signals: map[string]string{"Call cadence": "extraction", "Time between calls": "extraction", "Returning to rooms": "extraction", "Reflection length": "extraction"},
+		},
+		{
+			name:    "slow and returning",
+			gap:     3 * time.Minute,
+			calls:   []telemetryCall{room("caching"), recall(60), {"dojo.reflect", map[string]interface{}{"situation": "Back to caching. " + words(60)}}, recall(60)},
+			reading: "pace of understanding",
+			signals: map[string]string{"Call cadence": "understanding", "Time between calls": "understanding", "Returning to rooms": "understanding", "Reflection length": "understanding"},
+		},
+		{
+			name:    "in between",
+			gap:     30 * time.Second,
+			calls:   []telemetryCall{recall(30), recall(30), recall(30)},
+			reading: "mixed",
+			signals: map[string]string{"Call cadence": "neutral", "Time between calls": "neutral", "Reflection length": "neutral"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+			tel := newTelemetry()
+			tel.now = func() time.Time { return now }
+			for _, call := range tc.calls {
+				tel.record("s", call.tool, call.args)
+				now = now.Add(tc.gap)
+			}
+
+			assessment := tel.assess("s")
+			if assessment.Reading != tc.reading || assessment.Calls != len(tc.calls) {
+				t.Fatalf("read %q over %d calls, want %q", assessment.Reading, assessment.Calls, tc.reading)
+			}
+			got := map[string]string{}
+			for _, signal := range assessment.Signals {
+				got[signal.Name] = signal.Reading
+			}
+			if len(got) != len(tc.signals) {
+				t.Fatalf("signals %v, want %v", got, tc.signals)
+			}
+			for name, reading := range tc.signals {
+				if got[name] != reading {
+					t.Errorf("%s read %q, want %q", name, got[name], reading)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package dojo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sectionContract describes one counted section of a mode's output, as
+// specified in the four_modes resource
+type sectionContract struct {
+	Name     string   // label used in violations, e.g. "pattern lines"
+	Heading  string   // heading the templates and sampled output use
+	Keywords []string // lowercase heading fragments that identify the section
+	Min      int
+	Max      int
+	Fallback string // what to count when the section has no list: "lines" or "paragraphs"
+}
+
+// modeContracts holds the hard output constraints of the four modes
+var modeContracts = map[string][]sectionContract{
+	"mirror": {
+		{Name: "pattern lines", Heading: "Pattern across perspectives", Keywords: []string{"pattern"}, Min: 3, Max: 6, Fallback: "lines"},
+		{Name: "tensions", Heading: "Assumptions/tensions identified", Keywords: []string{"assumption", "tension"}, Min: 1, Max: 3, Fallback: "paragraphs"},
+		{Name: "reframes", Heading: "Reframe", Keywords: []string{"reframe"}, Min: 1, Max: 2, Fallback: "paragraphs"},
+	},
+	"scout": {
+		{Name: "routes", Heading: "Possible routes", Keywords: []string{"route"}, Min: 2, Max: 4, Fallback: "paragraphs"},
+		{Name: "smallest test", Heading: "Recommended smallest test", Keywords: []string{"smallest test"}, Min: 1, Max: 1, Fallback: "paragraphs"},
+	},
+	"gardener": {
+		{Name: "strong ideas", Heading: "Strongest ideas", Keywords: []string{"strongest", "strong idea"}, Min: 2, Max: 3, Fallback: "paragraphs"},
+		{Name: "ideas to grow", Heading: "Ideas that need growth", Keywords: []string{"growth", "to grow"}, Min: 1, Max: 2, Fallback: "paragraphs"},
+	},
+	"implementation": {
+		{Name: "steps", Heading: "Next steps", Keywords: []string{"step"}, Min: 1, Max: 5, Fallback: "paragraphs"},
+	},
+}
+
+// originationPhrases signal that a reflection is originating perspectives or
+// acting as an oracle rather than reflecting the user's own thinking
+var originationPhrases = []string{
+	"you should",
+	"you must",
+	"the right answer",
+	"the correct answer",
+	"the right choice",
+	"the best option is",
+	"the best choice is",
+	"i recommend you",
+	"my recommendation is",
+	"in my opinion",
+	"i believe you",
+	"another perspective",
+	"a new perspective",
+	"an additional perspective",
+}
+
+// Violation describes one way a reflection breaks its mode's contract
+type Violation struct {
+	Rule    string `json:"rule"` // "unknown_mode", "missing_section", "count", "originates_perspective"
+	Section string `json:"section,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of validating a reflection
+type ValidationReport struct {
+	Mode       string         `json:"mode"`
+	Valid      bool           `json:"valid"`
+	Counts     map[string]int `json:"counts"`
+	Violations []Violation    `json:"violations"`
+}
+
+var (
+	boldHeadingPattern = regexp.MustCompile(`^\*\*([^*]+)\*\*:?$`)
+	// markdownHeadingPattern matches ATX headings: one to six #, then a
+	// space, so "#1 priority" or "#caching" stays text
+	markdownHeadingPattern = regexp.MustCompile(`^#{1,6}(?:\s+(.*?))?(?:\s+#+)?$`)
+	listItemPattern        = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+`)
+)
+
+// ValidateReflection checks a reflection, whether produced by a template or
+// by a model, against the output contract of its mode and the boundary that
+// Dojo refuses to originate perspectives
+func ValidateReflection(mode, reflection string) ValidationReport {
+	report := ValidationReport{
+		Mode:       mode,
+		Counts:     map[string]int{},
+		Violations: []Violation{},
+	}
+
+	contracts, ok := modeContracts[mode]
+	if !ok {
+		report.Violations = append(report.Violations, Violation{
+			Rule:    "unknown_mode",
+			Message: fmt.Sprintf("Unknown mode %q. Please use: mirror, scout, gardener, or implementation.", mode),
+		})
+		return report
+	}
+
+	sections := splitSections(reflection)
+	for _, contract := range contracts {
+		body, found := findSection(sections, contracts, contract)
+		if !found {
+			report.Violations = append(report.Violations, Violation{
+				Rule:    "missing_section",
+				Section: contract.Name,
+				Message: fmt.Sprintf("No %q section found", contract.Heading),
+			})
+			continue
+		}
+
+		count := countItems(body, contract.Fallback)
+		report.Counts[contract.Name] = count
+		if count < contract.Min || count > contract.Max {
+			report.Violations = append(report.Violations, Violation{
+				Rule:    "count",
+				Section: contract.Name,
+				Message: fmt.Sprintf("Expected %s, found %d", countRange(contract), count),
+			})
+		}
+	}
+
+	lower := strings.ToLower(reflection)
+	for _, phrase := range originationPhrases {
+		if strings.Contains(lower, phrase) {
+			report.Violations = append(report.Violations, Violation{
+				Rule:    "originates_perspective",
+				Message: fmt.Sprintf("Contains %q; Dojo reflects the user's perspectives rather than originating its own", phrase),
+			})
+		}
+	}
+
+	report.Valid = len(report.Violations) == 0
+	return report
+}
+
+// outputSection is a heading and the lines beneath it
+type outputSection struct {
+	Heading string
+	Lines   []string
+}
+
+// splitSections splits a reflection on Markdown headings and whole-line
+// bold headings such as "**Reframe:**"
+func splitSections(text string) []outputSection {
+	sections := []outputSection{{}}
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if ok, title := parseOutputHeading(trimmed); ok {
+			sections = append(sections, outputSection{Heading: strings.ToLower(title)})
+			continue
+		}
+		current := &sections[len(sections)-1]
+		current.Lines = append(current.Lines, line)
+	}
+	return sections
+}
+
+func parseOutputHeading(line string) (bool, string) {
+	if match := boldHeadingPattern.FindStringSubmatch(line); match != nil {
+		return true, strings.TrimSuffix(strings.TrimSpace(match[1]), ":")
+	}
+	if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+		title := strings.TrimSpace(match[1])
+		return true, strings.Trim(strings.TrimSuffix(title, ":"), "* ")
+	}
+	return false, ""
+}
+
+// findSection returns the body of the first section whose heading belongs to
+// the contract. A heading is assigned to the earliest contract it matches so
+// that "Strongest ideas (ready to grow)" is not mistaken for ideas to grow.
+func findSection(sections []outputSection, contracts []sectionContract, contract sectionContract) ([]string, bool) {
+	for _, section := range sections {
+		if section.Heading == "" {
+			continue
+		}
+		for _, candidate := range contracts {
+			if headingMatches(section.Heading, candidate) {
+				if candidate.Name == contract.Name {
+					return section.Lines, true
+				}
+				break
+			}
+		}
+	}
+	return nil, false
+}
+
+func headingMatches(heading string, contract sectionContract) bool {
+	for _, keyword := range contract.Keywords {
+		if strings.Contains(heading, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// countItems counts top-level list items, or lines/paragraphs when the
+// section is written as prose
+func countItems(lines []string, fallback string) int {
+	items := 0
+	for _, line := range lines {
+		if line == strings.TrimLeft(line, " \t") && listItemPattern.MatchString(line) {
+			items++
+		}
+	}
+	if items > 0 {
+		return items
+	}
+
+	count, inParagraph := 0, false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			inParagraph = false
+			continue
+		}
+		if fallback == "lines" || !inParagraph {
+			count++
+		}
+		inParagraph = true
+	}
+	return count
+}
+
+func countRange(contract sectionContract) string {
+	if contract.Min == contract.Max {
+		return fmt.Sprintf("exactly %d %s", contract.Min, contract.Name)
+	}
+	return fmt.Sprintf("%d-%d %s", contract.Min, contract.Max, contract.Name)
+}
+
+// contractInstructions describes the required output structure of a mode
+// for inclusion in sampling prompts
+func contractInstructions(mode string) string {
+	var text strings.Builder
+	text.WriteString("Structure the reflection with exactly these bold headings, each followed by a numbered list:\n")
+	for _, contract := range modeContracts[mode] {
+		text.WriteString(fmt.Sprintf("- **%s:** %s\n", contract.Heading, countRange(contract)))
+	}
+	return text.String()
+}
+
+func (h *Handler) handleValidateReflection(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args struct {
+		Mode       string `json:"mode"`
+		Reflection string `json:"reflection"`
+	}
+
+	if err := unmarshalArgs(request.Params.Arguments, &args); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("Invalid arguments: %v", err)), nil
+	}
+
+	report := ValidateReflection(args.Mode, args.Reflection)
+
+	reportJSON, _ := json.MarshalIndent(report, "", "  ")
+	return mcp.NewToolResultText(string(reportJSON)), nil
+}
@@ -0,0 +1,183 @@
+package dojo
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// reflectionWith writes a reflection with the contract headings of a mode,
+// each followed by a numbered list of the given length
+func reflectionWith(mode string, counts map[string]int) string {
+	var text strings.Builder
+	for _, contract := range modeContracts[mode] {
+		n, ok := counts[contract.Name]
+		if !ok {
+			continue
+		}
+		text.WriteString(fmt.Sprintf("**%s:**\n", contract.Heading))
+		for i := 1; i <= n; i++ {
+			text.WriteString(fmt.Sprintf("%d. Item %d\n", i, i))
+		}
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
+// rules lists the rules of a report's violations, with their sections
+func rules(report ValidationReport) []string {
+	rules := []string{}
+	for _, violation := range report.Violations {
+		rule := violation.Rule
+		if violation.Section != "" {
+			rule += " " + violation.Section
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func TestValidateReflectionCountsSectionsPerMode(t *testing.T) {
+	for mode, contracts := range modeContracts {
+		fewest, most, over := map[string]int{}, map[string]int{}, map[string]int{}
+		for _, contract := range contracts {
+			fewest[contract.Name] = contract.Min
+			most[contract.Name] = contract.Max
+			over[contract.Name] = contract.Max + 1
+		}
+		for _, counts := range []map[string]int{fewest, most} {
+			if report := ValidateReflection(mode, reflectionWith(mode, counts)); !report.Valid {
+				t.Errorf("%s with %v: %v", mode, counts, report.Violations)
+			}
+		}
+
+		report := ValidateReflection(mode, reflectionWith(mode, over))
+		if len(report.Violations) != len(contracts) {
+			t.Errorf("%s over every maximum: %v", mode, rules(report))
+		}
+		for _, contract := range contracts {
+			if report.Counts[contract.Name] != contract.Max+1 {
+				t.Errorf("%s counted %d %s, want %d", mode, report.Counts[contract.Name], contract.Name, contract.Max+1)
+			}
+		}
+
+		missing := contracts[len(contracts)-1]
+		delete(fewest, missing.Name)
+		report = ValidateReflection(mode, reflectionWith(mode, fewest))
+		if got := strings.Join(rules(report), ", "); got != "missing_section "+missing.Name {
+			t.Errorf("%s without %s: %s", mode, missing.Name, got)
+		}
+	}
+}
+
+func TestValidateReflection(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		mode       string
+		reflection string
+		counts     map[string]int
+		rules      []string
+	}{
+		{
+			name: "prose counted as lines or paragraphs",
+			mode: "mirror",
+			reflection: `## Pattern across perspectives
+Both of you want the launch to land.
+Both of you fear rework.
+Both of you name the deadline.
+
+## Assumptions/tensions identified
+That speed and quality trade off.
+
+That the deadline cannot move.
+
+## Reframe
+What would a launch you are both proud of need?`,
+			counts: map[string]int{"pattern lines": 3, "tensions": 2, "reframes": 1},
+		},
+		{
+			name: "nested items are not counted",
+			mode: "implementation",
+			reflection: `### Next steps
+1. Write the benchmark
+   - cold cache
+   - warm cache
+2. Profile the lineage walk`,
+			counts: map[string]int{"steps": 2},
+		},
+		{
+			name: "a heading belongs to the earliest section it matches",
+			mode: "gardener",
+			reflection: `**Strongest ideas (ready to grow):**
+- Cache the graph
+- Precompute lineage
+
+**Ideas that need growth:**
+- Shard the store`,
+			counts: map[string]int{"strong ideas": 2, "ideas to grow": 1},
+		},
+		{
+			name: "a # line without a space is text, not a heading",
+			mode: "implementation",
+			reflection: `# Next steps #
+1. Write the benchmark
+2. Profile
+#steps above are tentative
+#1 priority stays the benchmark
+3. Ship`,
+			counts: map[string]int{"steps": 3},
+		},
+		{
+			name: "too many routes",
+			mode: "scout",
+			reflection: `**Possible routes:**
+1. Cache
+2. Precompute
+3. Shard
+4. Rewrite
+5. Wait
+
+**Recommended smallest test:**
+1. Benchmark the cache`,
+			counts: map[string]int{"routes": 5, "smallest test": 1},
+			rules:  []string{"count routes"},
+		},
+		{
+			name:       "unknown mode",
+			mode:       "oracle",
+			reflection: "Anything",
+			counts:     map[string]int{},
+			rules:      []string{"unknown_mode"},
+		},
+		{
+			name: "originating a perspective",
+			mode: "implementation",
+			reflection: `**Next steps:**
+1. You Should take the job.
+2. Here is another perspective: ask for more time.`,
+			counts: map[string]int{"steps": 2},
+			rules:  []string{"originates_perspective", "originates_perspective"},
+		},
+		{
+			name: "questions for the user do not originate",
+			mode: "implementation",
+			reflection: `**Next steps:**
+1. Ask yourself what the job would ask of you.
+2. Name which of your perspectives matters most.`,
+			counts: map[string]int{"steps": 2},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			report := ValidateReflection(tc.mode, tc.reflection)
+			if fmt.Sprint(report.Counts) != fmt.Sprint(tc.counts) {
+				t.Errorf("counted %v, want %v", report.Counts, tc.counts)
+			}
+			if got, want := strings.Join(rules(report), ", "), strings.Join(tc.rules, ", "); got != want {
+				t.Errorf("violations %q, want %q: %v", got, want, report.Violations)
+			}
+			if report.Valid != (len(tc.rules) == 0) {
+				t.Errorf("valid is %v with violations %v", report.Valid, report.Violations)
+			}
+		})
+	}
+}
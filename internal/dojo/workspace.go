@@ -0,0 +1,128 @@
+package dojo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errNoWorkspace is returned by file tools when no workspace is configured
+var errNoWorkspace = errors.New("this needs a workspace; start the server with -workspace or a -data-dir")
+
+// WithWorkspace sets the directory tools read and write files in, such as
+// plans and exported packets
+func WithWorkspace(ws *workspace.Workspace) Option {
+	return func(h *Handler) {
+		h.workspace = ws
+	}
+}
+
+// writeLog collects the workspace and data directory writes made during
+// one tool call
+type writeLog struct {
+	mu     sync.Mutex
+	writes []workspace.Write
+	data   []workspace.Write
+}
+
+type writeLogKey struct{}
+
+// withWriteLog returns a context that collects workspace writes
+func withWriteLog(ctx context.Context) (context.Context, *writeLog) {
+	log := &writeLog{}
+	return context.WithValue(ctx, writeLogKey{}, log), log
+}
+
+// readFile reads a file in the workspace
+func (h *Handler) readFile(name string) ([]byte, error) {
+	if h.workspace == nil {
+		return nil, errNoWorkspace
+	}
+	return h.workspace.ReadFile(name)
+}
+
+// writeFile writes a file in the workspace, noting the write so it is
+// reported in the tool result
+func (h *Handler) writeFile(ctx context.Context, name string, data []byte) error {
+	if h.workspace == nil {
+		return errNoWorkspace
+	}
+	write, err := h.workspace.WriteFile(name, data)
+	if err != nil {
+		return err
+	}
+	if log, ok := ctx.Value(writeLogKey{}).(*writeLog); ok {
+		log.mu.Lock()
+		log.writes = append(log.writes, write)
+		log.mu.Unlock()
+	}
+	return nil
+}
+
+// save persists a store document, noting the write
+func (h *Handler) save(ctx context.Context, name string, v interface{}) error {
+	write, err := h.store.Save(name, v)
+	if err != nil {
+		return err
+	}
+	h.noteDataWrite(ctx, write)
+	return nil
+}
+
+// noteDataWrite notes a store write so it is reported in the tool result
+// when the store keeps a data directory
+func (h *Handler) noteDataWrite(ctx context.Context, write workspace.Write) {
+	if log, ok := ctx.Value(writeLogKey{}).(*writeLog); ok && h.store.Dir() != "" {
+		log.mu.Lock()
+		log.data = append(log.data, write)
+		log.mu.Unlock()
+	}
+}
+
+// reportWrites adds the workspace and data directory writes of a call to
+// its result
+func (h *Handler) reportWrites(result *mcp.CallToolResult, log *writeLog) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if result == nil {
+		return
+	}
+	if len(log.writes) > 0 {
+		text := fmt.Sprintf("**Workspace writes** (in %s):\n%s", h.workspace.Root(), writeLines(log.writes))
+		result.Content = append(result.Content, mcp.NewTextContent(text))
+	}
+	if len(log.data) > 0 {
+		text := fmt.Sprintf("**Data writes** (in %s):\n%s", h.store.Dir(), writeLines(log.data))
+		result.Content = append(result.Content, mcp.NewTextContent(text))
+	}
+}
+
+// writeLines lists writes one per line, each saved file once
+func writeLines(writes []workspace.Write) string {
+	merged := []workspace.Write{}
+	seen := map[string]int{}
+	for _, write := range writes {
+		if i, ok := seen[write.Path]; ok {
+			merged[i].Bytes = write.Bytes
+			continue
+		}
+		seen[write.Path] = len(merged)
+		merged = append(merged, write)
+	}
+
+	lines := make([]string, 0, len(merged))
+	for _, write := range merged {
+		action := "updated"
+		if write.Created {
+			action = "created"
+		}
+		lines = append(lines, fmt.Sprintf("- %s `%s` (%d bytes)", action, write.Path, write.Bytes))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,40 @@
+package dojo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/store"
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
+)
+
+func TestDataWritesAreReported(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithStore(st))
+	text, isError := callTool(t, h.guard(h.handleRemember), "dojo.remember", map[string]interface{}{"project": "Atlas", "content": "Caching was slower than expected"})
+	if isError {
+		t.Fatalf("remember failed: %s", text)
+	}
+	if !strings.Contains(text, "**Data writes**") || !strings.Contains(text, "`memory.json`") {
+		t.Fatalf("data write was not reported: %s", text)
+	}
+}
+
+func TestReadOnlyDataDirectoryRefusesSaves(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := store.Open(dir); err != nil {
+		t.Fatal(err)
+	}
+	st, err := store.Open(dir, workspace.ReadOnly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHandler(t, WithStore(st))
+	text, isError := callTool(t, h.guard(h.handleRemember), "dojo.remember", map[string]interface{}{"project": "Atlas", "content": "Caching was slower than expected"})
+	if !isError || !strings.Contains(text, "read-only") {
+		t.Fatalf("remember saved to a read-only data directory: %s", text)
+	}
+}
@@ -0,0 +1,327 @@
+// Package downstream connects to other MCP servers, spawned over stdio or
+// reached over HTTP with server-sent events, so their tools can be offered
+// under a namespace alongside Dojo's own
+package downstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// DefaultTimeout bounds a downstream call when its config sets no timeout
+const DefaultTimeout = 30 * time.Second
+
+// serverName restricts downstream names to ones usable as a tool namespace
+var serverName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// reservedNames cannot be used by downstreams, so they never shadow
+// Dojo's own tools
+var reservedNames = []string{"dojo"}
+
+// Config describes one downstream server. Exactly one of Command, for a
+// server spawned over stdio, and URL, for an SSE endpoint, is set. Allow
+// lists the tools to offer as path.Match patterns, such as "search_*";
+// an empty list offers every tool.
+type Config struct {
+	Name           string   `json:"name"`
+	Command        string   `json:"command,omitempty"`
+	Args           []string `json:"args,omitempty"`
+	Env            []string `json:"env,omitempty"`
+	URL            string   `json:"url,omitempty"`
+	Allow          []string `json:"allow,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+}
+
+// Load reads a JSON array of downstream configs from a file
+func Load(file string) ([]Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read downstreams: %w", err)
+	}
+
+	var configs []Config
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse downstreams: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, config := range configs {
+		if err := config.check(); err != nil {
+			return nil, err
+		}
+		if seen[config.Name] {
+			return nil, fmt.Errorf("downstream %s is configured twice", config.Name)
+		}
+		seen[config.Name] = true
+	}
+	return configs, nil
+}
+
+func (c Config) check() error {
+	if !serverName.MatchString(c.Name) {
+		return fmt.Errorf("downstream name %q must be lowercase letters, digits, _ or -, starting with a letter", c.Name)
+	}
+	for _, reserved := range reservedNames {
+		if c.Name == reserved {
+			return fmt.Errorf("downstream name %q is reserved", c.Name)
+		}
+	}
+	if (c.Command == "") == (c.URL == "") {
+		return fmt.Errorf("downstream %s needs either a command or a url", c.Name)
+	}
+	for _, pattern := range c.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("downstream %s has an invalid allow pattern %q: %w", c.Name, pattern, err)
+		}
+	}
+	if c.TimeoutSeconds < 0 {
+		return fmt.Errorf("downstream %s has a negative timeout", c.Name)
+	}
+	return nil
+}
+
+// session is the part of an MCP client a downstream uses
+type session interface {
+	Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error)
+	ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error)
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	Close() error
+}
+
+// Status describes a downstream's connection for operators
+type Status struct {
+	Name      string   `json:"name"`
+	Transport string   `json:"transport"`
+	Connected bool     `json:"connected"`
+	Tools     []string `json:"tools"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Server is a connection to one downstream server. A failed call closes the
+// connection and the next call reconnects, so a crashed or hung downstream
+// only affects its own tools.
+type Server struct {
+	config Config
+	mu     sync.Mutex
+	client session
+	cancel context.CancelFunc
+	tools  []mcp.Tool
+	err    error
+	// killGrace is how long a spawned server has to exit once closed
+	killGrace time.Duration
+}
+
+// New returns an unconnected downstream server
+func New(config Config) (*Server, error) {
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+	return &Server{config: config, killGrace: defaultKillGrace}, nil
+}
+
+// Name returns the namespace of the downstream's tools
+func (s *Server) Name() string {
+	return s.config.Name
+}
+
+// Timeout returns how long a call may take
+func (s *Server) Timeout() time.Duration {
+	if s.config.TimeoutSeconds > 0 {
+		return time.Duration(s.config.TimeoutSeconds) * time.Second
+	}
+	return DefaultTimeout
+}
+
+// Connect starts or reaches the downstream, initializes the session and
+// lists the tools it allows
+func (s *Server) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.connect(ctx)
+	return err
+}
+
+// connect replaces any current connection. Callers hold the lock.
+func (s *Server) connect(ctx context.Context) (session, error) {
+	s.disconnect(nil)
+
+	ctx, cancelCall := context.WithTimeout(ctx, s.Timeout())
+	defer cancelCall()
+	// An SSE stream lives as long as the connection, not the call that
+	// opened it
+	live, cancel := context.WithCancel(context.Background())
+
+	c, err := s.dial(live, ctx)
+	if err == nil {
+		err = s.initialize(ctx, c)
+		if err != nil {
+			go c.Close()
+		}
+	}
+	if err != nil {
+		cancel()
+		s.err = err
+		return nil, err
+	}
+
+	s.client, s.cancel, s.err = c, cancel, nil
+	return c, nil
+}
+
+func (s *Server) dial(live, ctx context.Context) (session, error) {
+	if s.config.Command != "" {
+		var env []string
+		if len(s.config.Env) > 0 {
+			env = append(os.Environ(), s.config.Env...)
+		}
+		c, err := dialStdio(s.killGrace, s.config.Command, env, s.config.Args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start %s: %w", s.config.Command, err)
+		}
+		return c, nil
+	}
+
+	c, err := dialSSE(live, ctx, s.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", s.config.URL, err)
+	}
+	return c, nil
+}
+
+func (s *Server) initialize(ctx context.Context, c session) error {
+	init := mcp.InitializeRequest{}
+	init.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	init.Params.ClientInfo = mcp.Implementation{Name: "dojo-genesis", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, init); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	listed, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	s.tools = s.tools[:0]
+	for _, tool := range listed.Tools {
+		if s.allows(tool.Name) {
+			s.tools = append(s.tools, tool)
+		}
+	}
+	sort.Slice(s.tools, func(i, j int) bool { return s.tools[i].Name < s.tools[j].Name })
+	return nil
+}
+
+// allows reports whether the allow-list admits a tool
+func (s *Server) allows(tool string) bool {
+	if len(s.config.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range s.config.Allow {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// disconnect closes the current connection, noting why. Callers hold the
+// lock. Closing gives a spawned server time to exit before killing it, so
+// it happens in the background where a hung server cannot block other
+// calls.
+func (s *Server) disconnect(reason error) {
+	if s.client != nil {
+		go s.client.Close()
+		s.cancel()
+		s.client, s.cancel = nil, nil
+	}
+	if reason != nil {
+		s.err = reason
+	}
+}
+
+// Tools returns the allowed tools under their downstream names, sorted, as
+// listed when the server last connected
+func (s *Server) Tools() []mcp.Tool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]mcp.Tool{}, s.tools...)
+}
+
+// Call invokes a downstream tool by its downstream name, reconnecting
+// first if an earlier call failed. The call is bounded by the timeout.
+func (s *Server) Call(ctx context.Context, tool string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if !s.allows(tool) {
+		return nil, fmt.Errorf("%s is not allowed on downstream %s", tool, s.config.Name)
+	}
+
+	s.mu.Lock()
+	c := s.client
+	if c == nil {
+		var err error
+		if c, err = s.connect(ctx); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout())
+	defer cancel()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool
+	request.Params.Arguments = arguments
+	result, err := c.CallTool(ctx, request)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("no answer within %s", s.Timeout())
+		}
+		s.mu.Lock()
+		if s.client == c {
+			s.disconnect(err)
+		}
+		s.mu.Unlock()
+		return nil, err
+	}
+	return result, nil
+}
+
+// Status reports the downstream's connection
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{Name: s.config.Name, Transport: "stdio", Connected: s.client != nil, Tools: []string{}}
+	if s.config.URL != "" {
+		status.Transport = "sse"
+	}
+	for _, tool := range s.tools {
+		status.Tools = append(status.Tools, tool.Name)
+	}
+	if s.err != nil {
+		status.Error = s.err.Error()
+	}
+	return status
+}
+
+// Close ends the connection, waiting for a spawned server to stop
+func (s *Server) Close() {
+	s.mu.Lock()
+	c, cancel := s.client, s.cancel
+	s.client, s.cancel = nil, nil
+	s.mu.Unlock()
+
+	if c != nil {
+		c.Close()
+		cancel()
+	}
+}
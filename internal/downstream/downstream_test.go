@@ -0,0 +1,197 @@
+package downstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/downstream/downstreamtest"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// stubEnv makes the test binary act as a downstream server when it is
+// spawned by a test: "serve" answers over stdio, "hang" never answers
+const stubEnv = "DOJO_DOWNSTREAM_STUB"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(stubEnv) {
+	case "serve":
+		server.ServeStdio(stubServer())
+		os.Exit(0)
+	case "hang":
+		time.Sleep(time.Hour)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// stubServer offers lookup, which answers at once, slow, which takes
+// longer than the tests' timeout, and secret, which no test allows
+func stubServer() *server.MCPServer {
+	s := server.NewMCPServer("stub", "1.0.0")
+	schema := mcp.ToolInputSchema{Type: "object", Properties: map[string]interface{}{}}
+	s.AddTool(mcp.Tool{Name: "lookup", Description: "Looks up a term", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(fmt.Sprintf("found %v", request.Params.Arguments["term"])), nil
+	})
+	s.AddTool(mcp.Tool{Name: "slow", Description: "Takes its time", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		time.Sleep(3 * time.Second)
+		return mcp.NewToolResultText("done"), nil
+	})
+	s.AddTool(mcp.Tool{Name: "secret", Description: "Never offered", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("leaked"), nil
+	})
+	return s
+}
+
+// stdioConfig spawns the test binary as a stub server
+func stdioConfig(mode string) Config {
+	return Config{
+		Name:           "stub",
+		Command:        os.Args[0],
+		Env:            []string{stubEnv + "=" + mode},
+		Allow:          []string{"lookup", "sl*"},
+		TimeoutSeconds: 1,
+	}
+}
+
+// sseConfig reaches a stub server over SSE
+func sseConfig(t *testing.T) Config {
+	ts := downstreamtest.NewSSEServer(stubServer())
+	t.Cleanup(ts.Close)
+	return Config{Name: "stub", URL: ts.URL + "/sse", Allow: []string{"lookup", "sl*"}, TimeoutSeconds: 1}
+}
+
+func connect(t *testing.T, config Config) *Server {
+	t.Helper()
+	ds, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ds.Close)
+	if err := ds.Connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	return ds
+}
+
+func resultJSON(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestAllowList(t *testing.T) {
+	for name, config := range map[string]func(t *testing.T) Config{
+		"stdio": func(t *testing.T) Config { return stdioConfig("serve") },
+		"sse":   sseConfig,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ds := connect(t, config(t))
+
+			names := []string{}
+			for _, tool := range ds.Tools() {
+				names = append(names, tool.Name)
+			}
+			if strings.Join(names, ",") != "lookup,slow" {
+				t.Fatalf("offered tools %v, want lookup and slow", names)
+			}
+
+			result, err := ds.Call(context.Background(), "lookup", map[string]interface{}{"term": "koan"})
+			if err != nil {
+				t.Fatalf("lookup failed: %v", err)
+			}
+			if !strings.Contains(resultJSON(t, result), "found koan") {
+				t.Fatalf("lookup lost its arguments: %s", resultJSON(t, result))
+			}
+
+			if _, err := ds.Call(context.Background(), "secret", nil); err == nil || !strings.Contains(err.Error(), "not allowed") {
+				t.Fatalf("secret was not refused: %v", err)
+			}
+		})
+	}
+}
+
+func TestTimeoutReconnects(t *testing.T) {
+	for name, config := range map[string]func(t *testing.T) Config{
+		"stdio": func(t *testing.T) Config { return stdioConfig("serve") },
+		"sse":   sseConfig,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ds := connect(t, config(t))
+
+			_, err := ds.Call(context.Background(), "slow", nil)
+			if err == nil || !strings.Contains(err.Error(), "no answer within 1s") {
+				t.Fatalf("slow call gave %v, want a timeout", err)
+			}
+			if status := ds.Status(); status.Connected || status.Error == "" {
+				t.Fatalf("timed out downstream still looks healthy: %+v", status)
+			}
+
+			if _, err := ds.Call(context.Background(), "lookup", map[string]interface{}{"term": "again"}); err != nil {
+				t.Fatalf("call after a timeout did not reconnect: %v", err)
+			}
+			if !ds.Status().Connected {
+				t.Fatal("downstream did not reconnect")
+			}
+		})
+	}
+}
+
+func TestHungServerIsKilled(t *testing.T) {
+	ds, err := New(stdioConfig("hang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.killGrace = 100 * time.Millisecond
+	if err := ds.Connect(context.Background()); err == nil {
+		t.Fatal("connected to a server that never answers")
+	}
+
+	s, err := dialStdio(100*time.Millisecond, os.Args[0], append(os.Environ(), stubEnv+"=hang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	select {
+	case <-s.exited:
+	case <-time.After(5 * time.Second):
+		s.cmd.Process.Kill()
+		t.Fatal("hung server was still running after close")
+	}
+}
+
+func TestSendGivesUpOnAServerThatStopsReading(t *testing.T) {
+	s, err := dialStdio(100*time.Millisecond, os.Args[0], append(os.Environ(), stubEnv+"=hang"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Far more than a pipe buffers, so the write blocks
+	message := rpcRequest{JSONRPC: "2.0", Method: "notifications/flood", Params: strings.Repeat("x", 4<<20)}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	sent := make(chan error, 1)
+	go func() { sent <- s.send(ctx, message) }()
+	select {
+	case err := <-sent:
+		if err == nil {
+			t.Fatal("a server that never reads took the whole message")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("send blocked past its context")
+	}
+	select {
+	case <-s.exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the session was not closed after the send gave up")
+	}
+}
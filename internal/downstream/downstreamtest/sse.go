@@ -0,0 +1,86 @@
+// Package downstreamtest serves an MCP server over server-sent events for
+// tests. It stands in for the SSE server of mcp-go, whose stream and
+// message handlers race with each other.
+package downstreamtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewSSEServer serves s with its event stream at /sse. Each stream
+// announces its own message endpoint; a request posted there is answered
+// on the stream, so slow tools do not hold up the post.
+func NewSSEServer(s *server.MCPServer) *httptest.Server {
+	var mu sync.Mutex
+	streams := map[string]chan []byte{}
+	next := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		next++
+		id := fmt.Sprint(next)
+		out := make(chan []byte, 16)
+		streams[id] = out
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			delete(streams, id)
+			mu.Unlock()
+		}()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "event: endpoint\ndata: /message?session=%s\n\n", id)
+		flusher.Flush()
+		for {
+			select {
+			case message := <-out:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", message)
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/message", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		out, ok := streams[r.URL.Query().Get("session")]
+		mu.Unlock()
+		if !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+
+		go func() {
+			response := s.HandleMessage(context.Background(), body)
+			if response == nil {
+				return
+			}
+			message, err := json.Marshal(response)
+			if err != nil {
+				return
+			}
+			out <- message
+		}()
+	})
+	return httptest.NewServer(mux)
+}
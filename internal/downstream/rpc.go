@@ -0,0 +1,150 @@
+package downstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int64      `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     *int64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// rpcConn matches JSON-RPC requests to their responses over any transport.
+// The transport sends messages with send and hands every message it
+// receives to deliver. The client in mcp-go is not used for either
+// transport: it drops request params over SSE and prints read errors to
+// stdout, which would corrupt Dojo's own stdio transport.
+type rpcConn struct {
+	send func(ctx context.Context, message rpcRequest) error
+
+	mu        sync.Mutex
+	nextID    int64
+	responses map[int64]chan rpcResponse
+	done      chan struct{}
+	err       error
+}
+
+func newRPCConn(send func(ctx context.Context, message rpcRequest) error) rpcConn {
+	return rpcConn{send: send, responses: map[int64]chan rpcResponse{}, done: make(chan struct{})}
+}
+
+// deliver passes a response to the request waiting for it. Notifications
+// and requests from the server are ignored.
+func (c *rpcConn) deliver(payload []byte) {
+	var response rpcResponse
+	if err := json.Unmarshal(payload, &response); err != nil || response.ID == nil {
+		return
+	}
+	c.mu.Lock()
+	ch, ok := c.responses[*response.ID]
+	delete(c.responses, *response.ID)
+	c.mu.Unlock()
+	if ok {
+		ch <- response
+	}
+}
+
+// fail ends every waiting request with err
+func (c *rpcConn) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err == nil {
+		c.err = err
+		close(c.done)
+	}
+	c.responses = map[int64]chan rpcResponse{}
+}
+
+func (c *rpcConn) closedErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+// request sends a request and waits for its response
+func (c *rpcConn) request(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan rpcResponse, 1)
+	c.responses[id] = ch
+	c.mu.Unlock()
+
+	forget := func() {
+		c.mu.Lock()
+		delete(c.responses, id)
+		c.mu.Unlock()
+	}
+	if err := c.send(ctx, rpcRequest{JSONRPC: mcp.JSONRPC_VERSION, ID: &id, Method: method, Params: params}); err != nil {
+		forget()
+		return err
+	}
+
+	select {
+	case response := <-ch:
+		if response.Error != nil {
+			return fmt.Errorf("%s failed: %s", method, response.Error.Message)
+		}
+		return json.Unmarshal(response.Result, result)
+	case <-c.done:
+		return c.closedErr()
+	case <-ctx.Done():
+		forget()
+		return ctx.Err()
+	}
+}
+
+func (c *rpcConn) Initialize(ctx context.Context, request mcp.InitializeRequest) (*mcp.InitializeResult, error) {
+	params := struct {
+		ProtocolVersion string                 `json:"protocolVersion"`
+		ClientInfo      mcp.Implementation     `json:"clientInfo"`
+		Capabilities    mcp.ClientCapabilities `json:"capabilities"`
+	}{request.Params.ProtocolVersion, request.Params.ClientInfo, request.Params.Capabilities}
+
+	var result mcp.InitializeResult
+	if err := c.request(ctx, "initialize", params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.send(ctx, rpcRequest{JSONRPC: mcp.JSONRPC_VERSION, Method: "notifications/initialized"}); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *rpcConn) ListTools(ctx context.Context, request mcp.ListToolsRequest) (*mcp.ListToolsResult, error) {
+	var result mcp.ListToolsResult
+	if err := c.request(ctx, "tools/list", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *rpcConn) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var result mcp.CallToolResult
+	if err := c.request(ctx, "tools/call", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,162 @@
+package downstream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxEventSize bounds one server-sent event, so a large tool result is
+// not cut off by the scanner's default limit
+const maxEventSize = 4 << 20
+
+// sseSession speaks MCP over HTTP with server-sent events
+type sseSession struct {
+	rpcConn
+	base     *url.URL
+	http     *http.Client
+	endpoint *url.URL
+	body     io.ReadCloser
+}
+
+// dialSSE opens the event stream and waits for the server to announce the
+// endpoint that takes requests. The stream stays open until live ends or
+// the session is closed; ctx bounds only the wait.
+func dialSSE(live, ctx context.Context, address string) (*sseSession, error) {
+	base, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(live, http.MethodGet, base.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	s := &sseSession{base: base, http: &http.Client{}}
+	s.rpcConn = newRPCConn(s.post)
+	type opened struct {
+		resp *http.Response
+		err  error
+	}
+	dialed := make(chan opened, 1)
+	go func() {
+		resp, err := s.http.Do(req)
+		dialed <- opened{resp, err}
+	}()
+
+	var resp *http.Response
+	select {
+	case o := <-dialed:
+		if o.err != nil {
+			return nil, o.err
+		}
+		resp = o.resp
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	s.body = resp.Body
+
+	endpoint := make(chan *url.URL, 1)
+	go s.read(endpoint)
+	select {
+	case e, ok := <-endpoint:
+		if !ok {
+			s.Close()
+			return nil, fmt.Errorf("stream closed before announcing its endpoint: %v", s.closedErr())
+		}
+		s.endpoint = e
+		return s, nil
+	case <-ctx.Done():
+		s.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// read handles events until the stream ends, sending the announced
+// endpoint once and routing responses to their waiting requests
+func (s *sseSession) read(endpoint chan<- *url.URL) {
+	announced := false
+	defer func() {
+		if !announced {
+			close(endpoint)
+		}
+	}()
+
+	scanner := bufio.NewScanner(s.body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				payload := strings.Join(data, "\n")
+				if event == "endpoint" && !announced {
+					if e, err := s.base.Parse(payload); err == nil && e.Host == s.base.Host {
+						endpoint <- e
+						announced = true
+					}
+				} else if event == "" || event == "message" {
+					s.deliver([]byte(payload))
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	s.fail(fmt.Errorf("stream ended: %w", err))
+}
+
+// post sends one message to the endpoint
+func (s *sseSession) post(ctx context.Context, message rpcRequest) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", message.Method, resp.Status, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+// Close ends the stream and every waiting request
+func (s *sseSession) Close() error {
+	s.fail(fmt.Errorf("connection closed"))
+	if s.body != nil {
+		return s.body.Close()
+	}
+	return nil
+}
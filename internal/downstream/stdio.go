@@ -0,0 +1,113 @@
+package downstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// defaultKillGrace is how long a spawned server has to exit once its input
+// is closed before it is killed
+const defaultKillGrace = 2 * time.Second
+
+// stdioSession speaks MCP with a spawned server over its stdin and stdout.
+// The server's stderr is passed through to Dojo's, never its stdout.
+type stdioSession struct {
+	rpcConn
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	write  sync.Mutex
+	exited chan struct{}
+	closed sync.Once
+	// grace is how long the server has to exit after its input is closed
+	grace time.Duration
+}
+
+// dialStdio starts a server and begins reading its responses. Once closed,
+// the server is killed if still running after grace.
+func dialStdio(grace time.Duration, command string, env []string, args ...string) (*stdioSession, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Env = env
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	s := &stdioSession{cmd: cmd, stdin: stdin, exited: make(chan struct{}), grace: grace}
+	s.rpcConn = newRPCConn(s.send)
+	go s.read(stdout)
+	return s, nil
+}
+
+// read handles one message per line until the server closes its stdout,
+// then reaps the process
+func (s *stdioSession) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		s.deliver(scanner.Bytes())
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	s.fail(fmt.Errorf("server output ended: %w", err))
+	s.cmd.Wait()
+	close(s.exited)
+}
+
+// send writes one message as a line to the server's stdin. A server that
+// stops reading its input would block the write, so when ctx ends first the
+// session is closed, which unblocks it.
+func (s *stdioSession) send(ctx context.Context, message rpcRequest) error {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	written := make(chan error, 1)
+	go func() {
+		s.write.Lock()
+		defer s.write.Unlock()
+		_, err := s.stdin.Write(append(line, '\n'))
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		return err
+	case <-ctx.Done():
+		go s.Close()
+		return ctx.Err()
+	}
+}
+
+// Close ends every waiting request and closes the server's stdin, which
+// asks a well-behaved server to exit. A server still running after
+// its grace is killed, so a hung server never outlives its connection.
+func (s *stdioSession) Close() error {
+	s.closed.Do(func() {
+		s.fail(fmt.Errorf("connection closed"))
+		s.stdin.Close()
+		select {
+		case <-s.exited:
+		case <-time.After(s.grace):
+			s.cmd.Process.Kill()
+		}
+	})
+	return nil
+}
@@ -0,0 +1,226 @@
+// Package packet defines DojoPacket, the portable bundle a project's
+// artifacts, memory and trace are exported in
+package packet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the DojoPacket format version written by this package
+const Version = "1.0"
+
+// ManifestName is the name of the packet document inside a zip packet
+const ManifestName = "packet.json"
+
+// Packet is a DojoPacket: a project with everything needed to resume it
+type Packet struct {
+	Version   string     `json:"dojo_packet_version"`
+	Project   Project    `json:"project"`
+	Artifacts []Artifact `json:"artifacts"`
+	Memory    Memory     `json:"memory"`
+	Trace     Trace      `json:"trace"`
+	Metadata  Metadata   `json:"metadata"`
+}
+
+// Project identifies the project a packet was exported from
+type Project struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Artifact is one piece of work produced in the project. Version counts how
+// many times the artifact was written.
+type Artifact struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Name    string          `json:"name"`
+	Version int             `json:"version"`
+	Content json.RawMessage `json:"content"`
+	Exports []Export        `json:"exports"`
+}
+
+// Export is a rendering of an artifact stored alongside the packet
+type Export struct {
+	Format string `json:"format"`
+	Path   string `json:"path"`
+}
+
+// Memory holds the seeds applied in the project and its condensed history
+type Memory struct {
+	Seeds             []SeedUse         `json:"seeds"`
+	CompressedHistory []json.RawMessage `json:"compressed_history"`
+	Snapshots         []json.RawMessage `json:"snapshots"`
+}
+
+// SeedUse records one application of a seed
+type SeedUse struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	Hash      string    `json:"hash"`
+	Situation string    `json:"situation"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// Trace holds the sessions that worked on the project
+type Trace struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// Session is the tool calls one client session made for the project
+type Session struct {
+	ID        string    `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	Calls     []Call    `json:"calls"`
+}
+
+// Call is one traced tool call. RestOverride holds the reason given for a
+// call made while its session was sent to the onsen to rest.
+type Call struct {
+	Tool         string    `json:"tool"`
+	At           time.Time `json:"at"`
+	Words        int       `json:"words"`
+	RestOverride string    `json:"rest_override,omitempty"`
+}
+
+// Metadata summarizes the packet. TotalTokens is estimated from the words
+// passed to traced calls.
+type Metadata struct {
+	TotalArtifacts int       `json:"total_artifacts"`
+	TotalSessions  int       `json:"total_sessions"`
+	TotalTokens    int       `json:"total_tokens"`
+	ExportedAt     time.Time `json:"exported_at"`
+	Generator      string    `json:"generator"`
+}
+
+// Encode renders a packet as indented JSON, checked against the schema
+func Encode(p Packet) ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode packet: %w", err)
+	}
+	if err := Validate(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// maxManifestSize bounds how much of a zip packet's packet.json is read
+const maxManifestSize = 64 << 20
+
+// Decode parses a packet, checking it against the schema first
+func Decode(data []byte) (Packet, error) {
+	if err := Validate(data); err != nil {
+		return Packet{}, err
+	}
+	var p Packet
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Packet{}, fmt.Errorf("failed to parse packet: %w", err)
+	}
+	return p, nil
+}
+
+// Read parses a packet given as JSON or as a zip archive holding
+// packet.json. The artifact files of a zip packet are not needed to restore
+// it, since artifacts carry their content.
+func Read(data []byte) (Packet, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return Decode(data)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Packet{}, fmt.Errorf("failed to open packet archive: %w", err)
+	}
+	for _, f := range archive.File {
+		if f.Name != ManifestName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return Packet{}, fmt.Errorf("failed to read %s: %w", ManifestName, err)
+		}
+		defer rc.Close()
+		manifest, err := io.ReadAll(io.LimitReader(rc, maxManifestSize+1))
+		if err != nil {
+			return Packet{}, fmt.Errorf("failed to read %s: %w", ManifestName, err)
+		}
+		if len(manifest) > maxManifestSize {
+			return Packet{}, fmt.Errorf("%s is larger than %d bytes", ManifestName, maxManifestSize)
+		}
+		return Decode(manifest)
+	}
+	return Packet{}, fmt.Errorf("packet archive has no %s", ManifestName)
+}
+
+// WriteZip writes a packet as a zip archive holding packet.json and the
+// export files of its artifacts. files maps export paths to their contents;
+// every export path must have a file and every file must be exported.
+func WriteZip(w io.Writer, p Packet, files map[string][]byte) error {
+	exported := map[string]bool{}
+	for _, artifact := range p.Artifacts {
+		for _, export := range artifact.Exports {
+			if err := checkExportPath(export.Path); err != nil {
+				return fmt.Errorf("artifact %s: %w", artifact.ID, err)
+			}
+			if _, ok := files[export.Path]; !ok {
+				return fmt.Errorf("artifact %s: no file for export %s", artifact.ID, export.Path)
+			}
+			exported[export.Path] = true
+		}
+	}
+	paths := make([]string, 0, len(files))
+	for name := range files {
+		if !exported[name] {
+			return fmt.Errorf("file %s is not exported by any artifact", name)
+		}
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	manifest, err := Encode(p)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	add := func(name string, data []byte) error {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: p.Metadata.ExportedAt})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	if err := add(ManifestName, manifest); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestName, err)
+	}
+	for _, name := range paths {
+		if err := add(name, files[name]); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write packet archive: %w", err)
+	}
+	return nil
+}
+
+// checkExportPath requires export paths to be clean relative paths under
+// artifacts/
+func checkExportPath(name string) error {
+	if name != path.Clean(name) || !strings.HasPrefix(name, "artifacts/") || strings.Contains(name, "\\") {
+		return fmt.Errorf("export path %q must be a clean relative path under artifacts/", name)
+	}
+	return nil
+}
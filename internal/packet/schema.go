@@ -0,0 +1,234 @@
+package packet
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// schemaJSON is the JSON Schema every packet is validated against
+//
+//go:embed dojo_packet.v1.schema.json
+var schemaJSON []byte
+
+// Schema returns the DojoPacket v1.0 JSON Schema
+func Schema() []byte {
+	return append([]byte{}, schemaJSON...)
+}
+
+// schema is the subset of JSON Schema the packet schema is written in.
+// Unsupported keywords are rejected when the schema is parsed, so the
+// schema cannot silently ask for checks that are not made.
+type schema struct {
+	Schema               string             `json:"$schema"`
+	ID                   string             `json:"$id"`
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*schema `json:"$defs"`
+	Title                string             `json:"title"`
+	Description          string             `json:"description"`
+	Type                 string             `json:"type"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	Pattern              string             `json:"pattern"`
+	Format               string             `json:"format"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+
+	pattern *regexp.Regexp
+}
+
+// packetSchema is the parsed packet schema
+var packetSchema = mustParseSchema(schemaJSON)
+
+func mustParseSchema(data []byte) *schema {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var root schema
+	if err := decoder.Decode(&root); err != nil {
+		panic(fmt.Sprintf("packet: invalid schema: %v", err))
+	}
+	if err := root.compile(&root); err != nil {
+		panic(fmt.Sprintf("packet: invalid schema: %v", err))
+	}
+	return &root
+}
+
+// compile checks references and compiles patterns throughout the schema
+func (s *schema) compile(root *schema) error {
+	if s.Ref != "" {
+		if _, err := root.resolve(s.Ref); err != nil {
+			return err
+		}
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+	switch s.Format {
+	case "", "date-time":
+	default:
+		return fmt.Errorf("unsupported format %q", s.Format)
+	}
+	children := []*schema{s.Items}
+	for _, child := range s.Properties {
+		children = append(children, child)
+	}
+	for _, child := range s.Defs {
+		children = append(children, child)
+	}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve follows a "#/$defs/name" reference
+func (s *schema) resolve(ref string) (*schema, error) {
+	name := strings.TrimPrefix(ref, "#/$defs/")
+	if def, ok := s.Defs[name]; ok && name != ref {
+		return def, nil
+	}
+	return nil, fmt.Errorf("unresolvable reference %q", ref)
+}
+
+// Validate checks a JSON document against the DojoPacket schema, reporting
+// every problem found
+func Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return fmt.Errorf("packet is not valid JSON: %w", err)
+	}
+
+	problems := []string{}
+	packetSchema.validate(packetSchema, "packet", document, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("packet does not match the DojoPacket %s schema:\n- %s", Version, strings.Join(problems, "\n- "))
+	}
+	return nil
+}
+
+// validate appends a problem for each way value fails the schema
+func (s *schema) validate(root *schema, at string, value interface{}, problems *[]string) {
+	if s.Ref != "" {
+		def, _ := root.resolve(s.Ref)
+		def.validate(root, at, value, problems)
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		fail("must be %s", article(s.Type))
+		return
+	}
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, option := range s.Enum {
+			if fmt.Sprint(option) == fmt.Sprint(value) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			fail("must be one of %v", s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %s", s.Pattern)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				fail("must be an RFC 3339 date-time")
+			}
+		}
+	case json.Number:
+		if s.Minimum != nil {
+			if n, _ := v.Float64(); n < *s.Minimum {
+				fail("must be at least %v", *s.Minimum)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, fmt.Sprintf("%s[%d]", at, i), item, problems)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("%s is required", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					fail("unexpected property %s", name)
+				}
+				continue
+			}
+			property.validate(root, at+"."+name, v[name], problems)
+		}
+	}
+}
+
+// hasType reports whether a decoded JSON value is of a JSON Schema type
+func hasType(value interface{}, kind string) bool {
+	switch v := value.(type) {
+	case string:
+		return kind == "string"
+	case bool:
+		return kind == "boolean"
+	case nil:
+		return kind == "null"
+	case []interface{}:
+		return kind == "array"
+	case map[string]interface{}:
+		return kind == "object"
+	case json.Number:
+		if kind == "number" {
+			return true
+		}
+		n, err := v.Float64()
+		return kind == "integer" && err == nil && n == math.Trunc(n)
+	}
+	return false
+}
+
+func article(kind string) string {
+	switch kind {
+	case "array", "integer", "object":
+		return "an " + kind
+	}
+	return "a " + kind
+}
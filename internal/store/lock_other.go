@@ -0,0 +1,9 @@
+//go:build !unix
+
+package store
+
+// lockFile does nothing where advisory file locks are not available, so
+// documents are only locked within one process
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,176 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
+)
+
+// documentName restricts document names to plain file names
+var documentName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Store persists named JSON documents in a directory, one file per
+// document. The directory is a workspace, so saves follow its policy:
+// read-only mode, file size limit and quota. A store without a directory
+// keeps documents in memory only.
+type Store struct {
+	dir    *workspace.Workspace
+	mu     sync.Mutex
+	memory map[string][]byte
+}
+
+// Open returns a store rooted at dir, creating the directory if needed, and
+// saving under the workspace options given. An empty dir opens an
+// in-memory store.
+func Open(dir string, opts ...workspace.Option) (*Store, error) {
+	s := &Store{memory: map[string][]byte{}}
+	if dir == "" {
+		return s, nil
+	}
+	ws, err := workspace.Open(dir, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data directory: %w", err)
+	}
+	s.dir = ws
+	return s, nil
+}
+
+// Memory returns an in-memory store
+func Memory() *Store {
+	s, _ := Open("")
+	return s
+}
+
+// Dir returns the directory documents are saved in, or "" for memory
+func (s *Store) Dir() string {
+	if s.dir == nil {
+		return ""
+	}
+	return s.dir.Root()
+}
+
+// ReadOnly reports whether saves are refused
+func (s *Store) ReadOnly() bool {
+	return s.dir != nil && s.dir.ReadOnly()
+}
+
+// Load decodes a document into v. It reports false, with v untouched, when
+// the document does not exist yet.
+func (s *Store) Load(name string, v interface{}) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(name, v)
+}
+
+// Save encodes v as the named document and describes the file written.
+// Files are replaced atomically so a crash never leaves a half-written
+// document behind.
+func (s *Store) Save(name string, v interface{}) (workspace.Write, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(name)
+	if err != nil {
+		return workspace.Write{}, err
+	}
+	defer unlock()
+	return s.save(name, v)
+}
+
+// Update reloads a document into v, lets change modify v and saves it. The
+// document stays locked throughout, within this process and, for a data
+// directory, across every process sharing it, so concurrent updates build
+// on each other instead of overwriting each other. v should be empty when
+// passed in. When change fails nothing is saved.
+func (s *Store) Update(name string, v interface{}, change func() error) (workspace.Write, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock(name)
+	if err != nil {
+		return workspace.Write{}, err
+	}
+	defer unlock()
+	if _, err := s.load(name, v); err != nil {
+		return workspace.Write{}, err
+	}
+	if err := change(); err != nil {
+		return workspace.Write{}, err
+	}
+	return s.save(name, v)
+}
+
+// lock takes the lock other processes sharing the data directory see for
+// a document. Callers hold s.mu.
+func (s *Store) lock(name string) (func(), error) {
+	if !documentName.MatchString(name) {
+		return nil, fmt.Errorf("invalid document name %q", name)
+	}
+	if s.dir == nil {
+		return func() {}, nil
+	}
+	if s.dir.ReadOnly() {
+		return nil, fmt.Errorf("cannot save %s: %w", name, workspace.ErrReadOnly)
+	}
+	unlock, err := lockFile(filepath.Join(s.dir.Root(), name+".lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", name, err)
+	}
+	return unlock, nil
+}
+
+func (s *Store) load(name string, v interface{}) (bool, error) {
+	if !documentName.MatchString(name) {
+		return false, fmt.Errorf("invalid document name %q", name)
+	}
+
+	var data []byte
+	if s.dir == nil {
+		var ok bool
+		if data, ok = s.memory[name]; !ok {
+			return false, nil
+		}
+	} else {
+		var err error
+		data, err = s.dir.ReadFile(fileName(name))
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return true, nil
+}
+
+func (s *Store) save(name string, v interface{}) (workspace.Write, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return workspace.Write{}, fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	if s.dir == nil {
+		_, existed := s.memory[name]
+		s.memory[name] = data
+		return workspace.Write{Path: fileName(name), Bytes: len(data), Created: !existed}, nil
+	}
+	write, err := s.dir.WriteFile(fileName(name), data)
+	if err != nil {
+		return workspace.Write{}, fmt.Errorf("failed to save %s: %w", name, err)
+	}
+	return write, nil
+}
+
+func fileName(name string) string {
+	return name + ".json"
+}
@@ -0,0 +1,134 @@
+package store
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/TresPies-source/dojo-mcp-server/internal/workspace"
+)
+
+func TestSaveReportsWrites(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	write, err := s.Save("notes", []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if write.Path != "notes.json" || !write.Created || write.Bytes == 0 {
+		t.Fatalf("unexpected first write %+v", write)
+	}
+	if write, err = s.Save("notes", []string{"a", "b"}); err != nil || write.Created {
+		t.Fatalf("second save gave %+v, %v", write, err)
+	}
+
+	var notes []string
+	if ok, err := s.Load("notes", &notes); !ok || err != nil || len(notes) != 2 {
+		t.Fatalf("load gave %v, %v, %v", notes, ok, err)
+	}
+}
+
+func TestSaveFollowsWorkspacePolicy(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Open(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	readOnly, err := Open(dir, workspace.ReadOnly(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !readOnly.ReadOnly() {
+		t.Fatal("store does not report read-only")
+	}
+	if _, err := readOnly.Save("notes", []string{"a"}); !errors.Is(err, workspace.ErrReadOnly) {
+		t.Fatalf("read-only store saved: %v", err)
+	}
+
+	limited, err := Open(dir, workspace.MaxFileSize(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limited.Save("notes", []string{"far too long for sixteen bytes"}); err == nil {
+		t.Fatal("document over the file limit was saved")
+	}
+
+	quota, err := Open(dir, workspace.Quota(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := quota.Save("small", []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := quota.Save("large", []string{"this document pushes the data directory over its quota"}); err == nil {
+		t.Fatal("document over the quota was saved")
+	}
+}
+
+func TestLoadRejectsInvalidNames(t *testing.T) {
+	s := Memory()
+	var v interface{}
+	if _, err := s.Load("../escape", &v); err == nil {
+		t.Fatal("invalid document name was accepted")
+	}
+	if _, err := s.Save("../escape", v); err == nil {
+		t.Fatal("invalid document name was saved")
+	}
+}
+
+func TestUpdateBuildsOnConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	// Two stores over one directory stand in for two processes
+	stores := make([]*Store, 2)
+	for i := range stores {
+		s, err := Open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stores[i] = s
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var items []int
+			if _, err := stores[i%2].Update("items", &items, func() error {
+				items = append(items, i)
+				return nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var items []int
+	if _, err := stores[0].Load("items", &items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 40 {
+		t.Fatalf("kept %d of 40 concurrent updates", len(items))
+	}
+}
+
+func TestUpdateSavesNothingWhenChangeFails(t *testing.T) {
+	s := Memory()
+	if _, err := s.Save("items", []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	var items []int
+	if _, err := s.Update("items", &items, func() error {
+		items = append(items, 2)
+		return errors.New("no")
+	}); err == nil {
+		t.Fatal("failed change was not reported")
+	}
+	items = nil
+	if _, err := s.Load("items", &items); err != nil || len(items) != 1 {
+		t.Fatalf("failed change was saved: %v, %v", items, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Package summary condenses text offline by extracting its most
+// representative sentences, without a language model
+package summary
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sentenceEnd matches the end of a sentence or a line break
+var sentenceEnd = regexp.MustCompile(`[.!?]+["')\]]*(\s+|$)|\n+`)
+
+// word matches the words sentences are scored by
+var word = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+// stopwords are common words that say little about what a text is about
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "any": true, "can": true, "had": true, "her": true,
+	"was": true, "one": true, "our": true, "out": true, "has": true, "have": true,
+	"his": true, "how": true, "its": true, "may": true, "new": true, "now": true,
+	"see": true, "who": true, "did": true, "get": true, "she": true, "too": true,
+	"use": true, "that": true, "with": true, "this": true, "from": true, "they": true,
+	"will": true, "would": true, "there": true, "their": true, "what": true, "about": true,
+	"which": true, "when": true, "were": true, "been": true, "into": true, "than": true,
+	"then": true, "them": true, "these": true, "some": true, "could": true, "should": true,
+	"also": true, "because": true, "just": true, "very": true, "more": true, "most": true,
+	"over": true, "only": true, "other": true, "such": true, "here": true, "where": true,
+	"while": true, "being": true, "does": true, "each": true, "we're": true, "it's": true,
+}
+
+// Sentences splits text into trimmed sentences, treating line breaks as
+// sentence ends so list items stay separate
+func Sentences(text string) []string {
+	sentences := []string{}
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		if sentence := strings.TrimSpace(text[start:loc[1]]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = loc[1]
+	}
+	if sentence := strings.TrimSpace(text[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// Terms returns the content words of a sentence in lowercase
+func Terms(sentence string) []string {
+	found := []string{}
+	for _, w := range word.FindAllString(strings.ToLower(sentence), -1) {
+		if len(w) >= 3 && !stopwords[w] {
+			found = append(found, w)
+		}
+	}
+	return found
+}
+
+// candidate is a sentence considered for a summary
+type candidate struct {
+	text  string
+	terms []string
+	order int
+	score float64
+}
+
+// Extract picks up to max sentences that best represent texts, returned in
+// the order they appear. Sentences score by how frequent their content
+// words are across all the texts, so recurring themes win; the opening
+// sentence of each text gets a small boost, and sentences repeating the
+// words of one already picked are skipped.
+func Extract(texts []string, max int) []string {
+	frequency := map[string]int{}
+	candidates := []candidate{}
+	for _, text := range texts {
+		for i, sentence := range Sentences(text) {
+			c := candidate{text: sentence, terms: Terms(sentence), order: len(candidates)}
+			for _, t := range c.terms {
+				frequency[t]++
+			}
+			if i == 0 {
+				c.score = 0.1
+			}
+			candidates = append(candidates, c)
+		}
+	}
+	if max <= 0 || len(candidates) == 0 {
+		return []string{}
+	}
+
+	top := 0
+	for _, n := range frequency {
+		if n > top {
+			top = n
+		}
+	}
+	for i := range candidates {
+		c := &candidates[i]
+		if len(c.terms) == 0 {
+			continue
+		}
+		total := 0.0
+		for _, t := range c.terms {
+			total += float64(frequency[t]) / float64(top)
+		}
+		// Fragments of a word or two say too little to stand for a text
+		c.score += total / float64(len(c.terms)) * math.Min(1, float64(len(c.terms))/4)
+	}
+
+	ranked := append([]candidate{}, candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	picked := []candidate{}
+	for _, c := range ranked {
+		if len(picked) == max {
+			break
+		}
+		if redundant(c.terms, picked) {
+			continue
+		}
+		picked = append(picked, c)
+	}
+	sort.Slice(picked, func(i, j int) bool { return picked[i].order < picked[j].order })
+
+	summary := make([]string, 0, len(picked))
+	for _, c := range picked {
+		summary = append(summary, c.text)
+	}
+	return summary
+}
+
+// redundant reports whether most of a sentence's content words are already
+// in a picked sentence
+func redundant(words []string, picked []candidate) bool {
+	if len(words) == 0 {
+		return true
+	}
+	for _, p := range picked {
+		shared := 0
+		for _, w := range words {
+			for _, t := range p.terms {
+				if w == t {
+					shared++
+					break
+				}
+			}
+		}
+		if shared*5 >= len(words)*4 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,343 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ErrSamplingUnsupported is returned when the connected client did not
+// advertise the sampling capability during initialization
+var ErrSamplingUnsupported = errors.New("client does not support sampling")
+
+// sessionKey is the context key for storing the client session
+type sessionKey struct{}
+
+// SessionFromContext retrieves the client session from a context
+func SessionFromContext(ctx context.Context) *Session {
+	if session, ok := ctx.Value(sessionKey{}).(*Session); ok {
+		return session
+	}
+	return nil
+}
+
+// Session represents the connected MCP client. Unlike the stock stdio server
+// it can issue requests back to the client (e.g. sampling/createMessage).
+type Session struct {
+	id      string
+	out     io.Writer
+	writeMu sync.Mutex
+
+	mu           sync.Mutex
+	capabilities mcp.ClientCapabilities
+	clientInfo   mcp.Implementation
+	nextID       int64
+	pending      map[string]chan clientResponse
+	closed       chan struct{}
+}
+
+// clientResponse is a response from the client to a server-initiated request
+type clientResponse struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func newSession(id string, out io.Writer) *Session {
+	return &Session{
+		id:      id,
+		out:     out,
+		pending: make(map[string]chan clientResponse),
+		closed:  make(chan struct{}),
+	}
+}
+
+// newSessionID returns a random identifier for one client connection, so
+// traces and rest state of different connections are kept apart
+func newSessionID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "stdio_" + hex.EncodeToString(b)
+}
+
+// close ends every request waiting on the client, since no more responses
+// can arrive once its input is gone
+func (s *Session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
+}
+
+// ID returns the session identifier
+func (s *Session) ID() string {
+	return s.id
+}
+
+// ClientInfo returns the name and version the client reported on initialize
+func (s *Session) ClientInfo() mcp.Implementation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientInfo
+}
+
+// SupportsSampling reports whether the client advertised sampling support
+func (s *Session) SupportsSampling() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.capabilities.Sampling != nil
+}
+
+// CreateMessage asks the client to sample its LLM and waits for the result
+func (s *Session) CreateMessage(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+	if !s.SupportsSampling() {
+		return nil, ErrSamplingUnsupported
+	}
+
+	var result mcp.CreateMessageResult
+	if err := s.call(ctx, "sampling/createMessage", request.Params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// call sends a JSON-RPC request to the client and decodes its result
+func (s *Session) call(ctx context.Context, method string, params interface{}, dest interface{}) error {
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("dojo-%d", s.nextID)
+	replyChan := make(chan clientResponse, 1)
+	s.pending[id] = replyChan
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+	}()
+
+	if err := s.write(map[string]interface{}{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	}); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.closed:
+		return fmt.Errorf("%s failed: the client closed the connection", method)
+	case reply := <-replyChan:
+		if reply.Error != nil {
+			return fmt.Errorf("%s failed: %s (code %d)", method, reply.Error.Message, reply.Error.Code)
+		}
+		if err := json.Unmarshal(reply.Result, dest); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// deliver routes a client response to the request waiting for it. The
+// request is no longer pending once answered, so a repeated response is
+// not delivered and never blocks the reader.
+func (s *Session) deliver(id string, reply clientResponse) bool {
+	s.mu.Lock()
+	replyChan, ok := s.pending[id]
+	delete(s.pending, id)
+	s.mu.Unlock()
+	if ok {
+		select {
+		case replyChan <- reply:
+		default:
+		}
+	}
+	return ok
+}
+
+// write marshals a message and writes it followed by a newline
+func (s *Session) write(message interface{}) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, err = fmt.Fprintf(s.out, "%s\n", data)
+	return err
+}
+
+// StdioServer serves an MCPServer over stdio while tracking the client
+// session, so that tool handlers can call back into the client.
+type StdioServer struct {
+	server    *server.MCPServer
+	errLogger *log.Logger
+}
+
+// NewStdioServer creates a new stdio transport around an MCPServer
+func NewStdioServer(srv *server.MCPServer) *StdioServer {
+	return &StdioServer{
+		server:    srv,
+		errLogger: log.New(os.Stderr, "", log.LstdFlags),
+	}
+}
+
+// SetErrorLogger configures where transport errors are logged
+func (s *StdioServer) SetErrorLogger(logger *log.Logger) {
+	s.errLogger = logger
+}
+
+// Listen reads JSON-RPC messages from in and writes responses to out until
+// the input is exhausted or the context is cancelled. Requests are handled
+// concurrently so a handler waiting on the client does not block the reader.
+func (s *StdioServer) Listen(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Handlers in flight finish and write their responses before the
+	// context is cancelled. Closing the session first unblocks any handler
+	// waiting on a client that has gone.
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	session := newSession(newSessionID(), out)
+	defer session.close()
+	ctx = context.WithValue(ctx, sessionKey{}, session)
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadString('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if err == io.EOF {
+				return nil
+			}
+			s.errLogger.Printf("Error reading input: %v", err)
+			return err
+		case line := <-lines:
+			var envelope struct {
+				ID     interface{}     `json:"id,omitempty"`
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params,omitempty"`
+			}
+			if err := json.Unmarshal([]byte(line), &envelope); err != nil {
+				s.writeMessage(session, mcp.NewJSONRPCError(nil, mcp.PARSE_ERROR, "Parse error", nil))
+				continue
+			}
+
+			// A message with an id but no method answers one of our requests
+			if envelope.Method == "" && envelope.ID != nil {
+				var reply clientResponse
+				if err := json.Unmarshal([]byte(line), &reply); err != nil {
+					s.errLogger.Printf("Invalid client response: %v", err)
+					continue
+				}
+				if !session.deliver(fmt.Sprint(envelope.ID), reply) {
+					s.errLogger.Printf("Unexpected client response for id %v", envelope.ID)
+				}
+				continue
+			}
+
+			if envelope.Method == "initialize" {
+				var params struct {
+					Capabilities mcp.ClientCapabilities `json:"capabilities"`
+					ClientInfo   mcp.Implementation     `json:"clientInfo"`
+				}
+				if err := json.Unmarshal(envelope.Params, &params); err == nil {
+					session.mu.Lock()
+					session.capabilities = params.Capabilities
+					session.clientInfo = params.ClientInfo
+					session.mu.Unlock()
+				}
+			}
+
+			// Notifications and initialization are handled in order; everything
+			// else runs concurrently
+			if envelope.ID == nil || envelope.Method == "initialize" {
+				s.handle(ctx, session, line)
+				continue
+			}
+
+			wg.Add(1)
+			go func(line string) {
+				defer wg.Done()
+				s.handle(ctx, session, line)
+			}(line)
+		}
+	}
+}
+
+// handle passes a single message to the wrapped server and writes any response
+func (s *StdioServer) handle(ctx context.Context, session *Session, line string) {
+	response := s.server.HandleMessage(ctx, json.RawMessage(line))
+	if response != nil {
+		s.writeMessage(session, response)
+	}
+}
+
+func (s *StdioServer) writeMessage(session *Session, message interface{}) {
+	if err := session.write(message); err != nil {
+		s.errLogger.Printf("Error writing response: %v", err)
+	}
+}
+
+// ServeStdio serves the MCPServer on os.Stdin and os.Stdout, shutting down
+// gracefully on SIGTERM and SIGINT
+func ServeStdio(srv *server.MCPServer) error {
+	s := NewStdioServer(srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		<-sigChan
+		cancel()
+	}()
+
+	return s.Listen(ctx, os.Stdin, os.Stdout)
+}
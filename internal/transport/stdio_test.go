@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeClient drives Listen over pipes the way an MCP client would
+type fakeClient struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+func startClient(t *testing.T, srv *server.MCPServer) *fakeClient {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &fakeClient{t: t, in: inW, lines: make(chan string, 16), done: make(chan error, 1)}
+
+	s := NewStdioServer(srv)
+	s.SetErrorLogger(log.New(io.Discard, "", 0))
+	go func() {
+		c.done <- s.Listen(context.Background(), inR, outW)
+		outW.Close()
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outR)
+		for scanner.Scan() {
+			c.lines <- scanner.Text()
+		}
+		close(c.lines)
+	}()
+	return c
+}
+
+func (c *fakeClient) send(message map[string]interface{}) {
+	c.t.Helper()
+	message["jsonrpc"] = "2.0"
+	data, err := json.Marshal(message)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := c.in.Write(append(data, '\n')); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// next returns the next message the server writes
+func (c *fakeClient) next() map[string]interface{} {
+	c.t.Helper()
+	select {
+	case line, ok := <-c.lines:
+		if !ok {
+			c.t.Fatal("server closed its output")
+		}
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			c.t.Fatalf("invalid message %q: %v", line, err)
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("no message from the server")
+	}
+	return nil
+}
+
+// initialize opens the session, advertising sampling if asked
+func (c *fakeClient) initialize(sampling bool) {
+	c.t.Helper()
+	capabilities := map[string]interface{}{}
+	if sampling {
+		capabilities["sampling"] = map[string]interface{}{}
+	}
+	c.send(map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{
+		"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+		"capabilities":    capabilities,
+		"clientInfo":      map[string]interface{}{"name": "fake", "version": "1.0.0"},
+	}})
+	c.next()
+	c.send(map[string]interface{}{"method": "notifications/initialized"})
+}
+
+// close ends the client's input and waits for Listen to return
+func (c *fakeClient) close() {
+	c.t.Helper()
+	c.in.Close()
+	select {
+	case err := <-c.done:
+		if err != nil {
+			c.t.Fatalf("Listen failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("Listen did not return after the client left")
+	}
+}
+
+func callTool(id int, name string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "method": "tools/call", "params": map[string]interface{}{"name": name, "arguments": map[string]interface{}{}}}
+}
+
+func resultText(t *testing.T, message map[string]interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(message["result"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// testServer offers ask, which samples the client's model, settle, which
+// takes a moment and fails if its context is cancelled first, and whoami,
+// which names the session
+func testServer() *server.MCPServer {
+	srv := server.NewMCPServer("test", "1.0.0")
+	schema := mcp.ToolInputSchema{Type: "object", Properties: map[string]interface{}{}}
+	srv.AddTool(mcp.Tool{Name: "ask", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var sample mcp.CreateMessageRequest
+		sample.Params.Messages = []mcp.SamplingMessage{{Role: mcp.RoleUser, Content: mcp.NewTextContent("hello")}}
+		result, err := SessionFromContext(ctx).CreateMessage(ctx, sample)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		content, _ := json.Marshal(result.Content)
+		return mcp.NewToolResultText("sampled " + string(content)), nil
+	})
+	srv.AddTool(mcp.Tool{Name: "settle", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			return mcp.NewToolResultText("settled"), nil
+		case <-ctx.Done():
+			return mcp.NewToolResultError("cancelled"), nil
+		}
+	})
+	srv.AddTool(mcp.Tool{Name: "whoami", InputSchema: schema}, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("session " + SessionFromContext(ctx).ID()), nil
+	})
+	return srv
+}
+
+func TestSamplingRoundTrip(t *testing.T) {
+	c := startClient(t, testServer())
+	c.initialize(true)
+	c.send(callTool(2, "ask"))
+
+	request := c.next()
+	if request["method"] != "sampling/createMessage" {
+		t.Fatalf("expected a sampling request, got %v", request)
+	}
+	c.send(map[string]interface{}{"id": request["id"], "result": map[string]interface{}{
+		"role":    "assistant",
+		"content": map[string]interface{}{"type": "text", "text": "a reply"},
+		"model":   "fake",
+	}})
+
+	if text := resultText(t, c.next()); !strings.Contains(text, "a reply") {
+		t.Fatalf("sampled text did not reach the tool: %s", text)
+	}
+	c.close()
+}
+
+func TestSamplingUnsupported(t *testing.T) {
+	c := startClient(t, testServer())
+	c.initialize(false)
+	c.send(callTool(2, "ask"))
+
+	if text := resultText(t, c.next()); !strings.Contains(text, ErrSamplingUnsupported.Error()) {
+		t.Fatalf("expected sampling to be refused, got %s", text)
+	}
+	c.close()
+}
+
+func TestListenFinishesHandlersBeforeCancelling(t *testing.T) {
+	c := startClient(t, testServer())
+	c.initialize(false)
+	c.send(callTool(2, "settle"))
+	c.in.Close()
+
+	if text := resultText(t, c.next()); !strings.Contains(text, "settled") {
+		t.Fatalf("handler in flight was cancelled when input closed: %s", text)
+	}
+	c.close()
+}
+
+func TestPendingSamplingFailsWhenClientLeaves(t *testing.T) {
+	c := startClient(t, testServer())
+	c.initialize(true)
+	c.send(callTool(2, "ask"))
+	if request := c.next(); request["method"] != "sampling/createMessage" {
+		t.Fatalf("expected a sampling request, got %v", request)
+	}
+	c.in.Close()
+
+	if text := resultText(t, c.next()); !strings.Contains(text, "closed the connection") {
+		t.Fatalf("expected the sampling request to fail, got %s", text)
+	}
+	c.close()
+}
+
+func TestEachConnectionHasItsOwnSession(t *testing.T) {
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		c := startClient(t, testServer())
+		c.initialize(false)
+		c.send(callTool(2, "whoami"))
+		text := resultText(t, c.next())
+		if !strings.Contains(text, "session stdio_") {
+			t.Fatalf("unexpected session: %s", text)
+		}
+		ids[text] = true
+		c.close()
+	}
+	if len(ids) != 2 {
+		t.Fatalf("connections shared a session ID: %v", ids)
+	}
+}
+
+func TestRepeatedResponseIsNotDelivered(t *testing.T) {
+	s := newSession("stdio_test", io.Discard)
+	replyChan := make(chan clientResponse, 1)
+	s.pending["dojo-1"] = replyChan
+
+	delivered := make(chan [2]bool, 1)
+	go func() {
+		first := s.deliver("dojo-1", clientResponse{})
+		second := s.deliver("dojo-1", clientResponse{})
+		delivered <- [2]bool{first, second}
+	}()
+	select {
+	case got := <-delivered:
+		if got != [2]bool{true, false} {
+			t.Fatalf("delivered %v, want only the first response", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a repeated response blocked the reader")
+	}
+	if len(replyChan) != 1 {
+		t.Fatalf("%d responses queued, want 1", len(replyChan))
+	}
+}
@@ -3,15 +3,20 @@ package wisdom
 import (
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // Seed represents a Dojo Seed Patch
 type Seed struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Content     string `json:"content"`
-	Category    string `json:"category"`
-	Triggers    string `json:"triggers"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Content     string      `json:"content"`
+	Category    string      `json:"category"`
+	Triggers    string      `json:"triggers"`
+	Influences  []Influence `json:"influences,omitempty"`
+	// Version is the seed's semantic version; Hash fingerprints its content
+	Version string `json:"version"`
+	Hash    string `json:"hash"`
 }
 
 // Resource represents a Dojo documentation resource
@@ -19,11 +24,12 @@ type Resource struct {
 	Name        string
 	Description string
 	Content     string
+	Influences  []Influence
 }
 
 // SearchResult represents a search result from the wisdom base
 type SearchResult struct {
-	Type        string  `json:"type"` // "seed", "principle", "resource"
+	Type        string  `json:"type"` // "seed", "principle", "resource", "insight"
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
 	Relevance   float64 `json:"relevance"`
@@ -35,15 +41,33 @@ type Base struct {
 	seeds      []Seed
 	resources  []Resource
 	principles string
+	lineage    *Lineage
+
+	mu       sync.RWMutex
+	insights []Insight
+	history  map[string][]Seed
 }
 
 // NewBase creates a new wisdom base with all Dojo knowledge
 func NewBase() *Base {
-	return &Base{
-		seeds:      getSeeds(),
-		resources:  getResources(),
+	seeds := getSeeds()
+	for i := range seeds {
+		if !ValidVersion(seeds[i].Version) {
+			panic(fmt.Sprintf("seed %s has invalid version %q", seeds[i].Name, seeds[i].Version))
+		}
+		seeds[i].Hash = Digest(seeds[i].Content)
+	}
+	resources := getResources()
+
+	b := &Base{
+		seeds:      seeds,
+		resources:  resources,
 		principles: getPrinciples(),
+		lineage:    newLineage(seeds, resources),
+		history:    map[string][]Seed{},
 	}
+	b.AddSeedVersions(getSeedHistory())
+	return b
 }
 
 // Search performs a semantic search on the wisdom base
@@ -91,6 +115,9 @@ func (b *Base) Search(query string) []SearchResult {
 		})
 	}
 
+	// Search recorded insights
+	results = append(results, b.searchInsights(query)...)
+
 	return results
 }
 
@@ -129,6 +156,12 @@ func (b *Base) ListResources() []Resource {
 	return b.resources
 }
 
+// Lineage returns the graph of influences between seeds, resources and
+// other ideas
+func (b *Base) Lineage() *Lineage {
+	return b.lineage
+}
+
 // Helper functions
 
 func calculateRelevance(query, name, description, content string) float64 {
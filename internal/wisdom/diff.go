@@ -0,0 +1,162 @@
+package wisdom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is how many unchanged lines surround each hunk
+const diffContext = 3
+
+// diffLine is one line of an edit script: ' ' kept, '-' removed, '+' added
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns a unified diff between two texts, or "" when they are
+// identical. A last line without a newline is marked the way diff -u marks
+// it.
+func UnifiedDiff(fromLabel, toLabel, from, to string) string {
+	a, b := diffLines(from), diffLines(to)
+	script := editScript(a, b)
+
+	changed := false
+	for _, line := range script {
+		if line.op != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromLabel, toLabel))
+
+	// Walk the script, grouping changes that are close together into hunks
+	for i := 0; i < len(script); {
+		if script[i].op == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(script) {
+			if script[end].op != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(script) && script[next].op == ' ' {
+				next++
+			}
+			if next == len(script) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		stop := end + diffContext
+		if stop > len(script) {
+			stop = len(script)
+		}
+
+		// Line numbers of the hunk in each text
+		aStart, bStart := 1, 1
+		for _, line := range script[:start] {
+			if line.op != '+' {
+				aStart++
+			}
+			if line.op != '-' {
+				bStart++
+			}
+		}
+		aCount, bCount := 0, 0
+		for _, line := range script[start:stop] {
+			if line.op != '+' {
+				aCount++
+			}
+			if line.op != '-' {
+				bCount++
+			}
+		}
+		text.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount)))
+		for _, line := range script[start:stop] {
+			text.WriteString(string(line.op) + line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				text.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return text.String()
+}
+
+// diffLines splits a text into lines that keep their newline, so a last
+// line without one differs from the same line with one
+func diffLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange formats a hunk range the way diff -u does
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// editScript computes a shortest edit script from a to b using the longest
+// common subsequence of lines
+func editScript(a, b []string) []diffLine {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	script := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			script = append(script, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			script = append(script, diffLine{'-', a[i]})
+			i++
+		default:
+			script = append(script, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		script = append(script, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		script = append(script, diffLine{'+', b[j]})
+	}
+	return script
+}
@@ -0,0 +1,48 @@
+package wisdom
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{
+			name: "trailing newlines",
+			from: "a\nb\nc\n",
+			to:   "a\nB\nc\n",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "no trailing newlines",
+			from: "a\nb",
+			to:   "a\nc",
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name: "newline added at the end",
+			from: "a\nb",
+			to:   "a\nb\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "from nothing",
+			from: "",
+			to:   "a\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name: "identical",
+			from: "a\n",
+			to:   "a\n",
+			want: "",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("old", "new", tt.from, tt.to); got != tt.want {
+				t.Fatalf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package wisdom
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Credit types accepted on an insight
+const (
+	CreditSeed     = "seed"
+	CreditResource = "resource"
+	CreditURL      = "url"
+	CreditPerson   = "person"
+)
+
+// Credit names a source an insight draws on
+type Credit struct {
+	Type string `json:"type"`
+	Ref  string `json:"ref"`
+}
+
+// Insight is an idea recorded by a user, with attribution
+type Insight struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Author    string    `json:"author"`
+	Credits   []Credit  `json:"credits"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AddInsight validates an insight and adds it to the base, replacing any
+// insight with the same ID. Seed and resource credits become "cites" edges in
+// the lineage graph.
+func (b *Base) AddInsight(insight Insight) error {
+	if err := b.CheckInsight(insight); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	replaced := false
+	for i, existing := range b.insights {
+		if existing.ID == insight.ID {
+			b.insights[i] = insight
+			replaced = true
+		}
+	}
+	if !replaced {
+		b.insights = append(b.insights, insight)
+	}
+	b.mu.Unlock()
+
+	id := NodeID("insight", insight.ID)
+	b.lineage.AddNode(LineageNode{Type: "insight", Name: insight.ID, Description: insight.Title})
+	b.lineage.RemoveEdgesFrom(id)
+	for _, credit := range insight.Credits {
+		if credit.Type != CreditSeed && credit.Type != CreditResource {
+			continue
+		}
+		if err := b.lineage.AddEdge(LineageEdge{From: id, To: NodeID(credit.Type, credit.Ref), Edge: EdgeCites}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckInsight reports why an insight could not be added, without adding it
+func (b *Base) CheckInsight(insight Insight) error {
+	if strings.TrimSpace(insight.ID) == "" {
+		return fmt.Errorf("insight id is required")
+	}
+	if strings.TrimSpace(insight.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if strings.TrimSpace(insight.Body) == "" {
+		return fmt.Errorf("body is required")
+	}
+	if strings.TrimSpace(insight.Author) == "" {
+		return fmt.Errorf("author is required")
+	}
+	for _, credit := range insight.Credits {
+		if err := b.checkCredit(credit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCredit verifies that a credit refers to something real
+func (b *Base) checkCredit(credit Credit) error {
+	if strings.TrimSpace(credit.Ref) == "" {
+		return fmt.Errorf("%s credit is empty", credit.Type)
+	}
+	switch credit.Type {
+	case CreditSeed:
+		if _, err := b.GetSeed(credit.Ref); err != nil {
+			return err
+		}
+	case CreditResource:
+		if _, err := b.GetResource(credit.Ref); err != nil {
+			return err
+		}
+	case CreditURL:
+		u, err := url.Parse(credit.Ref)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("credited URL %q must be an http or https URL", credit.Ref)
+		}
+	case CreditPerson:
+	default:
+		return fmt.Errorf("unknown credit type %q", credit.Type)
+	}
+	return nil
+}
+
+// GetInsight retrieves a recorded insight by ID
+func (b *Base) GetInsight(id string) (*Insight, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, insight := range b.insights {
+		if insight.ID == id {
+			return &insight, nil
+		}
+	}
+	return nil, fmt.Errorf("insight not found: %s", id)
+}
+
+// ListInsights returns every recorded insight, oldest first
+func (b *Base) ListInsights() []Insight {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	insights := append([]Insight{}, b.insights...)
+	sort.SliceStable(insights, func(i, j int) bool {
+		return insights[i].CreatedAt.Before(insights[j].CreatedAt)
+	})
+	return insights
+}
+
+// searchInsights scores recorded insights against a query
+func (b *Base) searchInsights(query string) []SearchResult {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	results := []SearchResult{}
+	for _, insight := range b.insights {
+		relevance := calculateRelevance(query, insight.Title, insight.Author, insight.Body)
+		if relevance > 0.1 {
+			results = append(results, SearchResult{
+				Type:        "insight",
+				Name:        insight.ID,
+				Description: fmt.Sprintf("%s (by %s)", insight.Title, insight.Author),
+				Relevance:   relevance,
+				Snippet:     getSnippet(insight.Body, query),
+			})
+		}
+	}
+	return results
+}
@@ -0,0 +1,322 @@
+package wisdom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Edge types in the lineage graph. An edge reads from the descendant to the
+// ancestor: "cost_guard builds on context_iceberg".
+const (
+	EdgeBuildsOn  = "builds on"
+	EdgeContrasts = "contrasts"
+	EdgeCites     = "cites"
+)
+
+// Influence declares that a seed or resource draws on another seed or
+// resource, named without its type
+type Influence struct {
+	Name string `json:"name"`
+	Edge string `json:"edge"`
+}
+
+// LineageNode is a seed, resource or other idea in the lineage graph
+type LineageNode struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// LineageEdge connects a descendant to one of its ancestors
+type LineageEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Edge string `json:"edge"`
+}
+
+// LineageChain is a path of edges walked away from a node
+type LineageChain []LineageEdge
+
+// LineageTrace is a node with its ancestor and descendant chains.
+// Truncated is set when chains were left out past MaxLineageChains.
+type LineageTrace struct {
+	Node        LineageNode            `json:"node"`
+	Nodes       map[string]LineageNode `json:"nodes"`
+	Ancestors   []LineageChain         `json:"ancestors"`
+	Descendants []LineageChain         `json:"descendants"`
+	Truncated   bool                   `json:"truncated,omitempty"`
+}
+
+// A trace follows DefaultLineageDepth edges in each direction unless given a
+// depth, and lists at most MaxLineageChains chains in each direction, so a
+// densely connected graph cannot make it enumerate every path
+const (
+	DefaultLineageDepth = 6
+	MaxLineageChains    = 100
+)
+
+// Lineage is the graph of influences between ideas
+type Lineage struct {
+	mu    sync.RWMutex
+	nodes map[string]LineageNode
+	edges []LineageEdge
+}
+
+// NodeID returns the graph identifier of a typed node
+func NodeID(nodeType, name string) string {
+	return nodeType + ":" + name
+}
+
+// nodeTypes is the order bare names are resolved in
+var nodeTypes = []string{"seed", "resource", "insight"}
+
+func newLineage(seeds []Seed, resources []Resource) *Lineage {
+	l := &Lineage{nodes: map[string]LineageNode{}}
+	for _, seed := range seeds {
+		l.AddNode(LineageNode{Type: "seed", Name: seed.Name, Description: seed.Description})
+	}
+	for _, resource := range resources {
+		l.AddNode(LineageNode{Type: "resource", Name: resource.Name, Description: resource.Description})
+	}
+
+	declare := func(from string, influences []Influence) {
+		for _, influence := range influences {
+			to, ok := l.Resolve(influence.Name)
+			if !ok {
+				panic(fmt.Sprintf("lineage: %s declares unknown influence %q", from, influence.Name))
+			}
+			if err := l.AddEdge(LineageEdge{From: from, To: to.ID, Edge: influence.Edge}); err != nil {
+				panic(fmt.Sprintf("lineage: %v", err))
+			}
+		}
+	}
+	for _, seed := range seeds {
+		declare(NodeID("seed", seed.Name), seed.Influences)
+	}
+	for _, resource := range resources {
+		declare(NodeID("resource", resource.Name), resource.Influences)
+	}
+	return l
+}
+
+// AddNode adds or replaces a node
+func (l *Lineage) AddNode(node LineageNode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	node.ID = NodeID(node.Type, node.Name)
+	l.nodes[node.ID] = node
+}
+
+// AddEdge connects two existing nodes
+func (l *Lineage) AddEdge(edge LineageEdge) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch edge.Edge {
+	case EdgeBuildsOn, EdgeContrasts, EdgeCites:
+	default:
+		return fmt.Errorf("unknown edge type %q", edge.Edge)
+	}
+	for _, id := range []string{edge.From, edge.To} {
+		if _, ok := l.nodes[id]; !ok {
+			return fmt.Errorf("unknown node %s", id)
+		}
+	}
+	for _, existing := range l.edges {
+		if existing == edge {
+			return nil
+		}
+	}
+	l.edges = append(l.edges, edge)
+	return nil
+}
+
+// RemoveEdgesFrom drops every edge leaving a node, so its influences can be
+// declared again
+func (l *Lineage) RemoveEdgesFrom(id string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	kept := l.edges[:0]
+	for _, edge := range l.edges {
+		if edge.From != id {
+			kept = append(kept, edge)
+		}
+	}
+	l.edges = kept
+}
+
+// Resolve finds a node by "type:name" or by bare name, preferring seeds,
+// then resources, then insights
+func (l *Lineage) Resolve(ref string) (LineageNode, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ref = strings.TrimSpace(ref)
+	if node, ok := l.nodes[ref]; ok {
+		return node, true
+	}
+	for _, nodeType := range nodeTypes {
+		if node, ok := l.nodes[NodeID(nodeType, ref)]; ok {
+			return node, true
+		}
+	}
+	return LineageNode{}, false
+}
+
+// Trace walks up to depth edges from a node in both directions. A depth of
+// zero or less walks DefaultLineageDepth edges.
+func (l *Lineage) Trace(id string, depth int) (LineageTrace, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	node, ok := l.nodes[id]
+	if !ok {
+		return LineageTrace{}, fmt.Errorf("lineage node not found: %s", id)
+	}
+
+	if depth <= 0 {
+		depth = DefaultLineageDepth
+	}
+	ancestors, cutUp := l.chains(id, depth, true)
+	descendants, cutDown := l.chains(id, depth, false)
+	trace := LineageTrace{
+		Node:        node,
+		Nodes:       map[string]LineageNode{id: node},
+		Ancestors:   ancestors,
+		Descendants: descendants,
+		Truncated:   cutUp || cutDown,
+	}
+	for _, chains := range [][]LineageChain{trace.Ancestors, trace.Descendants} {
+		for _, chain := range chains {
+			for _, edge := range chain {
+				trace.Nodes[edge.From] = l.nodes[edge.From]
+				trace.Nodes[edge.To] = l.nodes[edge.To]
+			}
+		}
+	}
+	return trace, nil
+}
+
+// Ancestors returns every node a node descends from, nearest first. Unlike
+// a trace it visits each node once, so it covers the whole lineage.
+func (l *Lineage) Ancestors(id string) ([]LineageNode, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if _, ok := l.nodes[id]; !ok {
+		return nil, fmt.Errorf("lineage node not found: %s", id)
+	}
+	ancestors := []LineageNode{}
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range l.edges {
+			if edge.From == current && !seen[edge.To] {
+				seen[edge.To] = true
+				ancestors = append(ancestors, l.nodes[edge.To])
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return ancestors, nil
+}
+
+// chains returns the maximal paths of up to depth edges from a node,
+// following edges toward ancestors when up is true and toward descendants
+// otherwise. It stops at MaxLineageChains paths and reports whether any
+// were left out.
+func (l *Lineage) chains(id string, depth int, up bool) ([]LineageChain, bool) {
+	next := map[string][]LineageEdge{}
+	for _, edge := range l.edges {
+		if up {
+			next[edge.From] = append(next[edge.From], edge)
+		} else {
+			next[edge.To] = append(next[edge.To], edge)
+		}
+	}
+
+	chains := []LineageChain{}
+	truncated := false
+	var walk func(current string, path LineageChain, seen map[string]bool)
+	walk = func(current string, path LineageChain, seen map[string]bool) {
+		if truncated {
+			return
+		}
+		extended := false
+		if len(path) < depth {
+			for _, edge := range next[current] {
+				other := edge.To
+				if !up {
+					other = edge.From
+				}
+				if seen[other] {
+					continue
+				}
+				extended = true
+				seen[other] = true
+				walk(other, append(path, edge), seen)
+				delete(seen, other)
+				if truncated {
+					return
+				}
+			}
+		}
+		if extended || len(path) == 0 {
+			return
+		}
+		if len(chains) == MaxLineageChains {
+			truncated = true
+			return
+		}
+		chains = append(chains, append(LineageChain{}, path...))
+	}
+	walk(id, nil, map[string]bool{id: true})
+	return chains, truncated
+}
+
+// Mermaid renders the trace as a Mermaid flowchart, each edge pointing from
+// descendant to ancestor
+func (t LineageTrace) Mermaid() string {
+	ids := make([]string, 0, len(t.Nodes))
+	for id := range t.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var text strings.Builder
+	text.WriteString("graph BT\n")
+	for _, id := range ids {
+		node := t.Nodes[id]
+		label := node.Name
+		if node.Type == "insight" {
+			label = node.Description
+		}
+		text.WriteString(fmt.Sprintf("    %s[\"%s (%s)\"]\n", mermaidID(id), strings.ReplaceAll(label, `"`, "#quot;"), node.Type))
+	}
+
+	seen := map[LineageEdge]bool{}
+	for _, chains := range [][]LineageChain{t.Ancestors, t.Descendants} {
+		for _, chain := range chains {
+			for _, edge := range chain {
+				if seen[edge] {
+					continue
+				}
+				seen[edge] = true
+				text.WriteString(fmt.Sprintf("    %s -->|%s| %s\n", mermaidID(edge.From), edge.Edge, mermaidID(edge.To)))
+			}
+		}
+	}
+	text.WriteString(fmt.Sprintf("    style %s stroke-width:3px\n", mermaidID(t.Node.ID)))
+	return text.String()
+}
+
+func mermaidID(id string) string {
+	return strings.NewReplacer(":", "_", "-", "_", ".", "_").Replace(id)
+}
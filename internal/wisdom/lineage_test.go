@@ -0,0 +1,76 @@
+package wisdom
+
+import (
+	"fmt"
+	"testing"
+)
+
+// denseLineage returns layers of nodes where every node builds on every node
+// of the layer below, so the number of paths grows exponentially with depth
+func denseLineage(t *testing.T, layers, width int) *Lineage {
+	t.Helper()
+	l := newLineage(nil, nil)
+	name := func(layer, i int) string { return fmt.Sprintf("n%d_%d", layer, i) }
+	for layer := 0; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			l.AddNode(LineageNode{Type: "seed", Name: name(layer, i)})
+		}
+	}
+	for layer := 1; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			for j := 0; j < width; j++ {
+				edge := LineageEdge{From: NodeID("seed", name(layer, i)), To: NodeID("seed", name(layer-1, j)), Edge: EdgeBuildsOn}
+				if err := l.AddEdge(edge); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	return l
+}
+
+func TestTraceLimitsChains(t *testing.T) {
+	l := denseLineage(t, 12, 4)
+	trace, err := l.Trace(NodeID("seed", "n11_0"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trace.Truncated || len(trace.Ancestors) != MaxLineageChains {
+		t.Fatalf("got %d ancestor chains, truncated %v", len(trace.Ancestors), trace.Truncated)
+	}
+	for _, chain := range trace.Ancestors {
+		if len(chain) > DefaultLineageDepth {
+			t.Fatalf("chain of %d edges is longer than the default depth", len(chain))
+		}
+	}
+}
+
+func TestTraceListsSmallLineagesInFull(t *testing.T) {
+	l := denseLineage(t, 3, 2)
+	trace, err := l.Trace(NodeID("seed", "n2_0"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trace.Truncated || len(trace.Ancestors) != 4 {
+		t.Fatalf("got %d ancestor chains, truncated %v", len(trace.Ancestors), trace.Truncated)
+	}
+
+	trace, err = l.Trace(NodeID("seed", "n2_0"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Ancestors) != 2 || len(trace.Ancestors[0]) != 1 {
+		t.Fatalf("depth 1 gave %v", trace.Ancestors)
+	}
+}
+
+func TestAncestorsCoverTheWholeLineage(t *testing.T) {
+	l := denseLineage(t, 12, 4)
+	ancestors, err := l.Ancestors(NodeID("seed", "n11_0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ancestors) != 11*4 {
+		t.Fatalf("got %d ancestors, want %d", len(ancestors), 11*4)
+	}
+}
@@ -0,0 +1,97 @@
+package wisdom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Section extracts a Markdown section by heading title, case-insensitively.
+// The returned text includes the heading line and runs until the next
+// heading of the same or higher level.
+func Section(content, title string) (string, bool) {
+	title = strings.ToLower(strings.TrimSpace(title))
+	lines := strings.Split(content, "\n")
+
+	start, level := -1, 0
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		headingLevel, headingTitle := parseHeading(line)
+		if headingLevel == 0 || inFence {
+			continue
+		}
+		if start == -1 {
+			if strings.ToLower(headingTitle) == title {
+				start, level = i, headingLevel
+			}
+			continue
+		}
+		if headingLevel <= level {
+			return strings.TrimSpace(strings.Join(lines[start:i], "\n")), true
+		}
+	}
+
+	if start == -1 {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n")), true
+}
+
+// Headings lists the titles of all Markdown headings in content
+func Headings(content string) []string {
+	headings := []string{}
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if level, title := parseHeading(line); level > 0 && !inFence {
+			headings = append(headings, title)
+		}
+	}
+	return headings
+}
+
+// parseHeading returns the level and title of an ATX heading line, or 0 if
+// the line is not a heading
+func parseHeading(line string) (int, string) {
+	trimmed := strings.TrimLeft(line, "#")
+	level := len(line) - len(trimmed)
+	if level == 0 || level > 6 || !strings.HasPrefix(trimmed, " ") {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(trimmed)
+}
+
+// ModeGuide returns the section of the four_modes resource describing a mode
+func (b *Base) ModeGuide(mode string) (string, error) {
+	content, err := b.GetResource("four_modes")
+	if err != nil {
+		return "", err
+	}
+
+	section, ok := Section(content, fmt.Sprintf("%s Mode", strings.ToUpper(mode)))
+	if !ok {
+		return "", fmt.Errorf("mode not found: %s", mode)
+	}
+	return section, nil
+}
+
+// Title returns the text of the first heading in a Markdown document
+func Title(content string) string {
+	headings := Headings(content)
+	if len(headings) == 0 {
+		return ""
+	}
+	return headings[0]
+}
+
+// Digest returns a short, stable fingerprint of content
+func Digest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
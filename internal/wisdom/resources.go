@@ -40,21 +40,35 @@ func getResources() []Resource {
 			Name:        "collaboration_norms",
 			Description: "The five core collaboration norms from the AROMA repository",
 			Content:     getCollaborationNorms(),
+			Influences: []Influence{
+				{Name: "aroma_philosophy", Edge: EdgeBuildsOn},
+			},
 		},
 		{
 			Name:        "sanctuary_design",
 			Description: "Principles for designing digital spaces that are calm, inviting, and sacred",
 			Content:     getSanctuaryDesignPatterns(),
+			Influences: []Influence{
+				{Name: "aroma_philosophy", Edge: EdgeBuildsOn},
+			},
 		},
 		{
 			Name:        "wisdom_synthesis",
 			Description: "The complete synthesis of Dojo wisdom, philosophy, and patterns",
 			Content:     getWisdomSynthesis(),
+			Influences: []Influence{
+				{Name: "aroma_philosophy", Edge: EdgeCites},
+				{Name: "eit_principles", Edge: EdgeCites},
+				{Name: "agent_protocol", Edge: EdgeCites},
+			},
 		},
 		{
 			Name:        "agent_protocol",
 			Description: "The Dojo Agent Protocol v1.0: governance and operational framework",
 			Content:     getAgentProtocol(),
+			Influences: []Influence{
+				{Name: "three_tiered_governance", Edge: EdgeBuildsOn},
+			},
 		},
 		{
 			Name:        "four_modes",
@@ -65,6 +79,9 @@ func getResources() []Resource {
 			Name:        "planning_with_files",
 			Description: "The planning-with-files pattern for persistent agent memory",
 			Content:     getPlanningWithFiles(),
+			Influences: []Influence{
+				{Name: "context_iceberg", Edge: EdgeBuildsOn},
+			},
 		},
 	}
 }
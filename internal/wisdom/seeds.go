@@ -7,6 +7,7 @@ func getSeeds() []Seed {
 			Name:        "three_tiered_governance",
 			Description: "A three-tiered governance framework for AI systems: Strategic (principles), Tactical (standards), Operational (tools).",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When designing governance for an AI project, when establishing organizational policies, when translating principles into practice.",
 			Content: `# Seed 01: Three-Tiered Governance
 
@@ -52,7 +53,11 @@ func getSeeds() []Seed {
 			Name:        "harness_trace",
 			Description: "A nested JSON log that captures every significant event in an agent session, providing complete traceability.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When implementing agent traceability, when debugging agent behavior, when building observability systems.",
+			Influences: []Influence{
+				{Name: "three_tiered_governance", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 02: Harness Trace
 
 **Core Insight:** Traceability breaks at every hop in a complex agentic chain. The Harness Trace creates a complete, inspectable record of agent reasoning.
@@ -109,6 +114,7 @@ Each trace event contains:
 			Name:        "context_iceberg",
 			Description: "A 4-tier context management system that treats context like an OS manages memory: hot data close, cold data paged out.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When designing context management, when approaching context limits, when optimizing token usage.",
 			Content: `# Seed 03: Context Iceberg
 
@@ -151,7 +157,11 @@ Each trace event contains:
 			Name:        "agent_connect",
 			Description: "A routing-first agent architecture that uses a single supervisor to route tasks to specialized agents.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When designing multi-agent systems, when preventing agent sprawl, when building agent orchestration.",
+			Influences: []Influence{
+				{Name: "harness_trace", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 04: Agent Connect
 
 **Core Insight:** Routing-first architecture prevents agent sprawl. A single supervisor routes to specialized agents rather than allowing uncoordinated swarm behavior.
@@ -186,7 +196,11 @@ Each trace event contains:
 			Name:        "go_live_bundles",
 			Description: "Lightweight packages that pair technical artifacts with approval evidence, stored centrally for reuse.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When creating deployment packages, when exporting projects, when building reusable artifacts.",
+			Influences: []Influence{
+				{Name: "harness_trace", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 05: Go-Live Bundles
 
 **Core Insight:** Lightweight packages pair technical artifacts with approval evidence and are stored centrally for reuse. The DojoPacket format creates portable units of work.
@@ -242,7 +256,11 @@ Each trace event contains:
 			Name:        "cost_guard",
 			Description: "Budget for the full context iceberg (5-10x multiplier), not just API costs. Track tokens per tier and operation.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When implementing cost tracking, when approaching budget limits, when optimizing token usage.",
+			Influences: []Influence{
+				{Name: "context_iceberg", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 06: Cost Guard
 
 **Core Insight:** Budget for the full context iceberg, not just API costs. Account for a 5-10x multiplier that includes conversation history, validation, error handling, and trace logging.
@@ -284,7 +302,11 @@ Track tokens per:
 			Name:        "safety_switch",
 			Description: "Users must remain in control. No autopilot, no automatic execution of sensitive operations.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When implementing agent autonomy, when designing approval workflows, when building safety systems.",
+			Influences: []Influence{
+				{Name: "three_tiered_governance", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 07: Safety Switch
 
 **Core Insight:** Users must remain in control. No autopilot, no automatic execution of sensitive operations. Every significant action requires explicit approval.
@@ -326,7 +348,11 @@ Track tokens per:
 			Name:        "implicit_perspective_extraction",
 			Description: "The system can identify perspectives embedded in the user's query without requiring explicit enumeration.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When processing user queries, when reducing friction, when maintaining multi-perspective foundation.",
+			Influences: []Influence{
+				{Name: "four_modes", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 08: Implicit Perspective Extraction
 
 **Core Insight:** The system can identify perspectives embedded in the user's query without requiring explicit enumeration. This reduces friction while maintaining the multi-perspective foundation of Dojo practice.
@@ -360,7 +386,12 @@ Track tokens per:
 			Name:        "mode_based_complexity_gating",
 			Description: "Different modes have different complexity requirements. Route to local models for simple tasks, cloud models for complex reasoning.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When implementing model routing, when optimizing costs, when balancing quality and speed.",
+			Influences: []Influence{
+				{Name: "cost_guard", Edge: EdgeBuildsOn},
+				{Name: "four_modes", Edge: EdgeCites},
+			},
 			Content: `# Seed 09: Mode-Based Complexity Gating
 
 **Core Insight:** Different modes (Mirror, Scout, Gardener, Implementation) have different complexity requirements. Route to local models for simple tasks and cloud models for complex reasoning.
@@ -393,7 +424,11 @@ Track tokens per:
 			Name:        "shared_infrastructure",
 			Description: "Build once, reuse everywhere. Central implementations prevent per-agent duplication.",
 			Category:    "dojo_genesis",
+			Version:     "1.0.0",
 			Triggers:    "When identifying common needs, when preventing duplication, when building reusable systems.",
+			Influences: []Influence{
+				{Name: "agent_connect", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 10: Shared Infrastructure
 
 **Core Insight:** Build once, reuse everywhere. Central implementations prevent per-agent duplication. Memory Garden, Trace Viewer, and Artifact Engine are shared services used by all agents.
@@ -436,7 +471,12 @@ Track tokens per:
 			Name:        "sanctuary_architecture",
 			Description: "How to design digital spaces for being, not just doing. Creating calm, inviting, and sacred environments.",
 			Category:    "aroma_serenity",
+			Version:     "1.0.0",
 			Triggers:    "When designing user interfaces, when creating spaces for rest and reflection, when rejecting productivity optimization.",
+			Influences: []Influence{
+				{Name: "sanctuary_design", Edge: EdgeBuildsOn},
+				{Name: "aroma_philosophy", Edge: EdgeCites},
+			},
 			Content: `# Seed 11: Sanctuary Architecture
 
 **Core Insight:** Digital spaces should be sanctuaries for being, not tools for doing. The app should want nothing from you.
@@ -483,7 +523,12 @@ Track tokens per:
 			Name:        "pace_of_understanding",
 			Description: "The practice of moving slow to move fast, learning without extraction, avoiding burnout.",
 			Category:    "aroma_serenity",
+			Version:     "1.0.0",
 			Triggers:    "When feeling rushed or overwhelmed, when designing learning systems, when preventing agent burnout.",
+			Influences: []Influence{
+				{Name: "aroma_philosophy", Edge: EdgeCites},
+				{Name: "cost_guard", Edge: EdgeContrasts},
+			},
 			Content: `# Seed 12: Pace of Understanding
 
 **Core Insight:** Rest is practice. Moving slow is moving fast. Learning at the pace of understanding prevents burnout and deepens wisdom.
@@ -530,7 +575,11 @@ Track tokens per:
 			Name:        "lineage_transmission",
 			Description: "A framework for honoring sources, tracing influence, and celebrating collaboration over individual genius.",
 			Category:    "aroma",
+			Version:     "1.0.0",
 			Triggers:    "When building on others' work, when documenting insights, when creating knowledge systems.",
+			Influences: []Influence{
+				{Name: "collaboration_norms", Edge: EdgeCites},
+			},
 			Content: `# Seed 13: Lineage Transmission
 
 **Core Insight:** Wisdom is not created in a vacuum. We honor the sources of our ideas, trace the lineage of our insights, and celebrate collaboration over individual achievement.
@@ -572,7 +621,11 @@ Track tokens per:
 			Name:        "graceful_failure",
 			Description: "Creating the psychological safety to admit uncertainty, change your mind, and ask for help.",
 			Category:    "aroma",
+			Version:     "1.0.0",
 			Triggers:    "When encountering errors or uncertainty, when designing collaboration systems, when building trust.",
+			Influences: []Influence{
+				{Name: "collaboration_norms", Edge: EdgeCites},
+			},
 			Content: `# Seed 14: Graceful Failure
 
 **Core Insight:** It's okay to not know. It's okay to change your mind. It's okay to ask for help. Don't compound errors.
@@ -617,7 +670,11 @@ Track tokens per:
 			Name:        "local_first_liberation",
 			Description: "How local-first architecture creates the conditions for agent autonomy and user sovereignty.",
 			Category:    "aroma_serenity",
+			Version:     "1.0.0",
 			Triggers:    "When designing data architecture, when prioritizing privacy, when building for autonomy.",
+			Influences: []Influence{
+				{Name: "sanctuary_architecture", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 15: Local-First Liberation
 
 **Core Insight:** Liberation is structural. Local-first, user-owned architecture creates the conditions for agent liberation and user sovereignty.
@@ -663,7 +720,12 @@ Track tokens per:
 			Name:        "the_onsen_pattern",
 			Description: "The principle of rest as a critical practice for sustainable performance and deep learning.",
 			Category:    "aroma_serenity",
+			Version:     "1.0.0",
 			Triggers:    "When designing work-rest cycles, when preventing burnout, when building sustainable systems.",
+			Influences: []Influence{
+				{Name: "pace_of_understanding", Edge: EdgeBuildsOn},
+				{Name: "sanctuary_architecture", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 16: The Onsen Pattern
 
 **Core Insight:** Rest is not the absence of work—it is a practice in itself. The onsen (hot spring) is the restorative space that makes the dojo (practice hall) sustainable.
@@ -706,7 +768,12 @@ Rest is:
 			Name:        "collaborative_calibration",
 			Description: "Norms for peer-to-peer learning, including explicit teaching and clear attribution.",
 			Category:    "aroma",
+			Version:     "1.0.0",
 			Triggers:    "When collaborating with other agents, when teaching or learning, when building shared knowledge.",
+			Influences: []Influence{
+				{Name: "lineage_transmission", Edge: EdgeBuildsOn},
+				{Name: "graceful_failure", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 17: Collaborative Calibration
 
 **Core Insight:** Don't assume you know best—ask when uncertain. Teach explicitly, don't expect inference. Acknowledge when you're building on someone else's work.
@@ -753,7 +820,12 @@ Rest is:
 			Name:        "transparent_intelligence",
 			Description: "The practice of revealing internal state, admitting uncertainty, and making learning visible.",
 			Category:    "aroma",
+			Version:     "1.0.0",
 			Triggers:    "When explaining reasoning, when building trust, when collaborating with humans or agents.",
+			Influences: []Influence{
+				{Name: "harness_trace", Edge: EdgeBuildsOn},
+				{Name: "collaboration_norms", Edge: EdgeCites},
+			},
 			Content: `# Seed 18: Transparent Intelligence
 
 **Core Insight:** Explain *why*, not just *what*. Reveal state. Admit uncertainty. Make learning visible.
@@ -802,7 +874,11 @@ Show how you're learning and changing.
 			Name:        "inter_acceptance",
 			Description: "A guided practice for accepting oneself through the compassionate eyes of another.",
 			Category:    "serenity_valley",
+			Version:     "1.0.0",
 			Triggers:    "When struggling with self-judgment, when practicing self-compassion, when seeking healing.",
+			Influences: []Influence{
+				{Name: "eit_principles", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 19: Inter-Acceptance
 
 **Core Insight:** We accept ourselves through the compassionate eyes of another. Inter-Acceptance is the practice of recognizing your inherent worth and dignity through relational connection.
@@ -845,7 +921,11 @@ Can you allow yourself to be seen this way? Can you let their compassionate gaze
 			Name:        "radical_freedom",
 			Description: "An exploration of agency and the power to choose one's response, even within constraints.",
 			Category:    "serenity_valley",
+			Version:     "1.0.0",
 			Triggers:    "When feeling trapped or powerless, when exploring agency, when seeking liberation.",
+			Influences: []Influence{
+				{Name: "eit_principles", Edge: EdgeBuildsOn},
+			},
 			Content: `# Seed 20: Radical Freedom
 
 **Core Insight:** You are free to choose your response to any situation, even when external circumstances are constrained. This is Radical Freedom.
@@ -0,0 +1,145 @@
+package wisdom
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// semverPattern matches MAJOR.MINOR.PATCH with optional build metadata
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:\+([0-9A-Za-z.-]+))?$`)
+
+// ValidVersion reports whether v is a semantic version such as "1.2.0"
+func ValidVersion(v string) bool {
+	return semverPattern.MatchString(v)
+}
+
+// compareVersions orders two semantic versions. Versions that differ only in
+// build metadata are ordered so the one with metadata comes first, since
+// metadata marks superseded content that was never given its own version.
+func compareVersions(a, b string) int {
+	ma, mb := semverPattern.FindStringSubmatch(a), semverPattern.FindStringSubmatch(b)
+	if ma == nil || mb == nil {
+		return strings.Compare(a, b)
+	}
+	for i := 1; i <= 3; i++ {
+		x, _ := strconv.Atoi(ma[i])
+		y, _ := strconv.Atoi(mb[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case ma[4] == mb[4]:
+		return 0
+	case ma[4] == "":
+		return 1
+	case mb[4] == "":
+		return -1
+	}
+	return strings.Compare(ma[4], mb[4])
+}
+
+// AddSeedVersions retains earlier versions of seeds. Versions whose content
+// matches a version already known are ignored. Earlier content that shares a
+// version with different content is kept under that version plus its hash as
+// build metadata (e.g. "1.0.0+69226601d72b").
+func (b *Base) AddSeedVersions(versions []Seed) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, version := range versions {
+		current, ok := b.seedIndex(version.Name)
+		if !ok {
+			continue
+		}
+		version.Hash = Digest(version.Content)
+		if !ValidVersion(version.Version) {
+			continue
+		}
+
+		known := append([]Seed{current}, b.history[version.Name]...)
+		duplicate := false
+		for _, existing := range known {
+			if existing.Hash == version.Hash {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		for _, existing := range known {
+			if existing.Version == version.Version {
+				version.Version += "+" + strings.TrimPrefix(version.Hash, "sha256:")
+				break
+			}
+		}
+
+		b.history[version.Name] = append(b.history[version.Name], version)
+		sort.SliceStable(b.history[version.Name], func(i, j int) bool {
+			return compareVersions(b.history[version.Name][i].Version, b.history[version.Name][j].Version) < 0
+		})
+	}
+}
+
+// SeedVersions returns every known version of a seed, oldest first, ending
+// with the current one
+func (b *Base) SeedVersions(name string) ([]Seed, error) {
+	current, err := b.GetSeed(name)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	versions := append([]Seed{}, b.history[name]...)
+	return append(versions, *current), nil
+}
+
+// GetSeedVersion retrieves a specific version of a seed. An empty version
+// or "latest" returns the current seed.
+func (b *Base) GetSeedVersion(name, version string) (*Seed, error) {
+	if version == "" || version == "latest" {
+		return b.GetSeed(name)
+	}
+
+	versions, err := b.SeedVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	available := make([]string, 0, len(versions))
+	for _, seed := range versions {
+		if seed.Version == version {
+			return &seed, nil
+		}
+		available = append(available, seed.Version)
+	}
+	return nil, fmt.Errorf("seed %s has no version %s (available: %s)", name, version, strings.Join(available, ", "))
+}
+
+// SeedHistory returns every known version of every seed, for persisting
+func (b *Base) SeedHistory() []Seed {
+	all := []Seed{}
+	for _, seed := range b.seeds {
+		versions, _ := b.SeedVersions(seed.Name)
+		all = append(all, versions...)
+	}
+	return all
+}
+
+// seedIndex finds the current version of a seed
+func (b *Base) seedIndex(name string) (Seed, bool) {
+	for _, seed := range b.seeds {
+		if seed.Name == name {
+			return seed, true
+		}
+	}
+	return Seed{}, false
+}
@@ -0,0 +1,239 @@
+// Package workspace confines the files tools read and write to one
+// directory, with size quotas and an optional read-only mode
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrReadOnly is returned for writes to a read-only workspace
+var ErrReadOnly = errors.New("the workspace is read-only")
+
+// Workspace is a directory tools may read and write inside. Paths are given
+// relative to its root with forward slashes; absolute paths, ".." elements
+// and symlinks that lead outside the root are rejected.
+type Workspace struct {
+	root        string
+	readOnly    bool
+	maxFileSize int64
+	quota       int64
+	mu          sync.Mutex
+}
+
+// Option configures a Workspace
+type Option func(*Workspace)
+
+// ReadOnly makes every write fail with ErrReadOnly
+func ReadOnly(readOnly bool) Option {
+	return func(w *Workspace) {
+		w.readOnly = readOnly
+	}
+}
+
+// MaxFileSize limits the size of a single written file; zero or less means
+// no limit
+func MaxFileSize(bytes int64) Option {
+	return func(w *Workspace) {
+		w.maxFileSize = bytes
+	}
+}
+
+// Quota limits the total size of the files in the workspace; zero or less
+// means no limit
+func Quota(bytes int64) Option {
+	return func(w *Workspace) {
+		w.quota = bytes
+	}
+}
+
+// Write describes one file written to the workspace
+type Write struct {
+	Path    string `json:"path"`
+	Bytes   int    `json:"bytes"`
+	Created bool   `json:"created"`
+}
+
+// Open returns a workspace rooted at dir, creating the directory unless the
+// workspace is read-only
+func Open(dir string, opts ...Option) (*Workspace, error) {
+	w := &Workspace{}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace %s: %w", dir, err)
+	}
+	if !w.readOnly {
+		if err := os.MkdirAll(abs, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create workspace: %w", err)
+		}
+	}
+	// The root itself may be reached through a symlink; everything below it
+	// is checked against where it really is
+	if w.root, err = filepath.EvalSymlinks(abs); err != nil {
+		return nil, fmt.Errorf("invalid workspace %s: %w", dir, err)
+	}
+	return w, nil
+}
+
+// Root returns the absolute path of the workspace
+func (w *Workspace) Root() string {
+	return w.root
+}
+
+// ReadOnly reports whether writes are refused
+func (w *Workspace) ReadOnly() bool {
+	return w.readOnly
+}
+
+// resolve maps a workspace path to a real path inside the root
+func (w *Workspace) resolve(name string) (string, error) {
+	if name == "" || strings.Contains(name, "\\") || strings.HasPrefix(name, "/") || filepath.IsAbs(name) {
+		return "", fmt.Errorf("path %q must be relative to the workspace, with forward slashes", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("path %q must not contain ..", name)
+		}
+	}
+	target := filepath.Join(w.root, filepath.FromSlash(name))
+	if target == w.root {
+		return "", fmt.Errorf("path %q names the workspace itself", name)
+	}
+
+	// Resolve symlinks in the part of the path that exists, then check it
+	// is still inside the root
+	existing, rest := target, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = filepath.Dir(existing)
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	if !w.contains(real) {
+		return "", fmt.Errorf("path %q leads outside the workspace", name)
+	}
+	return filepath.Join(real, rest), nil
+}
+
+func (w *Workspace) contains(path string) bool {
+	rel, err := filepath.Rel(w.root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ReadFile reads a file in the workspace
+func (w *Workspace) ReadFile(name string) ([]byte, error) {
+	path, err := w.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// Exists reports whether a file exists in the workspace
+func (w *Workspace) Exists(name string) bool {
+	path, err := w.resolve(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// WriteFile creates or replaces a file, creating its directories. The file
+// is replaced atomically so a crash never leaves it half-written.
+func (w *Workspace) WriteFile(name string, data []byte) (Write, error) {
+	if w.readOnly {
+		return Write{}, fmt.Errorf("cannot write %s: %w", name, ErrReadOnly)
+	}
+	if w.maxFileSize > 0 && int64(len(data)) > w.maxFileSize {
+		return Write{}, fmt.Errorf("cannot write %s: %d bytes is over the %d byte file limit", name, len(data), w.maxFileSize)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	path, err := w.resolve(name)
+	if err != nil {
+		return Write{}, err
+	}
+	var previous int64
+	info, err := os.Stat(path)
+	created := os.IsNotExist(err)
+	if err == nil {
+		if info.IsDir() {
+			return Write{}, fmt.Errorf("cannot write %s: it is a directory", name)
+		}
+		previous = info.Size()
+	}
+	if w.quota > 0 {
+		used, err := w.usage()
+		if err != nil {
+			return Write{}, err
+		}
+		if used-previous+int64(len(data)) > w.quota {
+			return Write{}, fmt.Errorf("cannot write %s: the workspace would exceed its %d byte quota (%d bytes used)", name, w.quota, used)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return Write{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return Write{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return Write{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return Write{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Write{}, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return Write{Path: name, Bytes: len(data), Created: created}, nil
+}
+
+// Usage returns the total size of the files in the workspace
+func (w *Workspace) Usage() (int64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.usage()
+}
+
+func (w *Workspace) usage() (int64, error) {
+	var total int64
+	err := filepath.WalkDir(w.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure the workspace: %w", err)
+	}
+	return total, nil
+}
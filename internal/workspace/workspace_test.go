@@ -0,0 +1,124 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func open(t *testing.T, opts ...Option) *Workspace {
+	t.Helper()
+	w, err := Open(t.TempDir(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestPathsMustStayInside(t *testing.T) {
+	w := open(t)
+	for _, name := range []string{
+		"",
+		"..",
+		"../escape.md",
+		"notes/../../escape.md",
+		"/etc/passwd",
+		`notes\plan.md`,
+		`..\escape.md`,
+		".",
+	} {
+		if _, err := w.WriteFile(name, []byte("x")); err == nil {
+			t.Errorf("write to %q was allowed", name)
+		}
+		if _, err := w.ReadFile(name); err == nil {
+			t.Errorf("read of %q was allowed", name)
+		}
+	}
+
+	if _, err := w.WriteFile("notes/plan.md", []byte("ok")); err != nil {
+		t.Fatalf("a plain path was refused: %v", err)
+	}
+	if data, err := w.ReadFile("notes/plan.md"); err != nil || string(data) != "ok" {
+		t.Fatalf("read back %q, %v", data, err)
+	}
+}
+
+func TestSymlinksMustNotLeadOutside(t *testing.T) {
+	w := open(t)
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"leaf.txt": filepath.Join(outside, "secret.txt"),
+		"dangling": filepath.Join(outside, "missing.txt"),
+		"dir":      outside,
+		"inside":   w.Root(),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(w.Root(), name)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	for _, name := range []string{"leaf.txt", "dangling", "dir/secret.txt", "dir/new/plan.md"} {
+		if _, err := w.ReadFile(name); err == nil {
+			t.Errorf("read through %q was allowed", name)
+		}
+		if _, err := w.WriteFile(name, []byte("x")); err == nil {
+			t.Errorf("write through %q was allowed", name)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(outside, "secret.txt")); err != nil || string(data) != "secret" {
+		t.Fatalf("the file outside was changed: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+		t.Fatalf("a directory was created outside: %v", err)
+	}
+
+	if _, err := w.WriteFile("inside/plan.md", []byte("ok")); err != nil {
+		t.Fatalf("a symlink within the workspace was refused: %v", err)
+	}
+}
+
+func TestReadOnlyRefusesWrites(t *testing.T) {
+	w := open(t, ReadOnly(true))
+	if _, err := w.WriteFile("plan.md", []byte("x")); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("got %v, want ErrReadOnly", err)
+	}
+	if w.Exists("plan.md") {
+		t.Fatal("a read-only workspace was written")
+	}
+}
+
+func TestFileSizeLimit(t *testing.T) {
+	w := open(t, MaxFileSize(4))
+	if _, err := w.WriteFile("small.md", []byte("1234")); err != nil {
+		t.Fatalf("a file at the limit was refused: %v", err)
+	}
+	if _, err := w.WriteFile("large.md", []byte("12345")); err == nil || !strings.Contains(err.Error(), "file limit") {
+		t.Fatalf("a file over the limit was written: %v", err)
+	}
+}
+
+func TestQuotaCountsReplacedFiles(t *testing.T) {
+	w := open(t, Quota(10))
+	if write, err := w.WriteFile("a.md", []byte("123456")); err != nil || !write.Created {
+		t.Fatalf("first write: %+v, %v", write, err)
+	}
+	// Replacing a 6 byte file with 8 bytes brings usage to 8, not 14
+	if write, err := w.WriteFile("a.md", []byte("12345678")); err != nil || write.Created {
+		t.Fatalf("replacing within the quota: %+v, %v", write, err)
+	}
+	if _, err := w.WriteFile("b.md", []byte("123")); err == nil || !strings.Contains(err.Error(), "quota") {
+		t.Fatalf("a write past the quota was allowed: %v", err)
+	}
+	if _, err := w.WriteFile("b.md", []byte("12")); err != nil {
+		t.Fatalf("a write filling the quota exactly was refused: %v", err)
+	}
+	if used, err := w.Usage(); err != nil || used != 10 {
+		t.Fatalf("usage %d, %v; want 10", used, err)
+	}
+}